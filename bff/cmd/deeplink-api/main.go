@@ -1,24 +1,33 @@
 package main
 
 import (
+	"context"
 	"deeplink-bff/bff/config"
 	"deeplink-bff/bff/docs"
 	deeplink_client "deeplink-bff/bff/internal/adapters/client"
+	admin_handler "deeplink-bff/bff/internal/adapters/handler/admin"
+	analytics_handler "deeplink-bff/bff/internal/adapters/handler/analytics"
 	deeplink_handler "deeplink-bff/bff/internal/adapters/handler/deeplink"
+	shortlink_handler "deeplink-bff/bff/internal/adapters/handler/shortlink"
+	analytics_repository "deeplink-bff/bff/internal/adapters/repositories/analytics"
+	shortlink_repository "deeplink-bff/bff/internal/adapters/repositories/shortlink"
+	"deeplink-bff/bff/internal/core/domain"
+	analytics_service "deeplink-bff/bff/internal/core/services/analytics"
 	deeplink_service "deeplink-bff/bff/internal/core/services/deeplink"
+	shortlink_service "deeplink-bff/bff/internal/core/services/shortlink"
 	"deeplink-bff/middleware"
 	"deeplink-bff/pkg/logx"
 	"fmt"
-	"io"
 	"log/slog"
 	"net/http"
 	"os"
 	"os/signal"
-	"path/filepath"
+	"regexp"
 	"syscall"
 	"time"
 
 	"github.com/gofiber/fiber/v2"
+	"github.com/mattn/go-isatty"
 	fiberSwagger "github.com/swaggo/fiber-swagger"
 )
 
@@ -32,15 +41,24 @@ import (
 // @description				Please input your customer id. (works only in dev environment)
 func newRouters(
 	deeplinkHandler *deeplink_handler.Handler,
+	shortLinkHandler *shortlink_handler.Handler,
+	analyticsHandler *analytics_handler.Handler,
+	adminHandler *admin_handler.Handler,
 ) *fiber.App {
 	appConfig := fiber.Config{
 		// Fiber's default error handler is quite good.
 		// ErrorHandler: func(c *fiber.Ctx, err error) error { ... }
+
+		// Values from the context outlive the request in async log records
+		// and click events, so they must not point into reused buffers
+		Immutable: true,
 	}
 	if config.Get().Environment != "dev" {
 		// Mimic Gin's ReleaseMode effects for non-dev environments
 		appConfig.DisableStartupMessage = true // Suppress Fiber's startup banner
 	}
+	// Absolute source paths in request log stack traces only help locally
+	middleware.StackFullPath = config.Get().IsDevelop()
 
 	app := fiber.New(appConfig)
 	// app.Use(middleware.Error()) // Ensure middleware.Error() is Fiber compatible if used
@@ -55,11 +73,40 @@ func newRouters(
 		})
 	})
 
+	// The build serving requests, to tie incidents to exact builds. Host
+	// details are only served to admins.
+	app.Get("/version", func(c *fiber.Ctx) error {
+		return c.Status(http.StatusOK).JSON(logx.Runtime().BuildInfo)
+	})
+
+	// The admin endpoints are only served when an admin token is configured
+	if config.Get().Admin.Token != "" {
+		adminGroup := app.Group("/admin",
+			middleware.Logger(requestLogFilters...),
+			middleware.Recovery(true),
+			adminHandler.Authenticate,
+		)
+		adminGroup.Get("/log-level", adminHandler.GetLogLevel)
+		adminGroup.Put("/log-level", adminHandler.UpdateLogLevel)
+		adminGroup.Get("/runtime", adminHandler.GetRuntime)
+	}
+
+	app.Get("/r/:id",
+		middleware.Logger(requestLogFilters...),
+		middleware.Recovery(true),
+		deeplinkHandler.ResolveDeeplink,
+	)
+	app.Get("/s/:code",
+		middleware.Logger(requestLogFilters...),
+		middleware.Recovery(true),
+		shortLinkHandler.ResolveShortLink,
+	)
+
 	apiGroup := app.Group("/api")
 	v1 := apiGroup.Group("/v1")
 	v1.Use(
 		// middleware.Auth(), // Ensure this middleware is Fiber compatible: func(c *fiber.Ctx) error
-		middleware.Logger(),
+		middleware.Logger(requestLogFilters...),
 		middleware.Recovery(true),
 	)
 
@@ -68,10 +115,28 @@ func newRouters(
 		dashboardGroup.Get("", deeplinkHandler.GetDeeplinkList)
 		// Ensure deeplinkHandler.GetDeeplinkList signature is: func(c *fiber.Ctx) error
 		dashboardGroup.Get("/:id", deeplinkHandler.GetDeeplink)
+		dashboardGroup.Get("/:id/qr", deeplinkHandler.GetDeeplinkQR)
+		dashboardGroup.Post("/:id/short-link", shortLinkHandler.CreateShortLink)
+		dashboardGroup.Get("/:id/stats", analyticsHandler.GetDeeplinkStats)
+	}
+
+	partnerGroup := v1.Group("/partner")
+	{
+		partnerGroup.Get("/:partner/stats", analyticsHandler.GetPartnerStats)
 	}
 	return app
 }
 
+// requestLogFilters keep dashboards polling stats out of the request logs.
+// Failed polls are still logged. Probes and docs are served without the logger.
+var requestLogFilters = []middleware.Filter{
+	middleware.Ignore(middleware.AllOf(
+		middleware.AcceptMethod(fiber.MethodGet),
+		middleware.AcceptPathMatch(regexp.MustCompile(`^/api/v1/(deeplink|partner)/[^/]+/stats$`)),
+		middleware.AcceptStatusRange(http.StatusOK, http.StatusMultipleChoices-1),
+	)),
+}
+
 func initSwagger() fiber.Handler { // Return type changed to fiber.Handler
 	docs.SwaggerInfo.Title = "deeplink API"
 	docs.SwaggerInfo.Description = "APIs for providing deeplink data."
@@ -87,6 +152,152 @@ func initSwagger() fiber.Handler { // Return type changed to fiber.Handler
 	return fiberSwagger.WrapHandler // fiberSwagger.WrapHandler is the fiber.Handler
 }
 
+// newExperiments converts the configured experiments and validates them.
+func newExperiments() ([]domain.Experiment, error) {
+	var experiments []domain.Experiment
+	for _, cfg := range config.Get().Deeplink.Experiments {
+		experiment := domain.Experiment{
+			ProductCode: cfg.ProductCode,
+			Channel:     cfg.Channel,
+		}
+		for _, variant := range cfg.Variants {
+			experiment.Variants = append(experiment.Variants, domain.Variant{
+				Name:        variant.Name,
+				Weight:      variant.Weight,
+				AppBaseURL:  variant.AppBaseURL,
+				FallbackURL: variant.FallbackURL,
+			})
+		}
+		if err := experiment.Validate(); err != nil {
+			return nil, err
+		}
+		experiments = append(experiments, experiment)
+	}
+	return experiments, nil
+}
+
+// newLogLevels parses the configured base level and level overrides.
+func newLogLevels(level string, overrides map[string]string) (slog.Level, map[string]slog.Level, error) {
+	baseLevel, err := logx.ParseLevel(level)
+	if err != nil {
+		return 0, nil, err
+	}
+	levelOverrides, err := logx.ParseLevelOverrides(overrides)
+	if err != nil {
+		return 0, nil, err
+	}
+	return baseLevel, levelOverrides, nil
+}
+
+// reloadLogLevels reloads the log levels from the configuration on SIGHUP,
+// discarding changes made through the admin endpoint.
+func reloadLogLevels(levels *logx.Levels) {
+	hup := make(chan os.Signal, 1)
+	signal.Notify(hup, syscall.SIGHUP)
+	for range hup {
+		logCfg, err := config.LoadLog()
+		if err != nil {
+			slog.Error("Failed to reload log configuration", slog.Any("error", err))
+			continue
+		}
+		level, overrides, err := newLogLevels(logCfg.LevelOrDefault(config.Get().IsDevelop()), logCfg.LevelOverrides)
+		if err == nil {
+			err = levels.Set(level, overrides)
+		}
+		if err != nil {
+			slog.Error("Invalid log level configuration", slog.Any("error", err))
+			continue
+		}
+		slog.Warn("Log level reloaded",
+			slog.String("log_level", level.String()),
+			slog.Any("overrides", logCfg.LevelOverrides),
+		)
+	}
+}
+
+// reopenLogFiles reopens the log files on SIGUSR1, after an external tool
+// such as logrotate moved them away.
+func reopenLogFiles(logFiles []*logx.RotatingFile) {
+	usr1 := make(chan os.Signal, 1)
+	signal.Notify(usr1, syscall.SIGUSR1)
+	for range usr1 {
+		for _, logFile := range logFiles {
+			if err := logFile.Reopen(); err != nil {
+				slog.Error("Failed to reopen log file", slog.Any("error", err))
+			}
+		}
+		slog.Info("Log files reopened")
+	}
+}
+
+// sinkLevel parses an optional sink level; empty means no level of its own.
+func sinkLevel(level string) (slog.Leveler, error) {
+	if level == "" {
+		return nil, nil
+	}
+	return logx.ParseLevel(level)
+}
+
+// newLogSinks creates the stdout sink, the log file sink and the error file
+// sink. The returned files must be closed on exit.
+func newLogSinks() ([]logx.Sink, []*logx.RotatingFile, error) {
+	logCfg := config.Get().Log
+
+	// Developers reading logs in a terminal get the pretty format by default.
+	// Colors are only written to a terminal, and never with NO_COLOR set.
+	terminal := isatty.IsTerminal(os.Stdout.Fd())
+	color := terminal && os.Getenv("NO_COLOR") == ""
+	format := logCfg.ConsoleFormat
+	if format == "" && config.Get().IsDevelop() && terminal {
+		format = string(logx.FormatPretty)
+	}
+	consoleFormat, err := logx.ParseFormat(format)
+	if err != nil {
+		return nil, nil, err
+	}
+	consoleLevel, err := sinkLevel(logCfg.ConsoleLevel)
+	if err != nil {
+		return nil, nil, err
+	}
+	fileLevel, err := sinkLevel(logCfg.File.Level)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// local file-based logging, rotated by size and time
+	openFile := func(path string) (*logx.RotatingFile, error) {
+		return logx.NewRotatingFile(logx.RotateConfig{
+			Path:           path,
+			MaxSize:        logCfg.File.MaxSizeMB << 20,
+			Interval:       logCfg.File.RotateInterval,
+			MaxBackups:     logCfg.File.MaxBackups,
+			Compress:       logCfg.File.Compress,
+			OnWriteFailure: logx.WriteFailurePolicy(logCfg.File.OnWriteFailure),
+		})
+	}
+
+	logFile, err := openFile(logCfg.File.Path)
+	if err != nil {
+		return nil, nil, err
+	}
+	sinks := []logx.Sink{
+		{Writer: os.Stdout, Level: consoleLevel, Format: consoleFormat, Color: color},
+		{Writer: logFile, Level: fileLevel, Format: logx.FormatJSON},
+	}
+	logFiles := []*logx.RotatingFile{logFile}
+
+	if logCfg.File.ErrorPath != "" {
+		errorFile, err := openFile(logCfg.File.ErrorPath)
+		if err != nil {
+			logFile.Close()
+			return nil, nil, err
+		}
+		sinks = append(sinks, logx.Sink{Writer: errorFile, Level: slog.LevelError, Format: logx.FormatJSON})
+		logFiles = append(logFiles, errorFile)
+	}
+	return sinks, logFiles, nil
+}
+
 func main() {
 	config.Load()
 
@@ -95,39 +306,150 @@ func main() {
 		Source:      "deeplink-bff",
 	}
 
-	// local file-based logging
-	logDir := "logs"
-	if _, err := os.Stat(logDir); os.IsNotExist(err) {
-		if err := os.MkdirAll(logDir, os.ModePerm); err != nil {
-			fmt.Fprintf(os.Stderr, "Failed to create log directory: %v\n", err)
-			os.Exit(1)
-		}
+	logSinks, logFiles, err := newLogSinks()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to set up log sinks: %v\n", err)
+		os.Exit(1)
 	}
+	defer func() {
+		for _, logFile := range logFiles {
+			logFile.Close()
+		}
+	}()
+	go reopenLogFiles(logFiles)
 
-	// Open the log file
-	logFilePath := filepath.Join(logDir, "app.log")
-	logFile, err := os.OpenFile(logFilePath, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	level, levelOverrides, err := newLogLevels(config.Get().Log.LevelOrDefault(config.Get().IsDevelop()), config.Get().Log.LevelOverrides)
 	if err != nil {
-		fmt.Fprintf(os.Stderr, "Failed to open log file: %v\n", err)
+		fmt.Fprintf(os.Stderr, "Invalid log level configuration: %v\n", err)
+		os.Exit(1)
+	}
+	logLevels := logx.NewLevels(level)
+	if err := logLevels.SetOverrides(levelOverrides); err != nil {
+		fmt.Fprintf(os.Stderr, "Invalid log level configuration: %v\n", err)
 		os.Exit(1)
 	}
-	defer logFile.Close()
-
-	writer := io.MultiWriter(os.Stdout, logFile)
 
-	logger, _ := logx.New(
-		cfgLog,
+	logOptions := []logx.Option{
 		logx.WithAddSource(false),
-		logx.WithLevel(slog.LevelDebug),
-		logx.WithWriter(writer),
-	)
+		logx.WithLevels(logLevels),
+		logx.WithRuntimeInfo(config.Get().Log.RuntimeInfo),
+		logx.WithHashSalt(config.Get().Log.HashSalt),
+	}
+	for _, sink := range logSinks {
+		logOptions = append(logOptions, logx.WithSink(sink))
+	}
+
+	// Write log records off the request goroutines; flushed on shutdown
+	var logAsync *logx.Async
+	if asyncCfg := config.Get().Log.Async; asyncCfg.Enabled {
+		logAsync, err = logx.NewAsync(logx.AsyncConfig{
+			QueueSize: asyncCfg.QueueSize,
+			Overflow:  logx.OverflowPolicy(asyncCfg.Overflow),
+		})
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Invalid async log configuration: %v\n", err)
+			os.Exit(1)
+		}
+		logOptions = append(logOptions, logx.WithAsync(logAsync))
+	}
+
+	// Sample repeated records; errors are summarized rather than dropped
+	var logSampler *logx.Sampler
+	if samplingCfg := config.Get().Log.Sampling; samplingCfg.Enabled {
+		sampled := logx.SampleRule{First: samplingCfg.First, Thereafter: samplingCfg.Thereafter}
+		logSampler, err = logx.NewSampler(logx.SamplingConfig{
+			Interval: samplingCfg.Interval,
+			Rules: map[slog.Level]logx.SampleRule{
+				slog.LevelDebug: sampled,
+				slog.LevelError: {First: samplingCfg.First, Dedup: true},
+			},
+			// every successful request logs the same message, so sampling
+			// it would keep only the first requests of any route
+			Exempt: []string{middleware.RequestLogMessage},
+		})
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Invalid log sampling configuration: %v\n", err)
+			os.Exit(1)
+		}
+		logOptions = append(logOptions, logx.WithSampler(logSampler))
+	}
+
+	// Export censored records to an OpenTelemetry collector
+	var logExporter *logx.OTLPExporter
+	if otlpCfg := config.Get().Log.OTLP; otlpCfg.Endpoint != "" {
+		otlpLevel, err := sinkLevel(otlpCfg.Level)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Invalid OTLP log level: %v\n", err)
+			os.Exit(1)
+		}
+		logExporter, err = logx.NewOTLPExporter(logx.OTLPConfig{
+			Endpoint:      otlpCfg.Endpoint,
+			Headers:       otlpCfg.Headers,
+			Level:         otlpLevel,
+			BatchSize:     otlpCfg.BatchSize,
+			FlushInterval: otlpCfg.FlushInterval,
+		})
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Invalid OTLP log configuration: %v\n", err)
+			os.Exit(1)
+		}
+		logOptions = append(logOptions, logx.WithOTLP(logExporter))
+	}
+
+	logger, err := logx.New(cfgLog, logOptions...)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Invalid log configuration: %v\n", err)
+		os.Exit(1)
+	}
 
 	slog.SetDefault(logger)
 
 	deeplinkClient := deeplink_client.NewDeepLinkClient("http://localhost:3000")
-	deeplinkService := deeplink_service.NewDeeplinkService(deeplinkClient)
-	deeplinkHandler := deeplink_handler.NewHandler(deeplinkService)
-	app := newRouters(deeplinkHandler)
+
+	experiments, err := newExperiments()
+	if err != nil {
+		slog.Error("Invalid deeplink experiments", slog.Any("error", err))
+		os.Exit(1)
+	}
+
+	clickEventRepository := analytics_repository.NewMemoryRepository(config.Get().Analytics.MaxEvents)
+	analyticsService := analytics_service.NewAnalyticsService(clickEventRepository, logx.Named(logger, "analytics_service"))
+	analyticsHandler := analytics_handler.NewHandler(analyticsService)
+
+	deeplinkService := deeplink_service.NewDeeplinkService(deeplinkClient, analyticsService, deeplink_service.Config{
+		ResolveBaseURL: config.Get().Deeplink.ResolveBaseURL,
+		AppBaseURL:     config.Get().Deeplink.AppBaseURL,
+		FallbackURL:    config.Get().Deeplink.FallbackURL,
+		Experiments:    experiments,
+	}, logx.Named(logger, "deeplink_service"))
+	deeplinkHandler := deeplink_handler.NewHandler(deeplinkService, logx.Named(logger, "deeplink_handler"))
+
+	shortLinkRepository := shortlink_repository.NewMemoryRepository()
+	shortLinkService := shortlink_service.NewShortLinkService(deeplinkClient, deeplinkService, shortLinkRepository, shortlink_service.Config{
+		BaseURL:       config.Get().ShortLink.BaseURL,
+		CodeLength:    config.Get().ShortLink.CodeLength,
+		AliasPrefixes: config.Get().ShortLink.AliasPrefixes,
+	}, logx.Named(logger, "shortlink_service"))
+	shortLinkHandler := shortlink_handler.NewHandler(shortLinkService)
+
+	adminHandler := admin_handler.NewHandler(config.Get().Admin.Token, logLevels, logx.Named(logger, "admin_handler"))
+
+	app := newRouters(deeplinkHandler, shortLinkHandler, analyticsHandler, adminHandler)
+
+	go reloadLogLevels(logLevels)
+
+	// Purge expired short links so the in-memory store does not grow unbounded
+	go func() {
+		ticker := time.NewTicker(10 * time.Minute)
+		defer ticker.Stop()
+		for range ticker.C {
+			if n, err := shortLinkRepository.DeleteExpired(context.Background(), time.Now()); err != nil {
+				slog.Error("Failed to purge expired short links", slog.Any("error", err))
+			} else if n > 0 {
+				slog.Info("Purged expired short links", slog.Int("count", n))
+			}
+		}
+	}()
 
 	addr := fmt.Sprintf("%s:%d", config.Get().App.Host, config.Get().App.Port)
 
@@ -154,5 +476,31 @@ func main() {
 		slog.Error("Server forced to shutdown:", slog.Any("error", err))
 	}
 
-	slog.Info("Server exiting")
+	flushCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	var logStats []slog.Attr
+	if logSampler != nil {
+		// Log the summaries of deduplicated records
+		if err := logSampler.Close(flushCtx); err != nil {
+			fmt.Fprintf(os.Stderr, "Failed to log sampling summaries: %v\n", err)
+		}
+		logStats = append(logStats, slog.Uint64("sampled_log_records", logSampler.Dropped()))
+	}
+	if logAsync != nil {
+		logStats = append(logStats, slog.Uint64("dropped_log_records", logAsync.Dropped()))
+	}
+	slog.LogAttrs(flushCtx, slog.LevelInfo, "Server exiting", logStats...)
+
+	// Flush queued log records before the log files are closed
+	if logAsync != nil {
+		if err := logAsync.Close(flushCtx); err != nil {
+			fmt.Fprintf(os.Stderr, "Failed to flush log records: %v\n", err)
+		}
+	}
+	if logExporter != nil {
+		if err := logExporter.Shutdown(flushCtx); err != nil {
+			fmt.Fprintf(os.Stderr, "Failed to export log records: %v\n", err)
+		}
+	}
 }
@@ -0,0 +1,194 @@
+// Command logq queries the JSON logs written by logx.
+//
+// Usage:
+//
+//	logq [flags] [file ...]
+//
+// It reads stdin when no file is given. Examples:
+//
+//	logq -level warn logs/app.log
+//	logq -request-id dd806e2f-ac77-4ac9-817e-1d0e6cf971d3 -timeline logs/app.log
+//	logq -path '/api/v1/deeplink/*' -status 5xx -since 1h logs/app.log
+//	logq -where 'msg~GetDeeplink' -where 'response.length>1024' logs/app.log
+//	logq -f -level error logs/app.log logs/error.log
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+
+	"deeplink-bff/pkg/logq"
+	"deeplink-bff/pkg/logx"
+)
+
+// exprFlags collects repeated -where flags.
+type exprFlags []logq.Expr
+
+func (e *exprFlags) String() string {
+	exprs := make([]string, len(*e))
+	for i, expr := range *e {
+		exprs[i] = expr.String()
+	}
+	return strings.Join(exprs, " ")
+}
+
+func (e *exprFlags) Set(value string) error {
+	expr, err := logq.ParseExpr(value)
+	if err != nil {
+		return err
+	}
+	*e = append(*e, expr)
+	return nil
+}
+
+func main() {
+	if err := run(os.Args[1:], os.Stdin, os.Stdout); err != nil {
+		fmt.Fprintf(os.Stderr, "logq: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+func run(args []string, stdin io.Reader, stdout io.Writer) error {
+	flags := flag.NewFlagSet("logq", flag.ContinueOnError)
+	var (
+		level     = flags.String("level", "", "minimum level, e.g. warn")
+		since     = flags.String("since", "", "records at or after this RFC 3339 time or duration ago, e.g. 15m")
+		until     = flags.String("until", "", "records at or before this RFC 3339 time or duration ago")
+		requestID = flags.String("request-id", "", "records of one request")
+		path      = flags.String("path", "", "request path prefix, or a glob such as /api/v1/deeplink/*")
+		status    = flags.String("status", "", "response status, class or range, e.g. 500, 5xx or 400-499")
+		follow    = flags.Bool("f", false, "follow the files like tail -f")
+		timeline  = flags.Bool("timeline", false, "group the records by request")
+		exprs     exprFlags
+	)
+	flags.Var(&exprs, "where", "attribute expression, repeatable: key, !key, key=v, key!=v, key~regexp, key>n, key<n")
+	flags.Usage = func() {
+		fmt.Fprintln(flags.Output(), "Usage: logq [flags] [file ...]")
+		flags.PrintDefaults()
+	}
+	if err := flags.Parse(args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return nil
+		}
+		return err
+	}
+
+	query := logq.Query{RequestID: *requestID, Path: *path, Exprs: exprs}
+	now := time.Now()
+	var err error
+	if *level != "" {
+		if query.Level, err = logx.ParseLevel(*level); err != nil {
+			return err
+		}
+	}
+	if *since != "" {
+		if query.Since, err = logq.ParseTime(*since, now); err != nil {
+			return err
+		}
+	}
+	if *until != "" {
+		if query.Until, err = logq.ParseTime(*until, now); err != nil {
+			return err
+		}
+	}
+	if *status != "" {
+		if query.Status, err = logq.ParseStatusRange(*status); err != nil {
+			return err
+		}
+	}
+
+	files := flags.Args()
+	if *follow {
+		if *timeline {
+			return fmt.Errorf("-timeline cannot be used with -f")
+		}
+		if len(files) == 0 {
+			return fmt.Errorf("-f needs at least one file")
+		}
+		return followFiles(files, query, stdout)
+	}
+
+	var matched []logq.Record
+	collect := func(record logq.Record) error {
+		if !query.Match(record) {
+			return nil
+		}
+		if *timeline {
+			matched = append(matched, record)
+			return nil
+		}
+		return writeRecord(stdout, record)
+	}
+
+	if len(files) == 0 {
+		if err := logq.Scan(stdin, collect); err != nil {
+			return err
+		}
+	}
+	for _, name := range files {
+		if err := scanFile(name, collect); err != nil {
+			return err
+		}
+	}
+
+	if *timeline {
+		return logq.WriteTimeline(stdout, logq.Timeline(matched))
+	}
+	return nil
+}
+
+func scanFile(name string, fn func(logq.Record) error) error {
+	file, err := os.Open(name)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = file.Close() }()
+	return logq.Scan(file, fn)
+}
+
+func writeRecord(w io.Writer, record logq.Record) error {
+	_, err := fmt.Fprintf(w, "%s\n", record.Raw)
+	return err
+}
+
+// followFiles follows every file until SIGINT or SIGTERM, writing matching
+// records as they are appended.
+func followFiles(files []string, query logq.Query, stdout io.Writer) error {
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+
+	var (
+		mu   sync.Mutex
+		wg   sync.WaitGroup
+		errs = make([]error, len(files))
+	)
+	for i, name := range files {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			errs[i] = logq.Follow(ctx, name, false, logq.DefaultPollInterval, func(record logq.Record) error {
+				if !query.Match(record) {
+					return nil
+				}
+				mu.Lock()
+				defer mu.Unlock()
+				return writeRecord(stdout, record)
+			})
+			if errs[i] != nil {
+				// stop following the other files too
+				stop()
+			}
+		}()
+	}
+	wg.Wait()
+	return errors.Join(errs...)
+}
@@ -1,7 +1,10 @@
 package config
 
 import (
+	"encoding/json"
+	"os"
 	"sync"
+	"time"
 
 	"github.com/joho/godotenv"
 	"github.com/kelseyhightower/envconfig"
@@ -12,27 +15,216 @@ type appConfig struct {
 	Port int    `envconfig:"APP_PORT" default:"4000"`
 }
 
+type deeplinkConfig struct {
+	// ResolveBaseURL is the public URL a deeplink id is appended to when it is
+	// shared outside the app, e.g. in QR codes.
+	ResolveBaseURL string `envconfig:"DEEPLINK_RESOLVE_BASE_URL" default:"http://localhost:4000/r"`
+	// AppBaseURL is the app URL mobile clients are redirected to on resolve.
+	AppBaseURL string `envconfig:"DEEPLINK_APP_BASE_URL" default:"deeplink://open"`
+	// FallbackURL is where clients that cannot open the app are redirected to.
+	FallbackURL string `envconfig:"DEEPLINK_FALLBACK_URL" default:"https://www.example.com/download"`
+	// Experiments is a JSON array routing products to weighted destination variants, e.g.
+	// [{"product_code":"loan","variants":[{"name":"a","weight":50},{"name":"b","weight":50,"fallback_url":"https://..."}]}]
+	Experiments experiments `envconfig:"DEEPLINK_EXPERIMENTS"`
+}
+
+type variantConfig struct {
+	Name        string `json:"name"`
+	Weight      int    `json:"weight"`
+	AppBaseURL  string `json:"app_base_url"`
+	FallbackURL string `json:"fallback_url"`
+}
+
+type experimentConfig struct {
+	ProductCode string          `json:"product_code"`
+	Channel     string          `json:"channel"`
+	Variants    []variantConfig `json:"variants"`
+}
+
+type experiments []experimentConfig
+
+// Decode implements envconfig.Decoder.
+func (e *experiments) Decode(value string) error {
+	return json.Unmarshal([]byte(value), e)
+}
+
+type analyticsConfig struct {
+	MaxEvents int `envconfig:"ANALYTICS_MAX_EVENTS" default:"100000"`
+}
+
+type shortLinkConfig struct {
+	BaseURL    string `envconfig:"SHORTLINK_BASE_URL" default:"http://localhost:4000/s"`
+	CodeLength int    `envconfig:"SHORTLINK_CODE_LENGTH" default:"7"`
+	// AliasPrefixes maps a partner code to the prefix its custom aliases must use,
+	// e.g. SHORTLINK_ALIAS_PREFIXES=partner_a:pa-,partner_b:pb-
+	AliasPrefixes map[string]string `envconfig:"SHORTLINK_ALIAS_PREFIXES"`
+}
+
+type logConfig struct {
+	// Level is the base log level, e.g. debug or info. Empty means debug in
+	// dev and info in every other environment.
+	Level string `envconfig:"LOG_LEVEL"`
+	// LevelOverrides maps a logger name to its level,
+	// e.g. LOG_LEVEL_OVERRIDES=deeplink_service:debug,shortlink_service:warn
+	LevelOverrides map[string]string `envconfig:"LOG_LEVEL_OVERRIDES"`
+	// ConsoleFormat is json, text, console (colorized lines) or pretty
+	// (colorized, multi-line). Empty means pretty in dev when stdout is a
+	// terminal and json otherwise.
+	ConsoleFormat string `envconfig:"LOG_CONSOLE_FORMAT"`
+	// ConsoleLevel is the minimum level written to stdout; empty writes every level
+	ConsoleLevel string `envconfig:"LOG_CONSOLE_LEVEL"`
+	// HashSalt keys the HMAC of values masked with the hash strategy, see
+	// logx.WithHashSalt. Keep it secret and stable so hashes stay comparable.
+	HashSalt string `envconfig:"LOG_HASH_SALT"`
+	// RuntimeInfo attaches build info, hostname, pid and instance ID to every record
+	RuntimeInfo bool `envconfig:"LOG_RUNTIME_INFO" default:"true"`
+	File        logFileConfig
+	Async       logAsyncConfig
+	Sampling    logSamplingConfig
+	OTLP        logOTLPConfig
+}
+
+type logOTLPConfig struct {
+	// Endpoint is the OTLP/HTTP collector URL, e.g. http://localhost:4318;
+	// empty disables exporting logs
+	Endpoint string `envconfig:"LOG_OTLP_ENDPOINT"`
+	// Headers are sent with every export, e.g. LOG_OTLP_HEADERS=x-api-key:abc
+	Headers map[string]string `envconfig:"LOG_OTLP_HEADERS"`
+	// Level is the minimum level exported; empty exports every level
+	Level         string        `envconfig:"LOG_OTLP_LEVEL"`
+	BatchSize     int           `envconfig:"LOG_OTLP_BATCH_SIZE" default:"512"`
+	FlushInterval time.Duration `envconfig:"LOG_OTLP_FLUSH_INTERVAL" default:"1s"`
+}
+
+type logSamplingConfig struct {
+	// Enabled samples repeated records with the same level and message.
+	// Successful request logs are never sampled: they share one message
+	// across every route.
+	Enabled  bool          `envconfig:"LOG_SAMPLING_ENABLED" default:"false"`
+	Interval time.Duration `envconfig:"LOG_SAMPLING_INTERVAL" default:"1s"`
+	// First records per interval are always logged
+	First int `envconfig:"LOG_SAMPLING_FIRST" default:"100"`
+	// Thereafter logs every n-th record below error after the first ones;
+	// errors are deduplicated into a summary instead
+	Thereafter int `envconfig:"LOG_SAMPLING_THEREAFTER" default:"100"`
+}
+
+type logAsyncConfig struct {
+	// Enabled writes log records on a background goroutine
+	Enabled   bool `envconfig:"LOG_ASYNC_ENABLED" default:"true"`
+	QueueSize int  `envconfig:"LOG_ASYNC_QUEUE_SIZE" default:"4096"`
+	// Overflow is block or drop, selecting what happens when the queue is full
+	Overflow string `envconfig:"LOG_ASYNC_OVERFLOW" default:"block"`
+}
+
+type logFileConfig struct {
+	Path string `envconfig:"LOG_FILE_PATH" default:"logs/app.log"`
+	// Level is the minimum level written to the file; empty writes every level
+	Level string `envconfig:"LOG_FILE_LEVEL"`
+	// ErrorPath receives error records only; empty disables it
+	ErrorPath string `envconfig:"LOG_FILE_ERROR_PATH" default:"logs/error.log"`
+	// MaxSizeMB rotates the file when it grows beyond this size; 0 disables it
+	MaxSizeMB int64 `envconfig:"LOG_FILE_MAX_SIZE_MB" default:"100"`
+	// RotateInterval rotates the file on a fixed schedule, e.g. 24h; 0 disables it
+	RotateInterval time.Duration `envconfig:"LOG_FILE_ROTATE_INTERVAL" default:"24h"`
+	MaxBackups     int           `envconfig:"LOG_FILE_MAX_BACKUPS" default:"7"`
+	Compress       bool          `envconfig:"LOG_FILE_COMPRESS" default:"true"`
+	// OnWriteFailure is one of return, stderr or drop
+	OnWriteFailure string `envconfig:"LOG_FILE_ON_WRITE_FAILURE" default:"stderr"`
+}
+
+type adminConfig struct {
+	// Token is the bearer token required by the admin endpoints.
+	// The admin endpoints are disabled when it is empty.
+	Token string `envconfig:"ADMIN_TOKEN"`
+}
+
 type config struct {
 	Environment string `envconfig:"ENV" default:"dev"`
 	App         appConfig
+	Log         logConfig
+	Admin       adminConfig
+	Deeplink    deeplinkConfig
+	ShortLink   shortLinkConfig
+	Analytics   analyticsConfig
 }
 
 var (
 	cfg  config
 	once sync.Once
+
+	// envMu guards fromFile, the variables set from the .env file. Any other
+	// variable was set by the process environment.
+	envMu    sync.Mutex
+	fromFile = map[string]struct{}{}
 )
 
 func Load() {
 	once.Do(func() {
-		_ = godotenv.Load()
+		loadEnvFile()
 		envconfig.MustProcess("", &cfg)
 	})
 }
 
+// loadEnvFile applies the .env file to the environment. Variables set by the
+// process environment take precedence, as with godotenv.Load, while values
+// taken from the file earlier are replaced or removed, so edits to the file
+// apply when it is loaded again.
+func loadEnvFile() {
+	values, err := godotenv.Read()
+	if err != nil {
+		return
+	}
+
+	envMu.Lock()
+	defer envMu.Unlock()
+
+	for key := range fromFile {
+		if _, ok := values[key]; !ok {
+			_ = os.Unsetenv(key)
+			delete(fromFile, key)
+		}
+	}
+	for key, value := range values {
+		if _, loaded := fromFile[key]; !loaded {
+			if _, set := os.LookupEnv(key); set {
+				continue
+			}
+		}
+		_ = os.Setenv(key, value)
+		fromFile[key] = struct{}{}
+	}
+}
+
 func Get() config {
 	return cfg
 }
 
+// LoadLog reads the log configuration again from the .env file and the
+// environment, so log levels can be reloaded without a restart. As on
+// startup, the process environment takes precedence over the .env file.
+func LoadLog() (logConfig, error) {
+	loadEnvFile()
+
+	var log logConfig
+	if err := envconfig.Process("", &log); err != nil {
+		return logConfig{}, err
+	}
+	return log, nil
+}
+
+// LevelOrDefault returns the base log level name. An empty level defaults to
+// debug in development and info elsewhere.
+func (l logConfig) LevelOrDefault(develop bool) string {
+	if l.Level != "" {
+		return l.Level
+	}
+	if develop {
+		return "debug"
+	}
+	return "info"
+}
+
 func (c config) IsDevelop() bool {
 	return c.Environment == "dev"
 }
@@ -0,0 +1,69 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// useEnvFile runs the test in a directory holding a .env file and forgets
+// the variables loaded from it afterwards.
+func useEnvFile(t *testing.T, content string) func(string) {
+	t.Helper()
+	dir := t.TempDir()
+	write := func(content string) {
+		require.NoError(t, os.WriteFile(filepath.Join(dir, ".env"), []byte(content), 0o600))
+	}
+	write(content)
+	t.Chdir(dir)
+
+	t.Cleanup(func() {
+		envMu.Lock()
+		defer envMu.Unlock()
+		for key := range fromFile {
+			_ = os.Unsetenv(key)
+			delete(fromFile, key)
+		}
+	})
+	return write
+}
+
+func TestLoadLogEnvironmentWins(t *testing.T) {
+	t.Setenv("LOG_LEVEL", "warn")
+	useEnvFile(t, "LOG_LEVEL=debug\nLOG_CONSOLE_LEVEL=error\n")
+
+	// startup, as in Load, and reload agree: the environment wins
+	loadEnvFile()
+	assert.Equal(t, "warn", os.Getenv("LOG_LEVEL"))
+	assert.Equal(t, "error", os.Getenv("LOG_CONSOLE_LEVEL"))
+
+	log, err := LoadLog()
+	require.NoError(t, err)
+	assert.Equal(t, "warn", log.Level)
+	assert.Equal(t, "error", log.ConsoleLevel)
+}
+
+func TestLoadLogReloadsEnvFile(t *testing.T) {
+	write := useEnvFile(t, "LOG_LEVEL=info\nLOG_LEVEL_OVERRIDES=deeplink_service:debug\n")
+
+	log, err := LoadLog()
+	require.NoError(t, err)
+	assert.Equal(t, "info", log.Level)
+	assert.Equal(t, map[string]string{"deeplink_service": "debug"}, log.LevelOverrides)
+
+	// edited values apply on reload
+	write("LOG_LEVEL=error\nLOG_LEVEL_OVERRIDES=deeplink_service:warn\n")
+	log, err = LoadLog()
+	require.NoError(t, err)
+	assert.Equal(t, "error", log.Level)
+	assert.Equal(t, map[string]string{"deeplink_service": "warn"}, log.LevelOverrides)
+
+	// removed values fall back to their defaults
+	write("LOG_LEVEL=error\n")
+	log, err = LoadLog()
+	require.NoError(t, err)
+	assert.Empty(t, log.LevelOverrides)
+}
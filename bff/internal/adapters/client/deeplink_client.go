@@ -3,6 +3,7 @@ package client
 import (
 	"context"
 	"deeplink-bff/bff/internal/adapters/handler/dto"
+	"deeplink-bff/bff/internal/core/domain"
 	"encoding/json"
 	"fmt"
 	"net/http"
@@ -19,56 +20,57 @@ func NewDeepLinkClient(baseUrl string) *DeeplinkClient {
 }
 
 func (d *DeeplinkClient) GetDeeplinkList(ctx context.Context) (*dto.GetDeeplinkListResponse, error) {
-
 	url := fmt.Sprintf("%s/api/v1/deeplink", d.baseUrl)
 
-	fmt.Println("--------------------")
-	fmt.Println(url)
-
 	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
 	if err != nil {
-		return nil, fmt.Errorf("failed to create request: %w", err)
+		return nil, domain.Internal(fmt.Errorf("failed to create request: %w", err))
 	}
 
 	resp, err := http.DefaultClient.Do(req)
 	if err != nil {
-		return nil, fmt.Errorf("failed to send request: %w", err)
+		return nil, domain.Internal(fmt.Errorf("failed to send request: %w", err))
 	}
 	defer resp.Body.Close()
 
 	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("unexpected status code: %d", resp.StatusCode)
+		return nil, domain.Internal(fmt.Errorf("unexpected status code: %d", resp.StatusCode))
 	}
 
 	webclientResponse := new(dto.GetDeeplinkListResponse)
-	err = json.NewDecoder(resp.Body).Decode(&webclientResponse)
+	if err := json.NewDecoder(resp.Body).Decode(&webclientResponse); err != nil {
+		return nil, domain.Internal(fmt.Errorf("failed to decode response: %w", err))
+	}
 
-	return webclientResponse, err
+	return webclientResponse, nil
 }
 
 func (d *DeeplinkClient) GetDeeplink(ctx context.Context, id string) (*dto.GetDeeplinkResponse, error) {
 	url := fmt.Sprintf("%s/api/v1/deeplink/%s", d.baseUrl, id)
 
-	fmt.Println("--------------------")
-	fmt.Println(url)
-
 	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
 	if err != nil {
-		return nil, fmt.Errorf("failed to create request: %w", err)
+		return nil, domain.Internal(fmt.Errorf("failed to create request: %w", err))
 	}
 
 	resp, err := http.DefaultClient.Do(req)
 	if err != nil {
-		return nil, fmt.Errorf("failed to send request: %w", err)
+		return nil, domain.Internal(fmt.Errorf("failed to send request: %w", err))
 	}
 	defer resp.Body.Close()
 
+	// unknown and purged deeplinks are the caller's mistake, not ours
+	if resp.StatusCode == http.StatusNotFound || resp.StatusCode == http.StatusGone {
+		return nil, domain.ErrDeeplinkNotFound
+	}
 	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("unexpected status code: %d", resp.StatusCode)
+		return nil, domain.Internal(fmt.Errorf("unexpected status code: %d", resp.StatusCode))
 	}
 
 	webclientResponse := new(dto.GetDeeplinkResponse)
-	err = json.NewDecoder(resp.Body).Decode(&webclientResponse)
+	if err := json.NewDecoder(resp.Body).Decode(&webclientResponse); err != nil {
+		return nil, domain.Internal(fmt.Errorf("failed to decode response: %w", err))
+	}
 
-	return webclientResponse, err
+	return webclientResponse, nil
 }
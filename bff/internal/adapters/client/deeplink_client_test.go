@@ -0,0 +1,46 @@
+package client
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"deeplink-bff/bff/internal/core/domain"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetDeeplinkStatus(t *testing.T) {
+	tests := []struct {
+		name   string
+		status int
+		want   *domain.Error
+	}{
+		{"ok", http.StatusOK, nil},
+		{"not found", http.StatusNotFound, domain.ErrDeeplinkNotFound},
+		{"gone", http.StatusGone, domain.ErrDeeplinkNotFound},
+		{"upstream failure", http.StatusBadGateway, domain.ErrInternal},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				assert.Equal(t, "/api/v1/deeplink/dl-1", r.URL.Path)
+				w.WriteHeader(tt.status)
+				_, _ = w.Write([]byte(`{}`))
+			}))
+			defer server.Close()
+
+			deeplink, err := NewDeepLinkClient(server.URL).GetDeeplink(context.Background(), "dl-1")
+			if tt.want == nil {
+				require.NoError(t, err)
+				assert.NotNil(t, deeplink)
+				return
+			}
+			assert.Nil(t, deeplink)
+			assert.ErrorIs(t, err, tt.want)
+		})
+	}
+}
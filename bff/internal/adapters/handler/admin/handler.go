@@ -0,0 +1,114 @@
+package admin_handler
+
+import (
+	"crypto/subtle"
+	"deeplink-bff/bff/internal/adapters/handler/dto"
+	"deeplink-bff/bff/internal/adapters/handler/response"
+	"deeplink-bff/bff/internal/core/domain"
+	"deeplink-bff/pkg/logx"
+	"log/slog"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+type Handler struct {
+	token  string
+	levels *logx.Levels
+	logger *slog.Logger
+}
+
+func NewHandler(token string, levels *logx.Levels, logger *slog.Logger) *Handler {
+	return &Handler{
+		token,
+		levels,
+		logger,
+	}
+}
+
+// Authenticate rejects requests without the admin bearer token.
+func (h *Handler) Authenticate(c *fiber.Ctx) error {
+	token, ok := strings.CutPrefix(c.Get(fiber.HeaderAuthorization), "Bearer ")
+	if !ok || h.token == "" || subtle.ConstantTimeCompare([]byte(token), []byte(h.token)) != 1 {
+		return response.Error(c, domain.ErrUnauthorized)
+	}
+	return c.Next()
+}
+
+// @Summary	get log level
+// @Schemes
+// @Description	endpoint for the current base log level and the level overrides per logger name
+// @Tags			admin
+// @Produce		json
+// @Success		200	{object}	dto.LogLevelResponse
+// @Failure		401	{object}	dto.ErrorResponse
+// @Router			/admin/log-level [get]
+// @Security		Authorization
+func (h *Handler) GetLogLevel(c *fiber.Ctx) error {
+	return c.Status(200).JSON(h.logLevelResponse())
+}
+
+// @Summary	update log level
+// @Schemes
+// @Description	endpoint for changing the base log level and replacing the level overrides without a restart
+// @Tags			admin
+// @Accept			json
+// @Produce		json
+// @Param			request	body		dto.UpdateLogLevelRequest	true	"log levels"
+// @Success		200		{object}	dto.LogLevelResponse
+// @Failure		400		{object}	dto.ErrorResponse
+// @Failure		401		{object}	dto.ErrorResponse
+// @Router			/admin/log-level [put]
+// @Security		Authorization
+func (h *Handler) UpdateLogLevel(c *fiber.Ctx) error {
+	ctx := c.UserContext()
+
+	request := new(dto.UpdateLogLevelRequest)
+	if err := c.BodyParser(request); err != nil {
+		return response.Error(c, domain.ErrInvalidCommonFields)
+	}
+
+	level, err := logx.ParseLevel(request.Level)
+	if err != nil {
+		return response.Error(c, domain.ErrInvalidCommonFields)
+	}
+	overrides, err := logx.ParseLevelOverrides(request.Overrides)
+	if err != nil {
+		return response.Error(c, domain.ErrInvalidCommonFields)
+	}
+	if err := h.levels.Set(level, overrides); err != nil {
+		return response.Error(c, domain.ErrInvalidCommonFields)
+	}
+
+	h.logger.WarnContext(ctx, "Log level changed",
+		slog.String("log_level", level.String()),
+		slog.Any("overrides", request.Overrides),
+	)
+
+	return c.Status(200).JSON(h.logLevelResponse())
+}
+
+// @Summary	get runtime info
+// @Schemes
+// @Description	endpoint for the build and the process serving requests: host, pid and instance id
+// @Tags			admin
+// @Produce		json
+// @Success		200	{object}	logx.RuntimeInfo
+// @Failure		401	{object}	dto.ErrorResponse
+// @Router			/admin/runtime [get]
+// @Security		Authorization
+func (h *Handler) GetRuntime(c *fiber.Ctx) error {
+	return c.Status(200).JSON(logx.Runtime())
+}
+
+func (h *Handler) logLevelResponse() dto.LogLevelResponse {
+	overrides := make(map[string]string)
+	for name, level := range h.levels.Overrides() {
+		overrides[name] = level.String()
+	}
+
+	return dto.LogLevelResponse{
+		Level:     h.levels.Level().String(),
+		Overrides: overrides,
+	}
+}
@@ -0,0 +1,133 @@
+package admin_handler
+
+import (
+	"deeplink-bff/bff/internal/adapters/handler/dto"
+	"deeplink-bff/pkg/logx"
+	"encoding/json"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testToken = "admin-token"
+
+func newTestApp(levels *logx.Levels) *fiber.App {
+	handler := NewHandler(testToken, levels, slog.New(slog.NewTextHandler(io.Discard, nil)))
+	app := fiber.New()
+	group := app.Group("/admin", handler.Authenticate)
+	group.Get("/log-level", handler.GetLogLevel)
+	group.Put("/log-level", handler.UpdateLogLevel)
+	group.Get("/runtime", handler.GetRuntime)
+	return app
+}
+
+func doRequest(t *testing.T, app *fiber.App, method, path, authorization, body string) (int, []byte) {
+	t.Helper()
+	req := httptest.NewRequest(method, path, strings.NewReader(body))
+	req.Header.Set(fiber.HeaderContentType, fiber.MIMEApplicationJSON)
+	if authorization != "" {
+		req.Header.Set(fiber.HeaderAuthorization, authorization)
+	}
+	resp, err := app.Test(req)
+	require.NoError(t, err)
+	data, err := io.ReadAll(resp.Body)
+	require.NoError(t, err)
+	return resp.StatusCode, data
+}
+
+func TestAuthenticate(t *testing.T) {
+	app := newTestApp(logx.NewLevels(slog.LevelInfo))
+
+	tests := map[string]string{
+		"missing":      "",
+		"not bearer":   "Basic " + testToken,
+		"wrong token":  "Bearer other-token",
+		"empty bearer": "Bearer ",
+	}
+	for name, authorization := range tests {
+		t.Run(name, func(t *testing.T) {
+			status, body := doRequest(t, app, http.MethodGet, "/admin/log-level", authorization, "")
+			assert.Equal(t, http.StatusUnauthorized, status)
+
+			var resp dto.ErrorResponse
+			require.NoError(t, json.Unmarshal(body, &resp))
+			assert.Equal(t, "unauthorized", resp.Message)
+		})
+	}
+}
+
+func TestAuthenticateWithoutToken(t *testing.T) {
+	handler := NewHandler("", logx.NewLevels(slog.LevelInfo), slog.Default())
+	app := fiber.New()
+	app.Get("/admin/log-level", handler.Authenticate, handler.GetLogLevel)
+
+	// an empty configured token must not accept an empty bearer token
+	status, _ := doRequest(t, app, http.MethodGet, "/admin/log-level", "Bearer ", "")
+	assert.Equal(t, http.StatusUnauthorized, status)
+}
+
+func TestUpdateLogLevelValidation(t *testing.T) {
+	tests := map[string]string{
+		"malformed json":   `{"level":`,
+		"unknown level":    `{"level":"verbose"}`,
+		"unknown override": `{"level":"info","overrides":{"deeplink_service":"loud"}}`,
+		"unnamed override": `{"level":"info","overrides":{" ":"debug"}}`,
+	}
+	for name, body := range tests {
+		t.Run(name, func(t *testing.T) {
+			levels := logx.NewLevels(slog.LevelInfo)
+			app := newTestApp(levels)
+
+			status, _ := doRequest(t, app, http.MethodPut, "/admin/log-level", "Bearer "+testToken, body)
+			assert.Equal(t, http.StatusBadRequest, status)
+			// a rejected request leaves the levels unchanged
+			assert.Equal(t, slog.LevelInfo, levels.Level())
+			assert.Empty(t, levels.Overrides())
+		})
+	}
+}
+
+func TestGetLogLevelAfterUpdate(t *testing.T) {
+	levels := logx.NewLevels(slog.LevelInfo)
+	app := newTestApp(levels)
+
+	status, body := doRequest(t, app, http.MethodPut, "/admin/log-level", "Bearer "+testToken, `{"level":"warn","overrides":{"deeplink_service":"debug"}}`)
+	require.Equal(t, http.StatusOK, status, string(body))
+
+	status, body = doRequest(t, app, http.MethodGet, "/admin/log-level", "Bearer "+testToken, "")
+	require.Equal(t, http.StatusOK, status)
+	var resp dto.LogLevelResponse
+	require.NoError(t, json.Unmarshal(body, &resp))
+	assert.Equal(t, dto.LogLevelResponse{
+		Level:     "WARN",
+		Overrides: map[string]string{"deeplink_service": "DEBUG"},
+	}, resp)
+
+	assert.True(t, levels.Enabled("deeplink_service", slog.LevelDebug))
+	assert.False(t, levels.Enabled("shortlink_service", slog.LevelInfo))
+
+	// omitting the overrides removes them
+	status, _ = doRequest(t, app, http.MethodPut, "/admin/log-level", "Bearer "+testToken, `{"level":"info"}`)
+	require.Equal(t, http.StatusOK, status)
+	assert.Empty(t, levels.Overrides())
+}
+
+func TestGetRuntime(t *testing.T) {
+	app := newTestApp(logx.NewLevels(slog.LevelInfo))
+
+	status, _ := doRequest(t, app, http.MethodGet, "/admin/runtime", "", "")
+	assert.Equal(t, http.StatusUnauthorized, status)
+
+	status, body := doRequest(t, app, http.MethodGet, "/admin/runtime", "Bearer "+testToken, "")
+	require.Equal(t, http.StatusOK, status)
+	var resp logx.RuntimeInfo
+	require.NoError(t, json.Unmarshal(body, &resp))
+	assert.Equal(t, logx.Runtime(), resp)
+}
@@ -0,0 +1,76 @@
+package analytics_handler
+
+import (
+	"deeplink-bff/bff/internal/adapters/handler/dto"
+	"deeplink-bff/bff/internal/adapters/handler/response"
+	"deeplink-bff/bff/internal/core/domain"
+	"deeplink-bff/bff/internal/core/ports"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+type Handler struct {
+	analyticsService ports.AnalyticsService
+}
+
+func NewHandler(analyticsService ports.AnalyticsService) *Handler {
+	return &Handler{
+		analyticsService,
+	}
+}
+
+// @Summary	get deeplink stats
+// @Schemes
+// @Description	endpoint for the click and open funnel of a single deeplink
+// @Tags			analytics
+// @Produce		json
+// @Param			id	path		string	true	"deeplink id"
+// @Success		200	{object}	dto.GetDeeplinkStatsResponse
+// @Router			/v1/deeplink/{id}/stats [get]
+// @Security		Authorization
+func (h *Handler) GetDeeplinkStats(c *fiber.Ctx) error {
+	ctx := c.UserContext()
+
+	request := new(dto.GetDeeplinkStatsRequest)
+	if err := c.ParamsParser(request); err != nil {
+		return response.Error(c, domain.ErrInvalidCommonFields)
+	}
+
+	stats, err := h.analyticsService.GetDeeplinkStats(ctx, request)
+	if err != nil {
+		return response.Error(c, err)
+	}
+
+	return c.Status(200).JSON(stats)
+}
+
+// @Summary	get partner stats
+// @Schemes
+// @Description	endpoint for the click and open funnel of a partner, broken down by product
+// @Tags			analytics
+// @Produce		json
+// @Param			partner	path		string	true	"partner code"
+// @Param			from	query		string	false	"RFC 3339 lower bound (inclusive)"
+// @Param			to		query		string	false	"RFC 3339 upper bound (exclusive)"
+// @Success		200		{object}	dto.GetPartnerStatsResponse
+// @Failure		400		{object}	dto.ErrorResponse
+// @Router			/v1/partner/{partner}/stats [get]
+// @Security		Authorization
+func (h *Handler) GetPartnerStats(c *fiber.Ctx) error {
+	ctx := c.UserContext()
+
+	request := new(dto.GetPartnerStatsRequest)
+	if err := c.ParamsParser(request); err != nil {
+		return response.Error(c, domain.ErrInvalidCommonFields)
+	}
+	if err := c.QueryParser(request); err != nil {
+		return response.Error(c, domain.ErrInvalidCommonFields)
+	}
+
+	stats, err := h.analyticsService.GetPartnerStats(ctx, request)
+	if err != nil {
+		return response.Error(c, err)
+	}
+
+	return c.Status(200).JSON(stats)
+}
@@ -2,6 +2,8 @@ package deeplink_handler
 
 import (
 	"deeplink-bff/bff/internal/adapters/handler/dto"
+	"deeplink-bff/bff/internal/adapters/handler/response"
+	"deeplink-bff/bff/internal/core/domain"
 	"deeplink-bff/bff/internal/core/ports"
 	"log/slog"
 
@@ -10,11 +12,13 @@ import (
 
 type Handler struct {
 	deeplinkService ports.DeeplinkService
+	logger          *slog.Logger
 }
 
-func NewHandler(deeplinkService ports.DeeplinkService) *Handler {
+func NewHandler(deeplinkService ports.DeeplinkService, logger *slog.Logger) *Handler {
 	return &Handler{
 		deeplinkService,
+		logger,
 	}
 }
 
@@ -30,12 +34,11 @@ func NewHandler(deeplinkService ports.DeeplinkService) *Handler {
 func (h *Handler) GetDeeplinkList(c *fiber.Ctx) error {
 	ctx := c.UserContext()
 
-	slog.InfoContext(ctx, "Calling GetDeeplinkList in handler", slog.Any("test1", "testinfo1"))
+	h.logger.InfoContext(ctx, "Calling GetDeeplinkList in handler", slog.Any("test1", "testinfo1"))
 
 	deeplinks, err := h.deeplinkService.GetDeeplinkList(ctx)
 	if err != nil {
-		// TODO: using api error standard response
-		return c.Status(500).JSON(fiber.Map{"error": err.Error()})
+		return response.Error(c, err)
 	}
 
 	// TODO: using api standard response
@@ -45,20 +48,86 @@ func (h *Handler) GetDeeplinkList(c *fiber.Ctx) error {
 func (h *Handler) GetDeeplink(c *fiber.Ctx) error {
 	ctx := c.UserContext()
 
-	slog.InfoContext(ctx, "Calling GetDeeplink in handler", slog.Any("test3", "testinfo3"))
+	h.logger.InfoContext(ctx, "Calling GetDeeplink in handler", slog.Any("test3", "testinfo3"))
 
 	request := new(dto.GetDeeplinkRequest)
 	if err := c.ParamsParser(request); err != nil {
-		// TODO: using api error standard response
-		return c.Status(400).JSON(fiber.Map{"error": err.Error()})
+		return response.Error(c, domain.ErrInvalidCommonFields)
 	}
 
 	deeplink, err := h.deeplinkService.GetDeeplink(ctx, request)
 	if err != nil {
-		// TODO: using api error standard response
-		return c.Status(500).JSON(fiber.Map{"error": err.Error()})
+		return response.Error(c, err)
 	}
 
 	// TODO: using api standard response
 	return c.Status(200).JSON(deeplink)
 }
+
+// @Summary	get deeplink QR code
+// @Schemes
+// @Description	endpoint for rendering the deeplink resolve URL as a QR code
+// @Tags			deeplink
+// @Produce		png
+// @Produce		image/svg+xml
+// @Param			id			path		string	true	"deeplink id"
+// @Param			format		query		string	false	"png or svg"					default(png)
+// @Param			size		query		int		false	"image edge length in pixels"	default(256)
+// @Param			level		query		string	false	"error correction level L, M, Q or H"	default(M)
+// @Param			quiet_zone	query		int		false	"blank border in modules"		default(4)
+// @Success		200			{file}		binary
+// @Failure		400			{object}	dto.ErrorResponse
+// @Failure		404			{object}	dto.ErrorResponse
+// @Router			/v1/deeplink/{id}/qr [get]
+// @Security		Authorization
+func (h *Handler) GetDeeplinkQR(c *fiber.Ctx) error {
+	ctx := c.UserContext()
+
+	request := new(dto.GetDeeplinkQRRequest)
+	if err := c.ParamsParser(request); err != nil {
+		return response.Error(c, domain.ErrInvalidCommonFields)
+	}
+	if err := c.QueryParser(request); err != nil {
+		return response.Error(c, domain.ErrInvalidCommonFields)
+	}
+
+	qrCode, err := h.deeplinkService.GetDeeplinkQR(ctx, request)
+	if err != nil {
+		return response.Error(c, err)
+	}
+
+	c.Set(fiber.HeaderContentType, qrCode.ContentType)
+	c.Set(fiber.HeaderCacheControl, "no-store")
+	return c.Status(200).Send(qrCode.Image)
+}
+
+// @Summary	resolve deeplink
+// @Schemes
+// @Description	endpoint for redirecting a shared deeplink to the app or the fallback page
+// @Tags			deeplink
+// @Param			id	path	string	true	"deeplink id"
+// @Success		302
+// @Failure		400	{object}	dto.ErrorResponse
+// @Failure		404	{object}	dto.ErrorResponse
+// @Router			/r/{id} [get]
+func (h *Handler) ResolveDeeplink(c *fiber.Ctx) error {
+	ctx := c.UserContext()
+
+	request := new(dto.ResolveDeeplinkRequest)
+	if err := c.ParamsParser(request); err != nil {
+		return response.Error(c, domain.ErrInvalidCommonFields)
+	}
+	request.Client = dto.ClientInfo{
+		UserAgent: c.Get(fiber.HeaderUserAgent),
+		Referrer:  c.Get(fiber.HeaderReferer),
+		IP:        c.IP(),
+	}
+
+	resolved, err := h.deeplinkService.ResolveDeeplink(ctx, request)
+	if err != nil {
+		return response.Error(c, err)
+	}
+
+	c.Set(fiber.HeaderCacheControl, "no-store")
+	return c.Redirect(resolved.RedirectURL, fiber.StatusFound)
+}
@@ -0,0 +1,13 @@
+package dto
+
+type LogLevelResponse struct {
+	Level string `json:"level"`
+	// Overrides maps a logger name to its level
+	Overrides map[string]string `json:"overrides"`
+}
+
+type UpdateLogLevelRequest struct {
+	Level string `json:"level"`
+	// Overrides replaces every level override; omit it to remove them all
+	Overrides map[string]string `json:"overrides"`
+}
@@ -0,0 +1,44 @@
+package dto
+
+import "time"
+
+type GetDeeplinkStatsRequest struct {
+	Id string `params:"id"`
+}
+
+type GetPartnerStatsRequest struct {
+	Partner string `params:"partner"`
+	// From and To are optional RFC 3339 bounds on the event timestamp
+	From string `query:"from"`
+	To   string `query:"to"`
+}
+
+type Funnel struct {
+	Hits int `json:"hits"`
+	// AppRedirect counts redirects to the app, not confirmed app opens
+	AppRedirect     int     `json:"app_redirect"`
+	Fallback        int     `json:"fallback"`
+	Expired         int     `json:"expired"`
+	AppRedirectRate float64 `json:"app_redirect_rate"`
+}
+
+type GetDeeplinkStatsResponse struct {
+	DeeplinkId string            `json:"deeplink_id"`
+	Partner    string            `json:"partner"`
+	Product    string            `json:"product"`
+	Funnel     Funnel            `json:"funnel"`
+	ByPlatform map[string]Funnel `json:"by_platform"`
+	BySource   map[string]Funnel `json:"by_source"`
+	ByVariant  map[string]Funnel `json:"by_variant"`
+	FirstHitAt *time.Time        `json:"first_hit_at,omitempty"`
+	LastHitAt  *time.Time        `json:"last_hit_at,omitempty"`
+}
+
+type GetPartnerStatsResponse struct {
+	Partner    string            `json:"partner"`
+	Funnel     Funnel            `json:"funnel"`
+	ByProduct  map[string]Funnel `json:"by_product"`
+	ByCampaign map[string]Funnel `json:"by_campaign"`
+	// ByVariant is keyed by "<product>/<variant>"
+	ByVariant map[string]Funnel `json:"by_variant"`
+}
@@ -9,12 +9,15 @@ type GetDeeplinkListResponse struct {
 type GetDeeplinkResponse struct {
 	PartnerTxnCreatedDt  time.Time       `json:"partner_txn_created_dt"`
 	TxnSessionValidUntil time.Time       `json:"txn_session_valid_until"`
+	PartnerCode          string          `json:"partner_code"`
 	ProductCode          string          `json:"product_code"`
 	ChannelDestination   string          `json:"channel_destination"`
 	PartnerTxnRef        string          `json:"partner_txn_ref"`
 	PartnerDeeplink      PartnerDeeplink `json:"partner_deeplink"`
 	DynamicFields        interface{}     `json:"dynamic_fields"`
 	Email                string          `json:"email"`
+	Campaign             *Campaign       `json:"campaign,omitempty"`
+	Variant              string          `json:"variant,omitempty"`
 }
 
 type PartnerDeeplink struct {
@@ -22,6 +25,46 @@ type PartnerDeeplink struct {
 	Fail    string `json:"fail"`
 }
 
+type Campaign struct {
+	Source  string            `json:"source"`
+	Medium  string            `json:"medium"`
+	Name    string            `json:"name"`
+	Term    string            `json:"term,omitempty"`
+	Content string            `json:"content,omitempty"`
+	Params  map[string]string `json:"params,omitempty"`
+}
+
 type GetDeeplinkRequest struct {
 	Id string `param:"id"`
 }
+
+type GetDeeplinkQRRequest struct {
+	Id        string `params:"id"`
+	Format    string `query:"format"`
+	Size      int    `query:"size"`
+	Level     string `query:"level"`
+	QuietZone *int   `query:"quiet_zone"`
+}
+
+type GetDeeplinkQRResponse struct {
+	ContentType string
+	Image       []byte
+}
+
+// ClientInfo describes the client that hit a public deeplink URL.
+type ClientInfo struct {
+	UserAgent string
+	Referrer  string
+	IP        string
+}
+
+type ResolveDeeplinkRequest struct {
+	Id string `params:"id"`
+	// ShortCode is set when the deeplink was reached through a short link
+	ShortCode string
+	Client    ClientInfo
+}
+
+type ResolveDeeplinkResponse struct {
+	RedirectURL string
+}
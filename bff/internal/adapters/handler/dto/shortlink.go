@@ -0,0 +1,19 @@
+package dto
+
+import "time"
+
+type CreateShortLinkRequest struct {
+	DeeplinkId string `params:"id" json:"-"`
+	Alias      string `json:"alias"`
+}
+
+type CreateShortLinkResponse struct {
+	Code      string    `json:"code"`
+	ShortURL  string    `json:"short_url"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+type ResolveShortLinkRequest struct {
+	Code   string `params:"code"`
+	Client ClientInfo
+}
@@ -0,0 +1,28 @@
+package response
+
+import (
+	"deeplink-bff/bff/internal/adapters/handler/dto"
+	"deeplink-bff/bff/internal/core/domain"
+	"deeplink-bff/middleware"
+	"errors"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// Error writes the standard error envelope for err.
+// Errors that are not a *domain.Error are reported as DL9999.
+// err is recorded for the request log, with the stack trace it carries.
+func Error(c *fiber.Ctx, err error) error {
+	middleware.RecordError(c.UserContext(), err)
+
+	var domainErr *domain.Error
+	if !errors.As(err, &domainErr) {
+		domainErr = domain.ErrInternal
+	}
+
+	return c.Status(domainErr.Status).JSON(dto.ErrorResponse{
+		Code:      domainErr.Code.String(),
+		Message:   domainErr.Message,
+		RequestID: middleware.RequestIDFromContext(c.UserContext()),
+	})
+}
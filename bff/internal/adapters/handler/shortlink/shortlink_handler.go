@@ -0,0 +1,85 @@
+package shortlink_handler
+
+import (
+	"deeplink-bff/bff/internal/adapters/handler/dto"
+	"deeplink-bff/bff/internal/adapters/handler/response"
+	"deeplink-bff/bff/internal/core/domain"
+	"deeplink-bff/bff/internal/core/ports"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+type Handler struct {
+	shortLinkService ports.ShortLinkService
+}
+
+func NewHandler(shortLinkService ports.ShortLinkService) *Handler {
+	return &Handler{
+		shortLinkService,
+	}
+}
+
+// @Summary	create short link
+// @Schemes
+// @Description	endpoint for creating a short link bound to a deeplink
+// @Tags			short-link
+// @Accept			application/json
+// @Produce		json
+// @Param			id		path		string						true	"deeplink id"
+// @Param			request	body		dto.CreateShortLinkRequest	false	"optional custom alias"
+// @Success		201		{object}	dto.CreateShortLinkResponse
+// @Failure		400		{object}	dto.ErrorResponse
+// @Failure		409		{object}	dto.ErrorResponse
+// @Router			/v1/deeplink/{id}/short-link [post]
+// @Security		Authorization
+func (h *Handler) CreateShortLink(c *fiber.Ctx) error {
+	ctx := c.UserContext()
+
+	request := new(dto.CreateShortLinkRequest)
+	if len(c.Body()) > 0 {
+		if err := c.BodyParser(request); err != nil {
+			return response.Error(c, domain.ErrInvalidCommonFields)
+		}
+	}
+	if err := c.ParamsParser(request); err != nil {
+		return response.Error(c, domain.ErrInvalidCommonFields)
+	}
+
+	shortLink, err := h.shortLinkService.CreateShortLink(ctx, request)
+	if err != nil {
+		return response.Error(c, err)
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(shortLink)
+}
+
+// @Summary	resolve short link
+// @Schemes
+// @Description	endpoint for redirecting a short code to the app or the fallback page
+// @Tags			short-link
+// @Param			code	path	string	true	"short code or alias"
+// @Success		302
+// @Failure		400	{object}	dto.ErrorResponse
+// @Failure		404	{object}	dto.ErrorResponse
+// @Router			/s/{code} [get]
+func (h *Handler) ResolveShortLink(c *fiber.Ctx) error {
+	ctx := c.UserContext()
+
+	request := new(dto.ResolveShortLinkRequest)
+	if err := c.ParamsParser(request); err != nil {
+		return response.Error(c, domain.ErrInvalidCommonFields)
+	}
+	request.Client = dto.ClientInfo{
+		UserAgent: c.Get(fiber.HeaderUserAgent),
+		Referrer:  c.Get(fiber.HeaderReferer),
+		IP:        c.IP(),
+	}
+
+	resolved, err := h.shortLinkService.ResolveShortLink(ctx, request)
+	if err != nil {
+		return response.Error(c, err)
+	}
+
+	c.Set(fiber.HeaderCacheControl, "no-store")
+	return c.Redirect(resolved.RedirectURL, fiber.StatusFound)
+}
@@ -0,0 +1,84 @@
+package analytics_repository
+
+import (
+	"context"
+	"deeplink-bff/bff/internal/core/domain"
+	"sync"
+	"time"
+)
+
+// DefaultMaxEvents is the number of events kept when no limit is given.
+const DefaultMaxEvents = 100_000
+
+// MemoryRepository keeps the most recent click events in a ring buffer.
+// Older events are overwritten once maxEvents is reached.
+type MemoryRepository struct {
+	mu     sync.RWMutex
+	events []domain.ClickEvent
+	next   int
+	full   bool
+}
+
+func NewMemoryRepository(maxEvents int) *MemoryRepository {
+	if maxEvents <= 0 {
+		maxEvents = DefaultMaxEvents
+	}
+	return &MemoryRepository{
+		events: make([]domain.ClickEvent, maxEvents),
+	}
+}
+
+func (r *MemoryRepository) Save(ctx context.Context, event *domain.ClickEvent) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.events[r.next] = *event
+	r.next++
+	if r.next == len(r.events) {
+		r.next = 0
+		r.full = true
+	}
+	return nil
+}
+
+func (r *MemoryRepository) FindByDeeplink(ctx context.Context, deeplinkID string) ([]domain.ClickEvent, error) {
+	return r.find(func(event *domain.ClickEvent) bool {
+		return event.DeeplinkID == deeplinkID
+	}), nil
+}
+
+func (r *MemoryRepository) FindByPartner(ctx context.Context, partner string, from, to time.Time) ([]domain.ClickEvent, error) {
+	return r.find(func(event *domain.ClickEvent) bool {
+		if event.Partner != partner {
+			return false
+		}
+		if !from.IsZero() && event.Timestamp.Before(from) {
+			return false
+		}
+		if !to.IsZero() && !event.Timestamp.Before(to) {
+			return false
+		}
+		return true
+	}), nil
+}
+
+// find returns matching events from oldest to newest.
+func (r *MemoryRepository) find(match func(*domain.ClickEvent) bool) []domain.ClickEvent {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var result []domain.ClickEvent
+	collect := func(events []domain.ClickEvent) {
+		for i := range events {
+			if match(&events[i]) {
+				result = append(result, events[i])
+			}
+		}
+	}
+
+	if r.full {
+		collect(r.events[r.next:])
+	}
+	collect(r.events[:r.next])
+	return result
+}
@@ -0,0 +1,58 @@
+package shortlink_repository
+
+import (
+	"context"
+	"deeplink-bff/bff/internal/core/domain"
+	"sync"
+	"time"
+)
+
+// MemoryRepository keeps short links in process memory.
+// It is meant for a single instance; use a shared store when running replicas.
+type MemoryRepository struct {
+	mu    sync.RWMutex
+	links map[string]domain.ShortLink
+}
+
+func NewMemoryRepository() *MemoryRepository {
+	return &MemoryRepository{
+		links: make(map[string]domain.ShortLink),
+	}
+}
+
+func (r *MemoryRepository) Create(ctx context.Context, shortLink *domain.ShortLink) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if existing, ok := r.links[shortLink.Code]; ok && !domain.IsExpired(existing.ExpiresAt, time.Now()) {
+		return domain.ErrShortCodeTaken
+	}
+	r.links[shortLink.Code] = *shortLink
+	return nil
+}
+
+func (r *MemoryRepository) GetByCode(ctx context.Context, code string) (*domain.ShortLink, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	// expired links stay until DeleteExpired runs, but are gone for readers
+	shortLink, ok := r.links[code]
+	if !ok || domain.IsExpired(shortLink.ExpiresAt, time.Now()) {
+		return nil, domain.ErrShortLinkNotFound
+	}
+	return &shortLink, nil
+}
+
+func (r *MemoryRepository) DeleteExpired(ctx context.Context, now time.Time) (int, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	deleted := 0
+	for code, shortLink := range r.links {
+		if domain.IsExpired(shortLink.ExpiresAt, now) {
+			delete(r.links, code)
+			deleted++
+		}
+	}
+	return deleted, nil
+}
@@ -0,0 +1,73 @@
+package shortlink_repository
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"deeplink-bff/bff/internal/core/domain"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMemoryRepository(t *testing.T) {
+	ctx := context.Background()
+	now := time.Now()
+
+	tests := []struct {
+		name      string
+		expiresAt time.Time
+		found     bool
+		reusable  bool
+	}{
+		{"active", now.Add(time.Hour), true, false},
+		{"never expires", time.Time{}, true, false},
+		{"expired", now.Add(-time.Minute), false, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repository := NewMemoryRepository()
+			require.NoError(t, repository.Create(ctx, &domain.ShortLink{Code: "abc1234", DeeplinkID: "dl-1", ExpiresAt: tt.expiresAt}))
+
+			shortLink, err := repository.GetByCode(ctx, "abc1234")
+			if tt.found {
+				require.NoError(t, err)
+				assert.Equal(t, "dl-1", shortLink.DeeplinkID)
+			} else {
+				assert.ErrorIs(t, err, domain.ErrShortLinkNotFound)
+			}
+
+			err = repository.Create(ctx, &domain.ShortLink{Code: "abc1234", DeeplinkID: "dl-2", ExpiresAt: now.Add(time.Hour)})
+			if !tt.reusable {
+				assert.ErrorIs(t, err, domain.ErrShortCodeTaken)
+				return
+			}
+			require.NoError(t, err)
+			shortLink, err = repository.GetByCode(ctx, "abc1234")
+			require.NoError(t, err)
+			assert.Equal(t, "dl-2", shortLink.DeeplinkID)
+		})
+	}
+}
+
+func TestMemoryRepositoryUnknownCode(t *testing.T) {
+	_, err := NewMemoryRepository().GetByCode(context.Background(), "missing")
+	assert.ErrorIs(t, err, domain.ErrShortLinkNotFound)
+}
+
+func TestMemoryRepositoryDeleteExpired(t *testing.T) {
+	ctx := context.Background()
+	now := time.Now()
+	repository := NewMemoryRepository()
+	require.NoError(t, repository.Create(ctx, &domain.ShortLink{Code: "active1", ExpiresAt: now.Add(time.Hour)}))
+	require.NoError(t, repository.Create(ctx, &domain.ShortLink{Code: "expired", ExpiresAt: now.Add(-time.Hour)}))
+	require.NoError(t, repository.Create(ctx, &domain.ShortLink{Code: "forever"}))
+
+	deleted, err := repository.DeleteExpired(ctx, now)
+	require.NoError(t, err)
+	assert.Equal(t, 1, deleted)
+	assert.Len(t, repository.links, 2)
+	assert.NotContains(t, repository.links, "expired")
+}
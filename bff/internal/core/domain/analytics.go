@@ -0,0 +1,101 @@
+package domain
+
+import (
+	"net/netip"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// Outcome is what happened to the user after a deeplink was hit.
+type Outcome string
+
+// Platform is the client platform derived from the User-Agent.
+type Platform string
+
+// Source is the entry point a deeplink was hit through.
+type Source string
+
+const (
+	// OutcomeAppRedirect is a redirect to the app. Whether the app opened is
+	// not known: the client may not have it installed.
+	OutcomeAppRedirect Outcome = "app_redirect"
+	OutcomeFallback    Outcome = "fallback"
+	OutcomeExpired     Outcome = "expired"
+
+	PlatformIOS     Platform = "ios"
+	PlatformAndroid Platform = "android"
+	PlatformDesktop Platform = "desktop"
+	PlatformUnknown Platform = "unknown"
+
+	SourceResolve   Source = "resolve"
+	SourceShortLink Source = "short_link"
+)
+
+// ClickEvent records a single hit on a resolve or short link.
+type ClickEvent struct {
+	DeeplinkID string
+	Partner    string
+	Product    string
+	Source     Source
+	Platform   Platform
+	// Referrer holds only the scheme and host of the Referer header
+	Referrer string
+	// IPPrefix is the client address truncated to /24 (IPv4) or /48 (IPv6)
+	IPPrefix string
+	Campaign Campaign
+	// Variant is the destination variant the hit was routed to, if any
+	Variant   string
+	Outcome   Outcome
+	Timestamp time.Time
+}
+
+// IsMobile reports whether the platform can open the app.
+func (p Platform) IsMobile() bool {
+	return p == PlatformIOS || p == PlatformAndroid
+}
+
+// DetectPlatform derives the client platform from a User-Agent header.
+func DetectPlatform(userAgent string) Platform {
+	ua := strings.ToLower(userAgent)
+	switch {
+	case ua == "":
+		return PlatformUnknown
+	case strings.Contains(ua, "iphone"), strings.Contains(ua, "ipad"), strings.Contains(ua, "ipod"):
+		return PlatformIOS
+	case strings.Contains(ua, "android"):
+		return PlatformAndroid
+	default:
+		return PlatformDesktop
+	}
+}
+
+// IPPrefix truncates ip so that an individual client cannot be identified.
+// It returns an empty string when ip cannot be parsed.
+func IPPrefix(ip string) string {
+	addr, err := netip.ParseAddr(ip)
+	if err != nil {
+		return ""
+	}
+
+	bits := 48
+	if addr.Unmap().Is4() {
+		addr, bits = addr.Unmap(), 24
+	}
+
+	prefix, err := addr.Prefix(bits)
+	if err != nil {
+		return ""
+	}
+	return prefix.String()
+}
+
+// ReferrerOrigin strips the path and query from a Referer header, which may
+// carry tokens or personal data.
+func ReferrerOrigin(referrer string) string {
+	u, err := url.Parse(referrer)
+	if err != nil || u.Host == "" {
+		return ""
+	}
+	return u.Scheme + "://" + u.Host
+}
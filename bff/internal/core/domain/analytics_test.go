@@ -0,0 +1,38 @@
+package domain
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIPPrefix(t *testing.T) {
+	tests := map[string]string{
+		"203.0.113.42":               "203.0.113.0/24",
+		"203.0.113.0":                "203.0.113.0/24",
+		"::ffff:203.0.113.42":        "203.0.113.0/24",
+		"2001:db8:abcd:12:1:2:3:4":   "2001:db8:abcd::/48",
+		"2001:db8::1":                "2001:db8::/48",
+		"fe80::1%eth0":               "fe80::/48",
+		"":                           "",
+		"not an ip":                  "",
+		"203.0.113.42:8080":          "",
+		"[2001:db8:abcd:12::1]:8080": "",
+	}
+	for ip, want := range tests {
+		assert.Equal(t, want, IPPrefix(ip), ip)
+	}
+}
+
+func TestDetectPlatform(t *testing.T) {
+	tests := map[string]Platform{
+		"Mozilla/5.0 (iPhone; CPU iPhone OS 17_0 like Mac OS X)": PlatformIOS,
+		"Mozilla/5.0 (iPad; CPU OS 17_0 like Mac OS X)":          PlatformIOS,
+		"Mozilla/5.0 (Linux; Android 14; Pixel 8)":               PlatformAndroid,
+		"Mozilla/5.0 (Windows NT 10.0; Win64; x64)":              PlatformDesktop,
+		"": PlatformUnknown,
+	}
+	for userAgent, want := range tests {
+		assert.Equal(t, want, DetectPlatform(userAgent), userAgent)
+	}
+}
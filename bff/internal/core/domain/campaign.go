@@ -0,0 +1,80 @@
+package domain
+
+import "net/url"
+
+// Campaign is the marketing attribution a deeplink carries.
+type Campaign struct {
+	Source  string
+	Medium  string
+	Name    string
+	Term    string
+	Content string
+	// Params holds additional query parameters configured for the campaign
+	Params map[string]string
+}
+
+// IsZero reports whether the campaign carries no attribution.
+func (c Campaign) IsZero() bool {
+	return c.Source == "" && c.Medium == "" && c.Name == "" && c.Term == "" && c.Content == "" && len(c.Params) == 0
+}
+
+// Values returns the campaign as query parameters. The utm_* fields take
+// precedence over Params with the same key.
+func (c Campaign) Values() url.Values {
+	values := url.Values{}
+	for key, value := range c.Params {
+		if key != "" && value != "" {
+			values.Set(key, value)
+		}
+	}
+
+	utm := map[string]string{
+		"utm_source":   c.Source,
+		"utm_medium":   c.Medium,
+		"utm_campaign": c.Name,
+		"utm_term":     c.Term,
+		"utm_content":  c.Content,
+	}
+	for key, value := range utm {
+		if value != "" {
+			values.Set(key, value)
+		}
+	}
+
+	return values
+}
+
+// MergeQuery appends params to rawURL. Parameters already present in rawURL
+// are kept as they are, and the existing query string is not re-encoded so
+// partner URLs that rely on a particular ordering or escaping keep working.
+func MergeQuery(rawURL string, params url.Values) (string, error) {
+	if len(params) == 0 || rawURL == "" {
+		return rawURL, nil
+	}
+
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return rawURL, err
+	}
+
+	// a malformed pair in the existing query only hides that pair from the
+	// duplicate check, so the parse error is ignored
+	existing, _ := url.ParseQuery(u.RawQuery)
+
+	added := url.Values{}
+	for key, values := range params {
+		if _, ok := existing[key]; !ok {
+			added[key] = values
+		}
+	}
+	if len(added) == 0 {
+		return rawURL, nil
+	}
+
+	if u.RawQuery == "" {
+		u.RawQuery = added.Encode()
+	} else {
+		u.RawQuery += "&" + added.Encode()
+	}
+	return u.String(), nil
+}
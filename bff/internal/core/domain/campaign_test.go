@@ -0,0 +1,134 @@
+package domain
+
+import (
+	"net/url"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCampaignValues(t *testing.T) {
+	tests := []struct {
+		name     string
+		campaign Campaign
+		want     url.Values
+	}{
+		{"zero", Campaign{}, url.Values{}},
+		{
+			name:     "utm fields",
+			campaign: Campaign{Source: "sms", Medium: "push", Name: "summer", Term: "loan", Content: "banner"},
+			want: url.Values{
+				"utm_source":   {"sms"},
+				"utm_medium":   {"push"},
+				"utm_campaign": {"summer"},
+				"utm_term":     {"loan"},
+				"utm_content":  {"banner"},
+			},
+		},
+		{
+			name:     "empty fields and params are skipped",
+			campaign: Campaign{Source: "sms", Params: map[string]string{"ref": "", "": "x", "branch": "042"}},
+			want:     url.Values{"utm_source": {"sms"}, "branch": {"042"}},
+		},
+		{
+			name:     "utm fields take precedence over params",
+			campaign: Campaign{Source: "sms", Params: map[string]string{"utm_source": "email", "utm_medium": "print"}},
+			want:     url.Values{"utm_source": {"sms"}, "utm_medium": {"print"}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, tt.campaign.Values())
+			assert.Equal(t, tt.name == "zero", tt.campaign.IsZero())
+		})
+	}
+}
+
+func TestMergeQuery(t *testing.T) {
+	tests := []struct {
+		name   string
+		rawURL string
+		params url.Values
+		want   string
+	}{
+		{
+			name:   "no query",
+			rawURL: "https://partner.example.com/landing",
+			params: url.Values{"utm_source": {"sms"}},
+			want:   "https://partner.example.com/landing?utm_source=sms",
+		},
+		{
+			name:   "appended to existing query",
+			rawURL: "https://partner.example.com/landing?ref=42",
+			params: url.Values{"utm_source": {"sms"}, "utm_medium": {"push"}},
+			want:   "https://partner.example.com/landing?ref=42&utm_medium=push&utm_source=sms",
+		},
+		{
+			name:   "reserved characters are encoded",
+			rawURL: "https://partner.example.com/landing",
+			params: url.Values{"utm_campaign": {"a&b=c d/é?#"}},
+			want:   "https://partner.example.com/landing?utm_campaign=a%26b%3Dc+d%2F%C3%A9%3F%23",
+		},
+		{
+			name:   "destination utm_source wins",
+			rawURL: "https://partner.example.com/landing?utm_source=partner",
+			params: url.Values{"utm_source": {"sms"}, "utm_medium": {"push"}},
+			want:   "https://partner.example.com/landing?utm_source=partner&utm_medium=push",
+		},
+		{
+			name:   "all params present",
+			rawURL: "https://partner.example.com/landing?utm_source=partner&utm_medium=email",
+			params: url.Values{"utm_source": {"sms"}, "utm_medium": {"push"}},
+			want:   "https://partner.example.com/landing?utm_source=partner&utm_medium=email",
+		},
+		{
+			name:   "existing query is not re-encoded",
+			rawURL: "https://partner.example.com/landing?b=2&a=x%20y;c",
+			params: url.Values{"utm_source": {"sms"}},
+			want:   "https://partner.example.com/landing?b=2&a=x%20y;c&utm_source=sms",
+		},
+		{
+			name:   "fragment kept",
+			rawURL: "https://partner.example.com/landing?ref=42#offer",
+			params: url.Values{"utm_source": {"sms"}},
+			want:   "https://partner.example.com/landing?ref=42&utm_source=sms#offer",
+		},
+		{
+			name:   "app scheme",
+			rawURL: "myapp://deeplink/dl-1",
+			params: url.Values{"utm_source": {"sms"}},
+			want:   "myapp://deeplink/dl-1?utm_source=sms",
+		},
+		{
+			name:   "no params",
+			rawURL: "https://partner.example.com/landing?ref=42",
+			params: url.Values{},
+			want:   "https://partner.example.com/landing?ref=42",
+		},
+		{
+			name:   "empty URL",
+			rawURL: "",
+			params: url.Values{"utm_source": {"sms"}},
+			want:   "",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := MergeQuery(tt.rawURL, tt.params)
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestMergeQueryInvalidURL(t *testing.T) {
+	for _, rawURL := range []string{"https://partner.example.com/%zz", "://missing-scheme", "https://[::1"} {
+		got, err := MergeQuery(rawURL, url.Values{"utm_source": {"sms"}})
+		assert.Error(t, err, rawURL)
+		// the URL is returned unchanged so callers can still redirect to it
+		assert.Equal(t, rawURL, got)
+	}
+}
@@ -0,0 +1,18 @@
+package domain
+
+import (
+	"net/url"
+	"strings"
+	"time"
+)
+
+// ResolveURL returns the public URL that resolves the deeplink with the given id.
+func ResolveURL(baseURL, id string) string {
+	return strings.TrimRight(baseURL, "/") + "/" + url.PathEscape(id)
+}
+
+// IsExpired reports whether a session valid until validUntil has ended at now.
+// A zero validUntil never expires.
+func IsExpired(validUntil, now time.Time) bool {
+	return !validUntil.IsZero() && now.After(validUntil)
+}
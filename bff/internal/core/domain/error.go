@@ -0,0 +1,59 @@
+package domain
+
+import (
+	"deeplink-bff/constant"
+	"net/http"
+
+	"github.com/mdobak/go-xerrors"
+)
+
+// Error is a business error carrying the API error code and the HTTP status
+// it should be reported with.
+type Error struct {
+	Code    constant.Code
+	Status  int
+	Message string
+}
+
+func (e *Error) Error() string {
+	return e.Message
+}
+
+// ErrorCode returns the API error code, rendered as the code field of logged errors.
+func (e *Error) ErrorCode() string {
+	return e.Code.String()
+}
+
+// StatusCode returns the HTTP status, rendered as the status field of logged errors.
+func (e *Error) StatusCode() int {
+	return e.Status
+}
+
+// NewError creates a business error.
+func NewError(code constant.Code, status int, message string) *Error {
+	return &Error{
+		Code:    code,
+		Status:  status,
+		Message: message,
+	}
+}
+
+// Internal wraps an unexpected error as ErrInternal with the stack trace of
+// the caller, so the request log of the 5xx response shows where it
+// originated. It returns nil if err is nil.
+func Internal(err error) error {
+	if err == nil {
+		return nil
+	}
+	return xerrors.WithStackTrace(xerrors.WithWrapper(ErrInternal, err), 1)
+}
+
+var (
+	ErrInvalidCommonFields = NewError(constant.CodeInvalidCommonFields, http.StatusBadRequest, "invalid request")
+	ErrUnauthorized        = NewError(constant.CodeUnauthorized, http.StatusUnauthorized, "unauthorized")
+	ErrDeeplinkExpired     = NewError(constant.CodeDeeplinkExpired, http.StatusBadRequest, "deeplink expired")
+	ErrDeeplinkNotFound    = NewError(constant.CodeInvalidDeeplink, http.StatusNotFound, "deeplink not found")
+	ErrShortLinkNotFound   = NewError(constant.CodeInvalidDeeplink, http.StatusNotFound, "short link not found")
+	ErrShortCodeTaken      = NewError(constant.CodeDuplicateShortCode, http.StatusConflict, "short code already taken")
+	ErrInternal            = NewError(constant.CodeInternal, http.StatusInternalServerError, "internal server error")
+)
@@ -0,0 +1,14 @@
+package domain
+
+import "time"
+
+// ShortLink binds a short code to a deeplink. It expires together with the
+// deeplink session.
+type ShortLink struct {
+	Code       string
+	DeeplinkID string
+	Partner    string
+	Alias      bool
+	CreatedAt  time.Time
+	ExpiresAt  time.Time
+}
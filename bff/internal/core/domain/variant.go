@@ -0,0 +1,85 @@
+package domain
+
+import (
+	"crypto/sha256"
+	"encoding/binary"
+	"errors"
+	"fmt"
+)
+
+// Variant is one weighted destination of an experiment. Empty URLs fall back
+// to the default app and fallback destinations.
+type Variant struct {
+	Name        string
+	Weight      int
+	AppBaseURL  string
+	FallbackURL string
+}
+
+// Experiment splits deeplinks of a product, and optionally a channel, across
+// destination variants.
+type Experiment struct {
+	ProductCode string
+	// Channel restricts the experiment to one channel destination; empty matches all
+	Channel  string
+	Variants []Variant
+}
+
+// Validate reports whether the experiment can assign variants.
+func (e Experiment) Validate() error {
+	if e.ProductCode == "" {
+		return errors.New("experiment product code is required")
+	}
+	if len(e.Variants) == 0 {
+		return fmt.Errorf("experiment %s has no variants", e.ProductCode)
+	}
+
+	// a zero weight pauses a variant without removing it from the experiment
+	seen := make(map[string]struct{}, len(e.Variants))
+	total := 0
+	for _, variant := range e.Variants {
+		if variant.Name == "" {
+			return fmt.Errorf("experiment %s has a variant without a name", e.ProductCode)
+		}
+		if variant.Weight < 0 {
+			return fmt.Errorf("experiment %s variant %s must not have a negative weight", e.ProductCode, variant.Name)
+		}
+		if _, ok := seen[variant.Name]; ok {
+			return fmt.Errorf("experiment %s has duplicate variant %s", e.ProductCode, variant.Name)
+		}
+		seen[variant.Name] = struct{}{}
+		total += variant.Weight
+	}
+	if total == 0 {
+		return fmt.Errorf("experiment %s has no variant with a positive weight", e.ProductCode)
+	}
+	return nil
+}
+
+// Matches reports whether the experiment applies to a deeplink.
+func (e Experiment) Matches(productCode, channel string) bool {
+	return e.ProductCode == productCode && (e.Channel == "" || e.Channel == channel)
+}
+
+// Assign picks a variant for the deeplink. The choice is a hash of the
+// experiment and the deeplink id, so the same deeplink always gets the same
+// variant while different experiments split independently.
+func (e Experiment) Assign(deeplinkID string) Variant {
+	total := 0
+	for _, variant := range e.Variants {
+		total += variant.Weight
+	}
+
+	// the low bits of FNV only depend on the parity of the input bytes, which
+	// skews splits over even totals, so a cryptographic hash is used instead
+	sum := sha256.Sum256([]byte(e.ProductCode + "\x00" + e.Channel + "\x00" + deeplinkID))
+	bucket := int(binary.BigEndian.Uint64(sum[:8]) % uint64(total))
+
+	for _, variant := range e.Variants {
+		if bucket < variant.Weight {
+			return variant
+		}
+		bucket -= variant.Weight
+	}
+	return e.Variants[len(e.Variants)-1]
+}
@@ -0,0 +1,105 @@
+package domain
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestExperimentValidate(t *testing.T) {
+	tests := []struct {
+		name     string
+		variants []Variant
+		wantErr  string
+	}{
+		{"valid", []Variant{{Name: "a", Weight: 50}, {Name: "b", Weight: 50}}, ""},
+		{"paused variant", []Variant{{Name: "a", Weight: 1}, {Name: "b", Weight: 0}}, ""},
+		{"no variants", nil, "has no variants"},
+		{"unnamed variant", []Variant{{Weight: 1}}, "without a name"},
+		{"negative weight", []Variant{{Name: "a", Weight: 2}, {Name: "b", Weight: -1}}, "variant b must not have a negative weight"},
+		{"zero total weight", []Variant{{Name: "a"}, {Name: "b"}}, "no variant with a positive weight"},
+		{"duplicate names", []Variant{{Name: "a", Weight: 1}, {Name: "a", Weight: 1}}, "duplicate variant a"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := Experiment{ProductCode: "loan", Variants: tt.variants}.Validate()
+			if tt.wantErr == "" {
+				assert.NoError(t, err)
+				return
+			}
+			assert.ErrorContains(t, err, tt.wantErr)
+		})
+	}
+
+	assert.ErrorContains(t, Experiment{Variants: []Variant{{Name: "a", Weight: 1}}}.Validate(), "product code is required")
+}
+
+func TestExperimentMatches(t *testing.T) {
+	allChannels := Experiment{ProductCode: "loan"}
+	sms := Experiment{ProductCode: "loan", Channel: "sms"}
+
+	assert.True(t, allChannels.Matches("loan", "sms"))
+	assert.True(t, allChannels.Matches("loan", ""))
+	assert.False(t, allChannels.Matches("card", "sms"))
+	assert.True(t, sms.Matches("loan", "sms"))
+	assert.False(t, sms.Matches("loan", "push"))
+}
+
+func TestExperimentAssignDeterministic(t *testing.T) {
+	experiment := Experiment{ProductCode: "loan", Variants: []Variant{{Name: "a", Weight: 1}, {Name: "b", Weight: 1}}}
+
+	for i := 0; i < 100; i++ {
+		id := fmt.Sprintf("dl-%d", i)
+		assert.Equal(t, experiment.Assign(id), experiment.Assign(id), id)
+	}
+
+	// another experiment splits the same ids independently
+	other := Experiment{ProductCode: "card", Variants: experiment.Variants}
+	differ := 0
+	for i := 0; i < 100; i++ {
+		id := fmt.Sprintf("dl-%d", i)
+		if experiment.Assign(id).Name != other.Assign(id).Name {
+			differ++
+		}
+	}
+	assert.Greater(t, differ, 0)
+}
+
+func TestExperimentAssignWeights(t *testing.T) {
+	tests := []struct {
+		name     string
+		variants []Variant
+	}{
+		{"even", []Variant{{Name: "a", Weight: 50}, {Name: "b", Weight: 50}}},
+		{"uneven", []Variant{{Name: "a", Weight: 10}, {Name: "b", Weight: 30}, {Name: "c", Weight: 60}}},
+		{"paused variants", []Variant{{Name: "a", Weight: 0}, {Name: "b", Weight: 3}, {Name: "c", Weight: 0}, {Name: "d", Weight: 1}, {Name: "e", Weight: 0}}},
+	}
+
+	const ids = 20000
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			experiment := Experiment{ProductCode: "loan", Variants: tt.variants}
+			total := 0
+			for _, variant := range tt.variants {
+				total += variant.Weight
+			}
+
+			counts := make(map[string]int)
+			for i := 0; i < ids; i++ {
+				counts[experiment.Assign(fmt.Sprintf("dl-%d", i)).Name]++
+			}
+
+			for _, variant := range tt.variants {
+				if variant.Weight == 0 {
+					assert.Zero(t, counts[variant.Name], "paused variant %s was assigned", variant.Name)
+					continue
+				}
+				share := float64(counts[variant.Name]) / ids
+				want := float64(variant.Weight) / float64(total)
+				assert.InDelta(t, want, share, 0.02, "variant %s", variant.Name)
+			}
+		})
+	}
+}
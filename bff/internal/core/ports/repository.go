@@ -0,0 +1,24 @@
+package ports
+
+import (
+	"context"
+	"deeplink-bff/bff/internal/core/domain"
+	"time"
+)
+
+type ShortLinkRepository interface {
+	// Create stores a short link. It returns domain.ErrShortCodeTaken when the code is already in use.
+	Create(ctx context.Context, shortLink *domain.ShortLink) error
+	// GetByCode returns domain.ErrShortLinkNotFound when no short link uses the code
+	// or the short link has expired.
+	GetByCode(ctx context.Context, code string) (*domain.ShortLink, error)
+	// DeleteExpired removes short links that expired before now and returns how many were removed.
+	DeleteExpired(ctx context.Context, now time.Time) (int, error)
+}
+
+type ClickEventRepository interface {
+	Save(ctx context.Context, event *domain.ClickEvent) error
+	FindByDeeplink(ctx context.Context, deeplinkID string) ([]domain.ClickEvent, error)
+	// FindByPartner returns events in [from, to). A zero bound is open.
+	FindByPartner(ctx context.Context, partner string, from, to time.Time) ([]domain.ClickEvent, error)
+}
@@ -3,9 +3,25 @@ package ports
 import (
 	"context"
 	"deeplink-bff/bff/internal/adapters/handler/dto"
+	"deeplink-bff/bff/internal/core/domain"
 )
 
 type DeeplinkService interface {
 	GetDeeplinkList(ctx context.Context) (*dto.GetDeeplinkListResponse, error)
 	GetDeeplink(ctx context.Context, request *dto.GetDeeplinkRequest) (*dto.GetDeeplinkResponse, error)
+	GetDeeplinkQR(ctx context.Context, request *dto.GetDeeplinkQRRequest) (*dto.GetDeeplinkQRResponse, error)
+	ResolveDeeplink(ctx context.Context, request *dto.ResolveDeeplinkRequest) (*dto.ResolveDeeplinkResponse, error)
+}
+
+type ShortLinkService interface {
+	CreateShortLink(ctx context.Context, request *dto.CreateShortLinkRequest) (*dto.CreateShortLinkResponse, error)
+	ResolveShortLink(ctx context.Context, request *dto.ResolveShortLinkRequest) (*dto.ResolveDeeplinkResponse, error)
+}
+
+type AnalyticsService interface {
+	// Track records a click event. Failures are logged rather than returned so
+	// tracking never blocks a redirect.
+	Track(ctx context.Context, event *domain.ClickEvent)
+	GetDeeplinkStats(ctx context.Context, request *dto.GetDeeplinkStatsRequest) (*dto.GetDeeplinkStatsResponse, error)
+	GetPartnerStats(ctx context.Context, request *dto.GetPartnerStatsRequest) (*dto.GetPartnerStatsResponse, error)
 }
@@ -0,0 +1,186 @@
+package analytics_service
+
+import (
+	"context"
+	"deeplink-bff/bff/internal/adapters/handler/dto"
+	"deeplink-bff/bff/internal/core/domain"
+	"deeplink-bff/bff/internal/core/ports"
+	"deeplink-bff/constant"
+	"log/slog"
+	"net/http"
+	"time"
+)
+
+type analyticsService struct {
+	repository ports.ClickEventRepository
+	logger     *slog.Logger
+}
+
+func NewAnalyticsService(repository ports.ClickEventRepository, logger *slog.Logger) ports.AnalyticsService {
+	return &analyticsService{
+		repository,
+		logger,
+	}
+}
+
+func (a *analyticsService) Track(ctx context.Context, event *domain.ClickEvent) {
+	if err := a.repository.Save(ctx, event); err != nil {
+		a.logger.ErrorContext(ctx, "Failed to record click event",
+			slog.String("deeplink_id", event.DeeplinkID),
+			slog.Any("error", err),
+		)
+	}
+}
+
+func (a *analyticsService) GetDeeplinkStats(ctx context.Context, request *dto.GetDeeplinkStatsRequest) (*dto.GetDeeplinkStatsResponse, error) {
+	events, err := a.repository.FindByDeeplink(ctx, request.Id)
+	if err != nil {
+		return nil, err
+	}
+
+	stats := &dto.GetDeeplinkStatsResponse{
+		DeeplinkId: request.Id,
+		ByPlatform: map[string]dto.Funnel{},
+		BySource:   map[string]dto.Funnel{},
+		ByVariant:  map[string]dto.Funnel{},
+	}
+
+	var funnel funnelBuilder
+	byPlatform := map[string]*funnelBuilder{}
+	bySource := map[string]*funnelBuilder{}
+	byVariant := map[string]*funnelBuilder{}
+
+	for i := range events {
+		event := &events[i]
+		stats.Partner = event.Partner
+		stats.Product = event.Product
+
+		funnel.add(event)
+		builderFor(byPlatform, string(event.Platform)).add(event)
+		builderFor(bySource, string(event.Source)).add(event)
+		if event.Variant != "" {
+			builderFor(byVariant, event.Variant).add(event)
+		}
+	}
+
+	if len(events) > 0 {
+		first, last := events[0].Timestamp, events[len(events)-1].Timestamp
+		stats.FirstHitAt, stats.LastHitAt = &first, &last
+	}
+
+	stats.Funnel = funnel.build()
+	for platform, builder := range byPlatform {
+		stats.ByPlatform[platform] = builder.build()
+	}
+	for source, builder := range bySource {
+		stats.BySource[source] = builder.build()
+	}
+	for variant, builder := range byVariant {
+		stats.ByVariant[variant] = builder.build()
+	}
+
+	return stats, nil
+}
+
+func (a *analyticsService) GetPartnerStats(ctx context.Context, request *dto.GetPartnerStatsRequest) (*dto.GetPartnerStatsResponse, error) {
+	from, err := parseBound(request.From)
+	if err != nil {
+		return nil, err
+	}
+	to, err := parseBound(request.To)
+	if err != nil {
+		return nil, err
+	}
+
+	events, err := a.repository.FindByPartner(ctx, request.Partner, from, to)
+	if err != nil {
+		return nil, err
+	}
+
+	var funnel funnelBuilder
+	byProduct := map[string]*funnelBuilder{}
+	byCampaign := map[string]*funnelBuilder{}
+	byVariant := map[string]*funnelBuilder{}
+	for i := range events {
+		funnel.add(&events[i])
+		builderFor(byProduct, events[i].Product).add(&events[i])
+		if name := events[i].Campaign.Name; name != "" {
+			builderFor(byCampaign, name).add(&events[i])
+		}
+		if variant := events[i].Variant; variant != "" {
+			builderFor(byVariant, events[i].Product+"/"+variant).add(&events[i])
+		}
+	}
+
+	stats := &dto.GetPartnerStatsResponse{
+		Partner:    request.Partner,
+		Funnel:     funnel.build(),
+		ByProduct:  make(map[string]dto.Funnel, len(byProduct)),
+		ByCampaign: make(map[string]dto.Funnel, len(byCampaign)),
+		ByVariant:  make(map[string]dto.Funnel, len(byVariant)),
+	}
+	for product, builder := range byProduct {
+		stats.ByProduct[product] = builder.build()
+	}
+	for campaign, builder := range byCampaign {
+		stats.ByCampaign[campaign] = builder.build()
+	}
+	for variant, builder := range byVariant {
+		stats.ByVariant[variant] = builder.build()
+	}
+
+	return stats, nil
+}
+
+// funnelBuilder counts events per outcome.
+type funnelBuilder struct {
+	hits        int
+	appRedirect int
+	fallback    int
+	expired     int
+}
+
+func builderFor(builders map[string]*funnelBuilder, key string) *funnelBuilder {
+	builder, ok := builders[key]
+	if !ok {
+		builder = &funnelBuilder{}
+		builders[key] = builder
+	}
+	return builder
+}
+
+func (f *funnelBuilder) add(event *domain.ClickEvent) {
+	f.hits++
+	switch event.Outcome {
+	case domain.OutcomeAppRedirect:
+		f.appRedirect++
+	case domain.OutcomeFallback:
+		f.fallback++
+	case domain.OutcomeExpired:
+		f.expired++
+	}
+}
+
+func (f *funnelBuilder) build() dto.Funnel {
+	funnel := dto.Funnel{
+		Hits:        f.hits,
+		AppRedirect: f.appRedirect,
+		Fallback:    f.fallback,
+		Expired:     f.expired,
+	}
+	if f.hits > 0 {
+		funnel.AppRedirectRate = float64(f.appRedirect) / float64(f.hits)
+	}
+	return funnel
+}
+
+func parseBound(value string) (time.Time, error) {
+	if value == "" {
+		return time.Time{}, nil
+	}
+	t, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return time.Time{}, domain.NewError(constant.CodeInvalidCommonFields, http.StatusBadRequest, "from and to must be RFC 3339 timestamps")
+	}
+	return t, nil
+}
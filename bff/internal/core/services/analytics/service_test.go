@@ -0,0 +1,146 @@
+package analytics_service
+
+import (
+	"context"
+	"deeplink-bff/bff/internal/adapters/handler/dto"
+	"deeplink-bff/bff/internal/core/domain"
+	"errors"
+	"io"
+	"log/slog"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeRepository returns the events it was created with.
+type fakeRepository struct {
+	events []domain.ClickEvent
+	err    error
+}
+
+func (r *fakeRepository) Save(ctx context.Context, event *domain.ClickEvent) error {
+	if r.err != nil {
+		return r.err
+	}
+	r.events = append(r.events, *event)
+	return nil
+}
+
+func (r *fakeRepository) FindByDeeplink(ctx context.Context, deeplinkID string) ([]domain.ClickEvent, error) {
+	var events []domain.ClickEvent
+	for _, event := range r.events {
+		if event.DeeplinkID == deeplinkID {
+			events = append(events, event)
+		}
+	}
+	return events, nil
+}
+
+func (r *fakeRepository) FindByPartner(ctx context.Context, partner string, from, to time.Time) ([]domain.ClickEvent, error) {
+	var events []domain.ClickEvent
+	for _, event := range r.events {
+		if event.Partner == partner {
+			events = append(events, event)
+		}
+	}
+	return events, nil
+}
+
+var start = time.Date(2025, 7, 1, 12, 0, 0, 0, time.UTC)
+
+func clickEvents() []domain.ClickEvent {
+	event := func(minute int, platform domain.Platform, source domain.Source, variant, campaign string, outcome domain.Outcome) domain.ClickEvent {
+		return domain.ClickEvent{
+			DeeplinkID: "dl-1",
+			Partner:    "acme",
+			Product:    "loan",
+			Source:     source,
+			Platform:   platform,
+			Campaign:   domain.Campaign{Name: campaign},
+			Variant:    variant,
+			Outcome:    outcome,
+			Timestamp:  start.Add(time.Duration(minute) * time.Minute),
+		}
+	}
+	return []domain.ClickEvent{
+		event(0, domain.PlatformIOS, domain.SourceResolve, "a", "summer", domain.OutcomeAppRedirect),
+		event(1, domain.PlatformAndroid, domain.SourceShortLink, "b", "summer", domain.OutcomeAppRedirect),
+		event(2, domain.PlatformDesktop, domain.SourceResolve, "a", "", domain.OutcomeFallback),
+		event(3, domain.PlatformIOS, domain.SourceResolve, "", "", domain.OutcomeExpired),
+	}
+}
+
+func newTestService(events []domain.ClickEvent) *analyticsService {
+	return &analyticsService{
+		repository: &fakeRepository{events: events},
+		logger:     slog.New(slog.NewTextHandler(io.Discard, nil)),
+	}
+}
+
+func TestGetDeeplinkStats(t *testing.T) {
+	service := newTestService(clickEvents())
+
+	stats, err := service.GetDeeplinkStats(context.Background(), &dto.GetDeeplinkStatsRequest{Id: "dl-1"})
+	require.NoError(t, err)
+
+	assert.Equal(t, "acme", stats.Partner)
+	assert.Equal(t, "loan", stats.Product)
+	assert.Equal(t, dto.Funnel{Hits: 4, AppRedirect: 2, Fallback: 1, Expired: 1, AppRedirectRate: 0.5}, stats.Funnel)
+	assert.Equal(t, map[string]dto.Funnel{
+		"ios":     {Hits: 2, AppRedirect: 1, Expired: 1, AppRedirectRate: 0.5},
+		"android": {Hits: 1, AppRedirect: 1, AppRedirectRate: 1},
+		"desktop": {Hits: 1, Fallback: 1},
+	}, stats.ByPlatform)
+	assert.Equal(t, map[string]dto.Funnel{
+		"resolve":    {Hits: 3, AppRedirect: 1, Fallback: 1, Expired: 1, AppRedirectRate: 1.0 / 3},
+		"short_link": {Hits: 1, AppRedirect: 1, AppRedirectRate: 1},
+	}, stats.BySource)
+	assert.Equal(t, map[string]dto.Funnel{
+		"a": {Hits: 2, AppRedirect: 1, Fallback: 1, AppRedirectRate: 0.5},
+		"b": {Hits: 1, AppRedirect: 1, AppRedirectRate: 1},
+	}, stats.ByVariant, "hits without a variant are not grouped")
+	require.NotNil(t, stats.FirstHitAt)
+	require.NotNil(t, stats.LastHitAt)
+	assert.Equal(t, start, *stats.FirstHitAt)
+	assert.Equal(t, start.Add(3*time.Minute), *stats.LastHitAt)
+}
+
+func TestGetDeeplinkStatsWithoutHits(t *testing.T) {
+	service := newTestService(nil)
+
+	stats, err := service.GetDeeplinkStats(context.Background(), &dto.GetDeeplinkStatsRequest{Id: "dl-1"})
+	require.NoError(t, err)
+	assert.Equal(t, dto.Funnel{}, stats.Funnel, "no rate without hits")
+	assert.Empty(t, stats.ByPlatform)
+	assert.Nil(t, stats.FirstHitAt)
+}
+
+func TestGetPartnerStats(t *testing.T) {
+	service := newTestService(clickEvents())
+
+	stats, err := service.GetPartnerStats(context.Background(), &dto.GetPartnerStatsRequest{Partner: "acme"})
+	require.NoError(t, err)
+
+	assert.Equal(t, dto.Funnel{Hits: 4, AppRedirect: 2, Fallback: 1, Expired: 1, AppRedirectRate: 0.5}, stats.Funnel)
+	assert.Equal(t, map[string]dto.Funnel{
+		"loan": {Hits: 4, AppRedirect: 2, Fallback: 1, Expired: 1, AppRedirectRate: 0.5},
+	}, stats.ByProduct)
+	assert.Equal(t, map[string]dto.Funnel{
+		"summer": {Hits: 2, AppRedirect: 2, AppRedirectRate: 1},
+	}, stats.ByCampaign)
+	assert.Equal(t, map[string]dto.Funnel{
+		"loan/a": {Hits: 2, AppRedirect: 1, Fallback: 1, AppRedirectRate: 0.5},
+		"loan/b": {Hits: 1, AppRedirect: 1, AppRedirectRate: 1},
+	}, stats.ByVariant)
+}
+
+func TestGetPartnerStatsInvalidBound(t *testing.T) {
+	service := newTestService(clickEvents())
+
+	_, err := service.GetPartnerStats(context.Background(), &dto.GetPartnerStatsRequest{Partner: "acme", From: "yesterday"})
+	var domainErr *domain.Error
+	require.True(t, errors.As(err, &domainErr))
+	assert.Equal(t, 400, domainErr.Status)
+}
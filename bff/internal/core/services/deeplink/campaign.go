@@ -0,0 +1,41 @@
+package deeplink_service
+
+import (
+	"context"
+	"deeplink-bff/bff/internal/adapters/handler/dto"
+	"deeplink-bff/bff/internal/core/domain"
+	"log/slog"
+)
+
+// campaignOf returns the campaign carried by the deeplink.
+func campaignOf(deeplink *dto.GetDeeplinkResponse) domain.Campaign {
+	if deeplink.Campaign == nil {
+		return domain.Campaign{}
+	}
+	return domain.Campaign{
+		Source:  deeplink.Campaign.Source,
+		Medium:  deeplink.Campaign.Medium,
+		Name:    deeplink.Campaign.Name,
+		Term:    deeplink.Campaign.Term,
+		Content: deeplink.Campaign.Content,
+		Params:  deeplink.Campaign.Params,
+	}
+}
+
+// applyCampaign merges the deeplink campaign into its partner success and
+// fail URLs. A URL that cannot be parsed is left unchanged.
+func applyCampaign(ctx context.Context, logger *slog.Logger, deeplink *dto.GetDeeplinkResponse) {
+	params := campaignOf(deeplink).Values()
+	if len(params) == 0 {
+		return
+	}
+
+	for _, target := range []*string{&deeplink.PartnerDeeplink.Success, &deeplink.PartnerDeeplink.Fail} {
+		merged, err := domain.MergeQuery(*target, params)
+		if err != nil {
+			logger.WarnContext(ctx, "Failed to add campaign to partner deeplink", slog.Any("error", err))
+			continue
+		}
+		*target = merged
+	}
+}
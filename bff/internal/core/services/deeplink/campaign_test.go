@@ -0,0 +1,79 @@
+package deeplink_service
+
+import (
+	"bytes"
+	"context"
+	"deeplink-bff/bff/internal/adapters/handler/dto"
+	"log/slog"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestApplyCampaign(t *testing.T) {
+	tests := []struct {
+		name        string
+		campaign    *dto.Campaign
+		success     string
+		fail        string
+		wantSuccess string
+		wantFail    string
+		wantWarning bool
+	}{
+		{
+			name:        "no campaign",
+			success:     "https://partner.example.com/ok?ref=1",
+			fail:        "https://partner.example.com/fail",
+			wantSuccess: "https://partner.example.com/ok?ref=1",
+			wantFail:    "https://partner.example.com/fail",
+		},
+		{
+			name:        "empty campaign",
+			campaign:    &dto.Campaign{Params: map[string]string{"ref": ""}},
+			success:     "https://partner.example.com/ok?ref=1",
+			fail:        "https://partner.example.com/fail",
+			wantSuccess: "https://partner.example.com/ok?ref=1",
+			wantFail:    "https://partner.example.com/fail",
+		},
+		{
+			name:        "merged into both URLs",
+			campaign:    &dto.Campaign{Source: "sms", Name: "summer sale", Params: map[string]string{"branch": "042"}},
+			success:     "https://partner.example.com/ok?utm_source=partner",
+			fail:        "https://partner.example.com/fail",
+			wantSuccess: "https://partner.example.com/ok?utm_source=partner&branch=042&utm_campaign=summer+sale",
+			wantFail:    "https://partner.example.com/fail?branch=042&utm_campaign=summer+sale&utm_source=sms",
+		},
+		{
+			name:        "invalid URL left unchanged",
+			campaign:    &dto.Campaign{Source: "sms"},
+			success:     "https://partner.example.com/%zz",
+			fail:        "https://partner.example.com/fail",
+			wantSuccess: "https://partner.example.com/%zz",
+			wantFail:    "https://partner.example.com/fail?utm_source=sms",
+			wantWarning: true,
+		},
+		{
+			name:        "missing URL left empty",
+			campaign:    &dto.Campaign{Source: "sms"},
+			success:     "https://partner.example.com/ok",
+			wantSuccess: "https://partner.example.com/ok?utm_source=sms",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			buf := &bytes.Buffer{}
+			logger := slog.New(slog.NewTextHandler(buf, nil))
+			deeplink := &dto.GetDeeplinkResponse{
+				Campaign:        tt.campaign,
+				PartnerDeeplink: dto.PartnerDeeplink{Success: tt.success, Fail: tt.fail},
+			}
+
+			applyCampaign(context.Background(), logger, deeplink)
+
+			assert.Equal(t, tt.wantSuccess, deeplink.PartnerDeeplink.Success)
+			assert.Equal(t, tt.wantFail, deeplink.PartnerDeeplink.Fail)
+			assert.Equal(t, tt.wantWarning, bytes.Contains(buf.Bytes(), []byte("level=WARN")))
+		})
+	}
+}
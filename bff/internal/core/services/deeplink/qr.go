@@ -0,0 +1,67 @@
+package deeplink_service
+
+import (
+	"context"
+	"deeplink-bff/bff/internal/adapters/handler/dto"
+	"deeplink-bff/bff/internal/core/domain"
+	"deeplink-bff/constant"
+	"deeplink-bff/pkg/qr"
+	"log/slog"
+	"net/http"
+	"time"
+)
+
+func (d *deeplinkService) GetDeeplinkQR(ctx context.Context, request *dto.GetDeeplinkQRRequest) (*dto.GetDeeplinkQRResponse, error) {
+	opts, err := qrOptions(request)
+	if err != nil {
+		return nil, domain.NewError(constant.CodeInvalidCommonFields, http.StatusBadRequest, err.Error())
+	}
+
+	deeplink, err := d.deeplinkClient.GetDeeplink(ctx, request.Id)
+	if err != nil {
+		d.logger.ErrorContext(ctx, "Calling GetDeeplink in client failed", slog.Any("error", err))
+		return nil, err
+	}
+
+	if domain.IsExpired(deeplink.TxnSessionValidUntil, time.Now()) {
+		return nil, domain.ErrDeeplinkExpired
+	}
+
+	image, err := qr.Render(domain.ResolveURL(d.config.ResolveBaseURL, request.Id), opts)
+	if err != nil {
+		return nil, err
+	}
+
+	return &dto.GetDeeplinkQRResponse{
+		ContentType: opts.Format.ContentType(),
+		Image:       image,
+	}, nil
+}
+
+// qrOptions merges the request query with the default render options.
+func qrOptions(request *dto.GetDeeplinkQRRequest) (qr.Options, error) {
+	opts := qr.DefaultOptions()
+
+	if request.Format != "" {
+		format, err := qr.ParseFormat(request.Format)
+		if err != nil {
+			return opts, err
+		}
+		opts.Format = format
+	}
+	if request.Level != "" {
+		level, err := qr.ParseLevel(request.Level)
+		if err != nil {
+			return opts, err
+		}
+		opts.Level = level
+	}
+	if request.Size != 0 {
+		opts.Size = request.Size
+	}
+	if request.QuietZone != nil {
+		opts.QuietZone = *request.QuietZone
+	}
+
+	return opts, opts.Validate()
+}
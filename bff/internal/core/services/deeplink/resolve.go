@@ -0,0 +1,79 @@
+package deeplink_service
+
+import (
+	"context"
+	"deeplink-bff/bff/internal/adapters/handler/dto"
+	"deeplink-bff/bff/internal/core/domain"
+	"log/slog"
+	"strings"
+	"time"
+)
+
+func (d *deeplinkService) ResolveDeeplink(ctx context.Context, request *dto.ResolveDeeplinkRequest) (*dto.ResolveDeeplinkResponse, error) {
+	deeplink, err := d.deeplinkClient.GetDeeplink(ctx, request.Id)
+	if err != nil {
+		d.logger.ErrorContext(ctx, "Calling GetDeeplink in client failed", slog.Any("error", err))
+		return nil, err
+	}
+
+	now := time.Now()
+	event := newClickEvent(request, deeplink, now)
+
+	// expired hits are not attributed to a variant; the destination was never chosen
+	if domain.IsExpired(deeplink.TxnSessionValidUntil, now) {
+		event.Outcome = domain.OutcomeExpired
+		d.analyticsService.Track(ctx, event)
+		return nil, domain.ErrDeeplinkExpired
+	}
+
+	appBaseURL, fallbackURL := d.config.AppBaseURL, d.config.FallbackURL
+	if variant, ok := d.assignVariant(deeplink, request.Id); ok {
+		event.Variant = variant.Name
+		if variant.AppBaseURL != "" {
+			appBaseURL = variant.AppBaseURL
+		}
+		if variant.FallbackURL != "" {
+			fallbackURL = variant.FallbackURL
+		}
+	}
+
+	redirectURL := fallbackURL
+	event.Outcome = domain.OutcomeFallback
+	if event.Platform.IsMobile() {
+		redirectURL = domain.ResolveURL(appBaseURL, request.Id)
+		event.Outcome = domain.OutcomeAppRedirect
+	}
+	d.analyticsService.Track(ctx, event)
+
+	// forward the attribution so the app or landing page can report it too
+	if merged, err := domain.MergeQuery(redirectURL, event.Campaign.Values()); err == nil {
+		redirectURL = merged
+	} else {
+		d.logger.WarnContext(ctx, "Failed to add campaign to redirect URL", slog.Any("error", err))
+	}
+
+	return &dto.ResolveDeeplinkResponse{
+		RedirectURL: redirectURL,
+	}, nil
+}
+
+// newClickEvent builds the analytics event for a resolve request. Request
+// values may alias fiber's reusable buffers, so everything kept is copied.
+func newClickEvent(request *dto.ResolveDeeplinkRequest, deeplink *dto.GetDeeplinkResponse, now time.Time) *domain.ClickEvent {
+	source := domain.SourceResolve
+	if request.ShortCode != "" {
+		source = domain.SourceShortLink
+	}
+
+	return &domain.ClickEvent{
+		DeeplinkID: strings.Clone(request.Id),
+		Partner:    deeplink.PartnerCode,
+		Product:    deeplink.ProductCode,
+		Source:     source,
+		Platform:   domain.DetectPlatform(request.Client.UserAgent),
+		Referrer:   domain.ReferrerOrigin(request.Client.Referrer),
+		IPPrefix:   domain.IPPrefix(request.Client.IP),
+		Campaign:   campaignOf(deeplink),
+		Timestamp:  now,
+	}
+}
@@ -3,18 +3,36 @@ package deeplink_service
 import (
 	"context"
 	"deeplink-bff/bff/internal/adapters/handler/dto"
+	"deeplink-bff/bff/internal/core/domain"
 	"deeplink-bff/bff/internal/core/ports"
-	"errors"
 	"log/slog"
 )
 
+// Config configures how deeplinks are shared and resolved.
+type Config struct {
+	// ResolveBaseURL is the public URL a deeplink id is appended to.
+	ResolveBaseURL string
+	// AppBaseURL is the app URL mobile clients are redirected to, e.g. myapp://deeplink
+	AppBaseURL string
+	// FallbackURL is where clients that cannot open the app are redirected to.
+	FallbackURL string
+	// Experiments route products to weighted destination variants.
+	Experiments []domain.Experiment
+}
+
 type deeplinkService struct {
-	deeplinkClient ports.DeeplinkClient
+	deeplinkClient   ports.DeeplinkClient
+	analyticsService ports.AnalyticsService
+	config           Config
+	logger           *slog.Logger
 }
 
-func NewDeeplinkService(deeplinkClient ports.DeeplinkClient) ports.DeeplinkService {
+func NewDeeplinkService(deeplinkClient ports.DeeplinkClient, analyticsService ports.AnalyticsService, config Config, logger *slog.Logger) ports.DeeplinkService {
 	return &deeplinkService{
 		deeplinkClient,
+		analyticsService,
+		config,
+		logger,
 	}
 }
 
@@ -30,7 +48,7 @@ func (d *deeplinkService) GetDeeplinkList(ctx context.Context) (*dto.GetDeeplink
 					}
 			}
 	}`
-	slog.InfoContext(ctx, "Calling GetDeeplinkList in service", slog.Any("user", jsonData))
+	d.logger.InfoContext(ctx, "Calling GetDeeplinkList in service", slog.Any("user", jsonData))
 
 	// deeplinks, err := d.deeplinkClient.GetDeeplinkList(ctx)
 
@@ -49,13 +67,18 @@ func (d *deeplinkService) GetDeeplinkList(ctx context.Context) (*dto.GetDeeplink
 
 func (d *deeplinkService) GetDeeplink(ctx context.Context, request *dto.GetDeeplinkRequest) (*dto.GetDeeplinkResponse, error) {
 
-	slog.InfoContext(ctx, "Calling GetDeeplink in service", slog.Any("deeplink", "testinfo4"))
-	// deeplink, err := d.deeplinkClient.GetDeeplink(ctx, request.Id)
-	slog.ErrorContext(ctx, "Calling GetDeeplink in service failed", slog.Any("error", "testerror4"))
+	d.logger.InfoContext(ctx, "Calling GetDeeplink in service", slog.String("deeplink", request.Id))
 
-	// return deeplink, err
+	deeplink, err := d.deeplinkClient.GetDeeplink(ctx, request.Id)
+	if err != nil {
+		d.logger.ErrorContext(ctx, "Calling GetDeeplink in service failed", slog.Any("error", err))
+		return nil, err
+	}
 
-	err := errors.New("unexpect error")
+	applyCampaign(ctx, d.logger, deeplink)
+	if variant, ok := d.assignVariant(deeplink, request.Id); ok {
+		deeplink.Variant = variant.Name
+	}
 
-	return nil, err
+	return deeplink, nil
 }
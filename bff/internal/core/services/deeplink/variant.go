@@ -0,0 +1,17 @@
+package deeplink_service
+
+import (
+	"deeplink-bff/bff/internal/adapters/handler/dto"
+	"deeplink-bff/bff/internal/core/domain"
+)
+
+// assignVariant returns the destination variant of the first experiment that
+// applies to the deeplink.
+func (d *deeplinkService) assignVariant(deeplink *dto.GetDeeplinkResponse, id string) (domain.Variant, bool) {
+	for _, experiment := range d.config.Experiments {
+		if experiment.Matches(deeplink.ProductCode, deeplink.ChannelDestination) {
+			return experiment.Assign(id), true
+		}
+	}
+	return domain.Variant{}, false
+}
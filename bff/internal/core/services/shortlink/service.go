@@ -0,0 +1,180 @@
+package shortlink_service
+
+import (
+	"context"
+	"crypto/rand"
+	"deeplink-bff/bff/internal/adapters/handler/dto"
+	"deeplink-bff/bff/internal/core/domain"
+	"deeplink-bff/bff/internal/core/ports"
+	"deeplink-bff/constant"
+	"errors"
+	"fmt"
+	"log/slog"
+	"math/big"
+	"net/http"
+	"regexp"
+	"strings"
+	"time"
+)
+
+const (
+	// base62 keeps codes URL-safe without escaping
+	codeAlphabet = "0123456789ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz"
+
+	DefaultCodeLength   = 7
+	maxGenerateAttempts = 8
+
+	minAliasLength = 4
+	maxAliasLength = 32
+)
+
+var aliasPattern = regexp.MustCompile(`^[A-Za-z0-9_-]+$`)
+
+// Config configures short link generation.
+type Config struct {
+	// BaseURL is the public URL short codes are appended to, e.g. https://dl.example.com/s
+	BaseURL string
+	// CodeLength is the length of generated codes. Defaults to DefaultCodeLength.
+	CodeLength int
+	// AliasPrefixes maps a partner code to the prefix its custom aliases must start with.
+	// Partners that are not listed cannot create custom aliases.
+	AliasPrefixes map[string]string
+}
+
+type shortLinkService struct {
+	deeplinkClient  ports.DeeplinkClient
+	deeplinkService ports.DeeplinkService
+	repository      ports.ShortLinkRepository
+	config          Config
+	logger          *slog.Logger
+}
+
+func NewShortLinkService(deeplinkClient ports.DeeplinkClient, deeplinkService ports.DeeplinkService, repository ports.ShortLinkRepository, config Config, logger *slog.Logger) ports.ShortLinkService {
+	if config.CodeLength <= 0 {
+		config.CodeLength = DefaultCodeLength
+	}
+	return &shortLinkService{
+		deeplinkClient,
+		deeplinkService,
+		repository,
+		config,
+		logger,
+	}
+}
+
+func (s *shortLinkService) CreateShortLink(ctx context.Context, request *dto.CreateShortLinkRequest) (*dto.CreateShortLinkResponse, error) {
+	deeplink, err := s.deeplinkClient.GetDeeplink(ctx, request.DeeplinkId)
+	if err != nil {
+		s.logger.ErrorContext(ctx, "Calling GetDeeplink in client failed", slog.Any("error", err))
+		return nil, err
+	}
+
+	now := time.Now()
+	if domain.IsExpired(deeplink.TxnSessionValidUntil, now) {
+		return nil, domain.ErrDeeplinkExpired
+	}
+
+	// request values may alias fiber's reusable buffers, so copy what outlives the request
+	shortLink := &domain.ShortLink{
+		DeeplinkID: strings.Clone(request.DeeplinkId),
+		Partner:    deeplink.PartnerCode,
+		CreatedAt:  now,
+		ExpiresAt:  deeplink.TxnSessionValidUntil,
+	}
+
+	if request.Alias != "" {
+		if err := s.validateAlias(deeplink.PartnerCode, request.Alias); err != nil {
+			return nil, err
+		}
+		shortLink.Code = strings.Clone(request.Alias)
+		shortLink.Alias = true
+		if err := s.repository.Create(ctx, shortLink); err != nil {
+			return nil, err
+		}
+	} else if err := s.createWithGeneratedCode(ctx, shortLink); err != nil {
+		return nil, err
+	}
+
+	s.logger.InfoContext(ctx, "Short link created",
+		slog.String("code", shortLink.Code),
+		slog.String("deeplink_id", shortLink.DeeplinkID),
+		slog.Bool("alias", shortLink.Alias),
+	)
+
+	return &dto.CreateShortLinkResponse{
+		Code:      shortLink.Code,
+		ShortURL:  domain.ResolveURL(s.config.BaseURL, shortLink.Code),
+		ExpiresAt: shortLink.ExpiresAt,
+	}, nil
+}
+
+// ResolveShortLink resolves the deeplink bound to the code directly, so the
+// client is redirected once and the hit is tracked as a short link hit.
+// Expired short links are not found, like deleted ones.
+func (s *shortLinkService) ResolveShortLink(ctx context.Context, request *dto.ResolveShortLinkRequest) (*dto.ResolveDeeplinkResponse, error) {
+	shortLink, err := s.repository.GetByCode(ctx, request.Code)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.deeplinkService.ResolveDeeplink(ctx, &dto.ResolveDeeplinkRequest{
+		Id:        shortLink.DeeplinkID,
+		ShortCode: shortLink.Code,
+		Client:    request.Client,
+	})
+}
+
+// createWithGeneratedCode stores shortLink under a random code, retrying with
+// a fresh code whenever the repository reports a collision.
+func (s *shortLinkService) createWithGeneratedCode(ctx context.Context, shortLink *domain.ShortLink) error {
+	for attempt := 0; attempt < maxGenerateAttempts; attempt++ {
+		code, err := generateCode(s.config.CodeLength)
+		if err != nil {
+			return err
+		}
+
+		shortLink.Code = code
+		err = s.repository.Create(ctx, shortLink)
+		if !errors.Is(err, domain.ErrShortCodeTaken) {
+			return err
+		}
+	}
+
+	return domain.Internal(fmt.Errorf("failed to generate a unique short code after %d attempts", maxGenerateAttempts))
+}
+
+// validateAlias checks a custom alias against the rules configured for partner.
+func (s *shortLinkService) validateAlias(partner, alias string) error {
+	prefix, ok := s.config.AliasPrefixes[partner]
+	if !ok {
+		return invalidAlias("custom aliases are not enabled for this partner")
+	}
+	if len(alias) < minAliasLength || len(alias) > maxAliasLength {
+		return invalidAlias(fmt.Sprintf("alias must be between %d and %d characters", minAliasLength, maxAliasLength))
+	}
+	if !aliasPattern.MatchString(alias) {
+		return invalidAlias("alias may only contain letters, digits, '-' and '_'")
+	}
+	if !strings.HasPrefix(alias, prefix) {
+		return invalidAlias(fmt.Sprintf("alias must start with %q", prefix))
+	}
+	return nil
+}
+
+func invalidAlias(message string) error {
+	return domain.NewError(constant.CodeInvalidCommonFields, http.StatusBadRequest, message)
+}
+
+// generateCode returns a random base62 code of the given length.
+func generateCode(length int) (string, error) {
+	max := big.NewInt(int64(len(codeAlphabet)))
+	code := make([]byte, length)
+	for i := range code {
+		n, err := rand.Int(rand.Reader, max)
+		if err != nil {
+			return "", domain.Internal(fmt.Errorf("failed to generate short code: %w", err))
+		}
+		code[i] = codeAlphabet[n.Int64()]
+	}
+	return string(code), nil
+}
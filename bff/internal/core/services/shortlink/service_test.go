@@ -0,0 +1,288 @@
+package shortlink_service
+
+import (
+	"context"
+	"deeplink-bff/bff/internal/adapters/handler/dto"
+	"deeplink-bff/bff/internal/core/domain"
+	"errors"
+	"io"
+	"log/slog"
+	"net/http"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeClient returns the deeplink it was created with.
+type fakeClient struct {
+	deeplink *dto.GetDeeplinkResponse
+}
+
+func (c *fakeClient) GetDeeplinkList(ctx context.Context) (*dto.GetDeeplinkListResponse, error) {
+	return nil, errors.New("not implemented")
+}
+
+func (c *fakeClient) GetDeeplink(ctx context.Context, id string) (*dto.GetDeeplinkResponse, error) {
+	if c.deeplink == nil {
+		return nil, domain.ErrDeeplinkNotFound
+	}
+	return c.deeplink, nil
+}
+
+// fakeDeeplinkService records the resolve requests it receives.
+type fakeDeeplinkService struct {
+	resolved []*dto.ResolveDeeplinkRequest
+}
+
+func (s *fakeDeeplinkService) GetDeeplinkList(ctx context.Context) (*dto.GetDeeplinkListResponse, error) {
+	return nil, errors.New("not implemented")
+}
+
+func (s *fakeDeeplinkService) GetDeeplink(ctx context.Context, request *dto.GetDeeplinkRequest) (*dto.GetDeeplinkResponse, error) {
+	return nil, errors.New("not implemented")
+}
+
+func (s *fakeDeeplinkService) GetDeeplinkQR(ctx context.Context, request *dto.GetDeeplinkQRRequest) (*dto.GetDeeplinkQRResponse, error) {
+	return nil, errors.New("not implemented")
+}
+
+func (s *fakeDeeplinkService) ResolveDeeplink(ctx context.Context, request *dto.ResolveDeeplinkRequest) (*dto.ResolveDeeplinkResponse, error) {
+	s.resolved = append(s.resolved, request)
+	return &dto.ResolveDeeplinkResponse{RedirectURL: "https://example.com/" + request.Id}, nil
+}
+
+// fakeRepository reports the first taken codes as collisions and fails every
+// create with err when it is set.
+type fakeRepository struct {
+	links    map[string]domain.ShortLink
+	taken    int
+	err      error
+	attempts int
+}
+
+func newFakeRepository() *fakeRepository {
+	return &fakeRepository{links: make(map[string]domain.ShortLink)}
+}
+
+func (r *fakeRepository) Create(ctx context.Context, shortLink *domain.ShortLink) error {
+	r.attempts++
+	if r.err != nil {
+		return r.err
+	}
+	if _, ok := r.links[shortLink.Code]; ok || r.attempts <= r.taken {
+		return domain.ErrShortCodeTaken
+	}
+	r.links[shortLink.Code] = *shortLink
+	return nil
+}
+
+func (r *fakeRepository) GetByCode(ctx context.Context, code string) (*domain.ShortLink, error) {
+	shortLink, ok := r.links[code]
+	if !ok {
+		return nil, domain.ErrShortLinkNotFound
+	}
+	return &shortLink, nil
+}
+
+func (r *fakeRepository) DeleteExpired(ctx context.Context, now time.Time) (int, error) {
+	return 0, nil
+}
+
+var base62 = regexp.MustCompile(`^[0-9A-Za-z]+$`)
+
+func newTestService(deeplink *dto.GetDeeplinkResponse, repository *fakeRepository, deeplinkService *fakeDeeplinkService) *shortLinkService {
+	config := Config{
+		BaseURL:       "https://dl.example.com/s/",
+		AliasPrefixes: map[string]string{"acme": "acme-", "initech": ""},
+	}
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	return NewShortLinkService(&fakeClient{deeplink}, deeplinkService, repository, config, logger).(*shortLinkService)
+}
+
+func TestValidateAlias(t *testing.T) {
+	tests := []struct {
+		name    string
+		partner string
+		alias   string
+		wantErr string
+	}{
+		{"valid", "acme", "acme-summer_25", ""},
+		{"shortest", "initech", "abcd", ""},
+		{"longest", "acme", "acme-" + strings.Repeat("x", maxAliasLength-5), ""},
+		{"partner without aliases", "globex", "acme-summer", "not enabled"},
+		{"too short", "initech", "abc", "between"},
+		{"too long", "acme", "acme-" + strings.Repeat("x", maxAliasLength-4), "between"},
+		{"space", "acme", "acme-summer sale", "may only contain"},
+		{"slash", "acme", "acme-summer/sale", "may only contain"},
+		{"non ascii", "acme", "acme-été", "may only contain"},
+		{"missing prefix", "acme", "summer-sale", `start with "acme-"`},
+	}
+
+	service := newTestService(nil, newFakeRepository(), &fakeDeeplinkService{})
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := service.validateAlias(tt.partner, tt.alias)
+			if tt.wantErr == "" {
+				assert.NoError(t, err)
+				return
+			}
+			var domainErr *domain.Error
+			require.ErrorAs(t, err, &domainErr)
+			assert.Equal(t, 400, domainErr.Status)
+			assert.Contains(t, domainErr.Message, tt.wantErr)
+		})
+	}
+}
+
+func TestGenerateCode(t *testing.T) {
+	for _, length := range []int{1, DefaultCodeLength, 16} {
+		seen := make(map[string]struct{})
+		for i := 0; i < 100; i++ {
+			code, err := generateCode(length)
+			require.NoError(t, err)
+			assert.Len(t, code, length)
+			assert.Regexp(t, base62, code)
+			seen[code] = struct{}{}
+		}
+		if length >= DefaultCodeLength {
+			assert.Len(t, seen, 100, "codes of length %d should not repeat", length)
+		}
+	}
+}
+
+func TestCreateWithGeneratedCode(t *testing.T) {
+	failure := errors.New("store unavailable")
+
+	tests := []struct {
+		name         string
+		taken        int
+		err          error
+		wantAttempts int
+		wantErr      error
+	}{
+		{"first code free", 0, nil, 1, nil},
+		{"retries collisions", 3, nil, 4, nil},
+		{"last attempt free", maxGenerateAttempts - 1, nil, maxGenerateAttempts, nil},
+		{"gives up", maxGenerateAttempts, nil, maxGenerateAttempts, domain.ErrInternal},
+		{"repository failure is not retried", 0, failure, 1, failure},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repository := newFakeRepository()
+			repository.taken = tt.taken
+			repository.err = tt.err
+			service := newTestService(nil, repository, &fakeDeeplinkService{})
+
+			shortLink := &domain.ShortLink{DeeplinkID: "dl-1"}
+			err := service.createWithGeneratedCode(context.Background(), shortLink)
+
+			assert.Equal(t, tt.wantAttempts, repository.attempts)
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+				assert.Empty(t, repository.links)
+				return
+			}
+			require.NoError(t, err)
+			assert.Len(t, shortLink.Code, DefaultCodeLength)
+			assert.Contains(t, repository.links, shortLink.Code)
+		})
+	}
+}
+
+func TestCreateShortLink(t *testing.T) {
+	validUntil := time.Now().Add(time.Hour)
+	active := &dto.GetDeeplinkResponse{PartnerCode: "acme", TxnSessionValidUntil: validUntil}
+	expired := &dto.GetDeeplinkResponse{PartnerCode: "acme", TxnSessionValidUntil: time.Now().Add(-time.Minute)}
+
+	tests := []struct {
+		name       string
+		deeplink   *dto.GetDeeplinkResponse
+		existing   string
+		alias      string
+		wantStatus int
+	}{
+		{"generated code", active, "", "", 0},
+		{"alias", active, "", "acme-promo", 0},
+		{"duplicate alias", active, "acme-promo", "acme-promo", http.StatusConflict},
+		{"invalid alias", active, "", "promo", http.StatusBadRequest},
+		{"unknown deeplink", nil, "", "", http.StatusNotFound},
+		{"expired deeplink", expired, "", "", http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repository := newFakeRepository()
+			if tt.existing != "" {
+				repository.links[tt.existing] = domain.ShortLink{Code: tt.existing, DeeplinkID: "dl-0"}
+			}
+			service := newTestService(tt.deeplink, repository, &fakeDeeplinkService{})
+
+			resp, err := service.CreateShortLink(context.Background(), &dto.CreateShortLinkRequest{DeeplinkId: "dl-1", Alias: tt.alias})
+			if tt.wantStatus != 0 {
+				var domainErr *domain.Error
+				require.ErrorAs(t, err, &domainErr)
+				assert.Equal(t, tt.wantStatus, domainErr.Status)
+				for _, stored := range repository.links {
+					assert.NotEqual(t, "dl-1", stored.DeeplinkID)
+				}
+				return
+			}
+			require.NoError(t, err)
+
+			if tt.alias != "" {
+				assert.Equal(t, tt.alias, resp.Code)
+			} else {
+				assert.Len(t, resp.Code, DefaultCodeLength)
+				assert.Regexp(t, base62, resp.Code)
+			}
+			assert.Equal(t, "https://dl.example.com/s/"+resp.Code, resp.ShortURL)
+			assert.Equal(t, validUntil, resp.ExpiresAt)
+
+			stored := repository.links[resp.Code]
+			assert.Equal(t, "dl-1", stored.DeeplinkID)
+			assert.Equal(t, "acme", stored.Partner)
+			assert.Equal(t, tt.alias != "", stored.Alias)
+			assert.Equal(t, validUntil, stored.ExpiresAt)
+		})
+	}
+}
+
+func TestResolveShortLink(t *testing.T) {
+	client := dto.ClientInfo{UserAgent: "test", IP: "192.0.2.1"}
+
+	tests := []struct {
+		name    string
+		code    string
+		wantErr error
+	}{
+		{"known code", "abc1234", nil},
+		{"unknown code", "zzz9999", domain.ErrShortLinkNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repository := newFakeRepository()
+			repository.links["abc1234"] = domain.ShortLink{Code: "abc1234", DeeplinkID: "dl-1"}
+			deeplinkService := &fakeDeeplinkService{}
+			service := newTestService(nil, repository, deeplinkService)
+
+			resp, err := service.ResolveShortLink(context.Background(), &dto.ResolveShortLinkRequest{Code: tt.code, Client: client})
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+				assert.Empty(t, deeplinkService.resolved)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, "https://example.com/dl-1", resp.RedirectURL)
+
+			// the hit is resolved as the bound deeplink and tracked as a short link hit
+			require.Len(t, deeplinkService.resolved, 1)
+			assert.Equal(t, &dto.ResolveDeeplinkRequest{Id: "dl-1", ShortCode: "abc1234", Client: client}, deeplinkService.resolved[0])
+		})
+	}
+}
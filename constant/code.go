@@ -9,10 +9,12 @@ func (c Code) String() string {
 var (
 	CodeSuccess                    Code = "DL0000"
 	CodeInvalidCommonFields        Code = "DL4000"
+	CodeUnauthorized               Code = "DL4010"
 	CodePartnerConfigNotExist      Code = "DL4091"
 	CodeInvalidDynamicFields       Code = "DL4092"
 	CodeDuplicatePartnerTxnRef     Code = "DL4093"
 	CodeSessionValidUntilTooOld    Code = "DL4094"
+	CodeDuplicateShortCode         Code = "DL4095"
 	CodeTransactionNotExist        Code = "DL4040"
 	CodeInvalidDeeplink            Code = "DL4020"
 	CodeDeeplinkExpired            Code = "DL4021"
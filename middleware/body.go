@@ -0,0 +1,182 @@
+package middleware
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"log/slog"
+	"mime"
+	"mime/multipart"
+	"net/url"
+	"strings"
+	"unicode/utf8"
+)
+
+// textMediaTypes are logged as text, in addition to text/* and +xml types.
+var textMediaTypes = map[string]struct{}{
+	"application/xml":        {},
+	"application/javascript": {},
+	"application/graphql":    {},
+	"application/x-ndjson":   {},
+}
+
+// bodyValue returns the value logged for a body of the given content type.
+//
+// JSON, form and multipart bodies are parsed, so logx redacts them field by
+// field with its key rules instead of scanning a string. Text is logged as
+// is, truncated to maxSize. Binary bodies, and structured bodies larger than
+// maxSize, which cannot be parsed once truncated, are logged as their size
+// and SHA-256 only.
+func bodyValue(contentType string, body capturedBody, maxSize int) slog.Value {
+	if body.size == 0 {
+		return slog.StringValue("")
+	}
+	complete := body.size <= maxSize && !body.truncated()
+
+	mediaType, params, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		mediaType, _, _ = strings.Cut(strings.ToLower(contentType), ";")
+		mediaType = strings.TrimSpace(mediaType)
+	}
+
+	switch {
+	case mediaType == "application/json" || strings.HasSuffix(mediaType, "+json"):
+		if !complete {
+			return binaryValue(body)
+		}
+		if v, ok := parseJSON(body.data); ok {
+			return slog.AnyValue(v)
+		}
+		return textValue(body, maxSize)
+
+	case mediaType == "application/x-www-form-urlencoded":
+		if !complete {
+			return binaryValue(body)
+		}
+		if values, err := url.ParseQuery(string(body.data)); err == nil {
+			return slog.AnyValue(formValue(values))
+		}
+		return textValue(body, maxSize)
+
+	case mediaType == "multipart/form-data" && params["boundary"] != "":
+		if body.truncated() {
+			return binaryValue(body)
+		}
+		if v, ok := parseMultipart(body.data, params["boundary"], maxSize); ok {
+			return slog.AnyValue(v)
+		}
+		return binaryValue(body)
+
+	case isTextMediaType(mediaType), mediaType == "" && utf8.Valid(body.data):
+		return textValue(body, maxSize)
+
+	default:
+		return binaryValue(body)
+	}
+}
+
+// queryValue returns the value logged for a query string, parsed like a form
+// so logx redacts its parameters by key.
+func queryValue(query string) slog.Value {
+	values, err := url.ParseQuery(query)
+	if err != nil {
+		return slog.StringValue(query)
+	}
+	return slog.AnyValue(formValue(values))
+}
+
+func isTextMediaType(mediaType string) bool {
+	if strings.HasPrefix(mediaType, "text/") || strings.HasSuffix(mediaType, "+xml") {
+		return true
+	}
+	_, ok := textMediaTypes[mediaType]
+	return ok
+}
+
+func textValue(body capturedBody, maxSize int) slog.Value {
+	data := body.data
+	if len(data) > maxSize {
+		data = data[:maxSize]
+	}
+	return slog.StringValue(string(data))
+}
+
+func binaryValue(body capturedBody) slog.Value {
+	return slog.GroupValue(
+		slog.Int("size", body.size),
+		slog.String("sha256", hex.EncodeToString(body.sha256())),
+	)
+}
+
+// parseJSON decodes a single JSON document. Numbers are decoded as
+// json.Number so large integers are logged unchanged.
+func parseJSON(body []byte) (any, bool) {
+	var v any
+	decoder := json.NewDecoder(bytes.NewReader(body))
+	decoder.UseNumber()
+	if err := decoder.Decode(&v); err != nil || decoder.More() {
+		return nil, false
+	}
+	return v, true
+}
+
+// formValue flattens form values: a key with a single value maps to a string,
+// one with several values to a slice.
+func formValue(values url.Values) map[string]any {
+	form := make(map[string]any, len(values))
+	for key, vs := range values {
+		if len(vs) == 1 {
+			form[key] = vs[0]
+		} else {
+			form[key] = vs
+		}
+	}
+	return form
+}
+
+// parseMultipart parses a multipart form. Fields are logged like a form,
+// truncated to maxSize, and files as their name, size and SHA-256.
+func parseMultipart(body []byte, boundary string, maxSize int) (map[string]any, bool) {
+	values := make(map[string][]any)
+	reader := multipart.NewReader(bytes.NewReader(body), boundary)
+	for {
+		part, err := reader.NextPart()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, false
+		}
+
+		data, err := io.ReadAll(part)
+		if err != nil {
+			return nil, false
+		}
+		name := part.FormName()
+		if filename := part.FileName(); filename != "" {
+			sum := sha256.Sum256(data)
+			values[name] = append(values[name], map[string]any{
+				"filename": filename,
+				"size":     len(data),
+				"sha256":   hex.EncodeToString(sum[:]),
+			})
+			continue
+		}
+		if len(data) > maxSize {
+			data = data[:maxSize]
+		}
+		values[name] = append(values[name], string(data))
+	}
+
+	form := make(map[string]any, len(values))
+	for key, vs := range values {
+		if len(vs) == 1 {
+			form[key] = vs[0]
+		} else {
+			form[key] = vs
+		}
+	}
+	return form, true
+}
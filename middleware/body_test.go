@@ -0,0 +1,113 @@
+package middleware
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"log/slog"
+	"mime/multipart"
+	"strings"
+	"testing"
+
+	"deeplink-bff/pkg/logx"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func sha256Hex(data string) string {
+	sum := sha256.Sum256([]byte(data))
+	return hex.EncodeToString(sum[:])
+}
+
+func TestBodyValue(t *testing.T) {
+	binary := string([]byte{0x89, 'P', 'N', 'G', 0, 1})
+	summary := func(body string) slog.Value {
+		return slog.GroupValue(slog.Int("size", len(body)), slog.String("sha256", sha256Hex(body)))
+	}
+
+	tests := []struct {
+		name        string
+		contentType string
+		body        string
+		want        slog.Value
+	}{
+		{"empty", "application/json", "", slog.StringValue("")},
+		{"json", "application/json; charset=utf-8", `{"password":"p","n":12345678901234567890}`,
+			slog.AnyValue(map[string]any{"password": "p", "n": jsonNumber("12345678901234567890")})},
+		{"problem json", "application/problem+json", `[1]`, slog.AnyValue([]any{jsonNumber("1")})},
+		{"invalid json is text", "application/json", `{"a":`, slog.StringValue(`{"a":`)},
+		{"json larger than max", "application/json", `{"a":"` + strings.Repeat("x", 80) + `"}`,
+			summary(`{"a":"` + strings.Repeat("x", 80) + `"}`)},
+		{"form", "application/x-www-form-urlencoded", "password=p&a=1&a=2",
+			slog.AnyValue(map[string]any{"password": "p", "a": []string{"1", "2"}})},
+		{"text truncated", "text/plain", strings.Repeat("x", 80), slog.StringValue(strings.Repeat("x", 64))},
+		{"no content type text", "", "hello", slog.StringValue("hello")},
+		{"no content type binary", "", binary, summary(binary)},
+		{"binary", "image/png", binary, summary(binary)},
+		{"unknown application type", "application/octet-stream", "plain", summary("plain")},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := bodyValue(tt.contentType, newCapturedBody([]byte(tt.body)), 64)
+			assert.Equal(t, tt.want.String(), got.String())
+		})
+	}
+}
+
+func TestBodyValueMultipart(t *testing.T) {
+	buf := &bytes.Buffer{}
+	w := multipart.NewWriter(buf)
+	require.NoError(t, w.WriteField("password", "p"))
+	file, err := w.CreateFormFile("file", "a.bin")
+	require.NoError(t, err)
+	_, err = file.Write([]byte{0, 1, 2})
+	require.NoError(t, err)
+	require.NoError(t, w.Close())
+
+	got := bodyValue(w.FormDataContentType(), newCapturedBody(buf.Bytes()), 1024)
+	assert.Equal(t, map[string]any{
+		"password": "p",
+		"file":     map[string]any{"filename": "a.bin", "size": 3, "sha256": sha256Hex("\x00\x01\x02")},
+	}, got.Any())
+}
+
+func TestBodyValueStreamed(t *testing.T) {
+	body := `{"password":"` + strings.Repeat("x", 40) + `"}`
+	capture := newBodyCapture(16)
+	capture.write([]byte(body[:10]))
+	capture.write([]byte(body[10:]))
+
+	// only the start is kept, the size and hash cover the whole body
+	captured := capture.body()
+	assert.Len(t, captured.data, 16)
+	got := bodyValue("application/json", captured, 1024)
+	assert.Equal(t, slog.GroupValue(slog.Int("size", len(body)), slog.String("sha256", sha256Hex(body))).String(), got.String())
+}
+
+func TestBodyValueSensitiveNumber(t *testing.T) {
+	buf := &bytes.Buffer{}
+	logger, err := logx.New(logx.Config{}, logx.WithWriter(buf), logx.WithRuntimeInfo(false))
+	require.NoError(t, err)
+
+	body := `{"customer_id":12345,"cvv":987,"amount":10.5}`
+	logger.Info("msg", slog.Any("body", bodyValue("application/json", newCapturedBody([]byte(body)), 1024)))
+
+	// a redacted number must not break the rest of the body
+	var record struct {
+		Body map[string]any `json:"body"`
+	}
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &record), buf.String())
+	assert.Equal(t, map[string]any{
+		"customer_id": logx.DefaultRedactMessage,
+		"cvv":         logx.DefaultRedactMessage,
+		"amount":      10.5,
+	}, record.Body)
+}
+
+func jsonNumber(s string) any {
+	v, _ := parseJSON([]byte(s))
+	return v
+}
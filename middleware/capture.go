@@ -0,0 +1,154 @@
+package middleware
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/sha256"
+	"hash"
+	"io"
+	"net"
+	"net/http"
+)
+
+// capturedBody is a request or response body as seen by the logger. data
+// holds at most the max body size, while size and sum cover the whole body.
+type capturedBody struct {
+	data []byte
+	size int
+	// sum is the SHA-256 of the whole body, nil when data is the whole body
+	sum []byte
+}
+
+// newCapturedBody returns a body the framework holds in memory, as Fiber does.
+func newCapturedBody(data []byte) capturedBody {
+	return capturedBody{data: data, size: len(data)}
+}
+
+// sha256 returns the SHA-256 of the whole body.
+func (b capturedBody) sha256() []byte {
+	if b.sum != nil {
+		return b.sum
+	}
+	sum := sha256.Sum256(b.data)
+	return sum[:]
+}
+
+// truncated reports whether data is only the start of the body.
+func (b capturedBody) truncated() bool {
+	return len(b.data) < b.size
+}
+
+// bodyCapture records a streamed body: its first maxSize bytes, its size and
+// its SHA-256.
+type bodyCapture struct {
+	buf     bytes.Buffer
+	hash    hash.Hash
+	maxSize int
+	size    int
+}
+
+func newBodyCapture(maxSize int) *bodyCapture {
+	return &bodyCapture{hash: sha256.New(), maxSize: maxSize}
+}
+
+func (b *bodyCapture) write(p []byte) {
+	if room := b.maxSize - b.buf.Len(); room > 0 {
+		b.buf.Write(p[:min(len(p), room)])
+	}
+	b.hash.Write(p)
+	b.size += len(p)
+}
+
+func (b *bodyCapture) body() capturedBody {
+	return capturedBody{data: b.buf.Bytes(), size: b.size, sum: b.hash.Sum(nil)}
+}
+
+// bodyReader captures a request body as the handler reads it. A body the
+// handler does not read is not captured.
+type bodyReader struct {
+	io.ReadCloser
+	capture *bodyCapture
+}
+
+// implements io.Reader
+func (r *bodyReader) Read(b []byte) (int, error) {
+	n, err := r.ReadCloser.Read(b)
+	r.capture.write(b[:n])
+	return n, err
+}
+
+func newBodyReader(reader io.ReadCloser, maxSize int) *bodyReader {
+	return &bodyReader{ReadCloser: reader, capture: newBodyCapture(maxSize)}
+}
+
+var _ http.ResponseWriter = (*responseWriter)(nil)
+var _ http.Flusher = (*responseWriter)(nil)
+var _ http.Hijacker = (*responseWriter)(nil)
+
+// responseWriter captures the status and body of a net/http response.
+type responseWriter struct {
+	http.ResponseWriter
+	capture *bodyCapture
+	status  int
+}
+
+func newResponseWriter(w http.ResponseWriter, maxSize int) *responseWriter {
+	return &responseWriter{ResponseWriter: w, capture: newBodyCapture(maxSize)}
+}
+
+// newStatusWriter returns a responseWriter that tracks the status only.
+func newStatusWriter(w http.ResponseWriter) *responseWriter {
+	return &responseWriter{ResponseWriter: w}
+}
+
+// implements http.ResponseWriter
+func (w *responseWriter) WriteHeader(status int) {
+	if w.status == 0 {
+		w.status = status
+	}
+	w.ResponseWriter.WriteHeader(status)
+}
+
+// implements http.ResponseWriter
+func (w *responseWriter) Write(b []byte) (int, error) {
+	if w.status == 0 {
+		w.status = http.StatusOK
+	}
+	n, err := w.ResponseWriter.Write(b)
+	if w.capture != nil {
+		w.capture.write(b[:n])
+	}
+	return n, err
+}
+
+// implements http.Flusher
+func (w *responseWriter) Flush() {
+	if flusher, ok := w.ResponseWriter.(http.Flusher); ok {
+		flusher.Flush()
+	}
+}
+
+// implements http.Hijacker
+func (w *responseWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	hijacker, ok := w.ResponseWriter.(http.Hijacker)
+	if !ok {
+		return nil, nil, http.ErrNotSupported
+	}
+	return hijacker.Hijack()
+}
+
+// Unwrap lets http.ResponseController reach the wrapped writer.
+func (w *responseWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
+
+// response returns the captured response. A handler that writes nothing
+// answers 200.
+func (w *responseWriter) response() *Response {
+	status := w.status
+	if status == 0 {
+		status = http.StatusOK
+	}
+	body := w.capture.body()
+	return &Response{Status: status, Header: w.Header(), Length: body.size, body: body}
+}
@@ -0,0 +1,129 @@
+package middleware
+
+import (
+	"log/slog"
+	"net/http"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/gofiber/fiber/v2/utils"
+	"github.com/mdobak/go-xerrors"
+)
+
+const requestIDCtx = "middleware.request-id"
+
+// Logger returns a Fiber middleware with default configuration, logging only
+// the requests every filter accepts.
+func Logger(filters ...Filter) fiber.Handler {
+	return LoggerWithConfig(defaultConfig(filters))
+}
+
+// LoggerWithConfig sets up request logging based on the provided Config.
+func LoggerWithConfig(config Config) fiber.Handler {
+	logger := newRequestLogger(config)
+
+	return func(c *fiber.Ctx) error {
+		ctx, requestID := logger.start(c.UserContext(), c.Get(RequestIDHeaderKey))
+		if requestID != "" {
+			c.Set(RequestIDHeaderKey, requestID) // Set for response header
+			c.Locals(requestIDCtx, requestID)
+		}
+		c.SetUserContext(ctx)
+
+		// Call the next handler in the middleware chain and capture any error after setting up context but before logging
+		err := c.Next()
+
+		// Answer a returned error now, as Fiber's own logger does, so the
+		// status and body logged are those the client gets
+		if err != nil {
+			if handlerErr := c.App().ErrorHandler(c, err); handlerErr != nil {
+				_ = c.SendStatus(http.StatusInternalServerError)
+			}
+		}
+
+		// Fiber holds both bodies in memory. Its strings point into buffers
+		// fasthttp reuses for the next request, so everything the record
+		// keeps is copied before an async handler gets to write it.
+		req := &Request{
+			Method: utils.CopyString(c.Method()),
+			Host:   string(c.Context().Host()),
+			Path:   utils.CopyString(c.Path()),
+			Query:  string(c.Request().URI().QueryString()),
+			Params: copyParams(c.AllParams()),
+			Header: copyHeader(c.GetReqHeaders()),
+			body:   newCapturedBody(c.Body()),
+		}
+		responseBody := c.Response().Body()
+		resp := &Response{
+			Status: c.Response().StatusCode(),
+			Header: copyHeader(c.GetRespHeaders()),
+			Length: len(responseBody),
+			body:   newCapturedBody(responseBody),
+		}
+		logger.log(ctx, req, resp, err)
+
+		// the error is answered above, it must not be handled twice
+		return nil
+	}
+}
+
+// copyParams copies route params out of fasthttp's buffers.
+func copyParams(params map[string]string) map[string]string {
+	copied := make(map[string]string, len(params))
+	for key, value := range params {
+		copied[utils.CopyString(key)] = utils.CopyString(value)
+	}
+	return copied
+}
+
+// copyHeader copies headers out of fasthttp's buffers.
+func copyHeader(header map[string][]string) http.Header {
+	copied := make(http.Header, len(header))
+	for key, values := range header {
+		copiedValues := make([]string, len(values))
+		for i, value := range values {
+			copiedValues[i] = utils.CopyString(value)
+		}
+		copied[utils.CopyString(key)] = copiedValues
+	}
+	return copied
+}
+
+// GetRequestID returns the request identifier.
+func GetRequestID(c *fiber.Ctx) string {
+	if id, ok := c.Locals(requestIDCtx).(string); ok {
+		return id
+	}
+	return ""
+}
+
+// AddCustomAttributes adds custom attributes to the request log.
+func AddCustomAttributes(c *fiber.Ctx, attr slog.Attr) {
+	AddContextAttributes(c.UserContext(), attr)
+}
+
+// Recovery recovers from panics, logs them and answers the standard DL9999
+// error response.
+func Recovery(stack bool) fiber.Handler {
+	return RecoveryWithConfig(RecoveryConfig{Stack: stack})
+}
+
+// RecoveryWithConfig sets up panic recovery based on the provided RecoveryConfig.
+func RecoveryWithConfig(config RecoveryConfig) fiber.Handler {
+	recoverer := newRecoverer(config)
+
+	return func(c *fiber.Ctx) (err error) {
+		defer func() {
+			if rec := recover(); rec != nil {
+				ctx := c.UserContext()
+				if recoverer.handle(ctx, rec, xerrors.FromRecover(rec), c.Method(), c.OriginalURL()) {
+					// If the connection is dead, we can't write a status to it.
+					return
+				}
+
+				// By sending a response, we effectively handle the panic.
+				err = c.Status(http.StatusInternalServerError).JSON(newPanicResponse(ctx))
+			}
+		}()
+		return c.Next() // Call the next handler in the chain.
+	}
+}
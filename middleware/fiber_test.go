@@ -0,0 +1,139 @@
+package middleware_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"deeplink-bff/middleware"
+	"deeplink-bff/pkg/logx"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/mdobak/go-xerrors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFiberLoggerReturnedError(t *testing.T) {
+	tests := []struct {
+		name   string
+		err    error
+		status int
+		level  string
+		msg    string
+		stack  bool
+	}{
+		{"internal error", xerrors.New("database unavailable"), http.StatusInternalServerError, "ERROR", "database unavailable", true},
+		{"fiber error", fiber.NewError(http.StatusServiceUnavailable, "partner api down"), http.StatusServiceUnavailable, "ERROR", "partner api down", false},
+		{"client error", fiber.ErrNotFound, http.StatusNotFound, "WARN", "Not Found", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			records := captureLogs(t)
+			app := fiber.New()
+			app.Use(middleware.Logger())
+			app.Get("/", func(c *fiber.Ctx) error { return tt.err })
+
+			resp, err := app.Test(httptest.NewRequest(http.MethodGet, "/", nil))
+			require.NoError(t, err)
+			body, err := io.ReadAll(resp.Body)
+			require.NoError(t, err)
+
+			// the status logged is the one the client gets
+			assert.Equal(t, tt.status, resp.StatusCode)
+			logs := records()
+			require.Len(t, logs, 1)
+			assert.Equal(t, tt.level, logs[0]["level"])
+			assert.Equal(t, tt.msg, logs[0]["msg"])
+			assert.EqualValues(t, tt.status, lookup(logs[0], "response.status"))
+			assert.Equal(t, string(body), lookup(logs[0], "response.body"))
+			assert.Equal(t, tt.stack, lookup(logs[0], "response.stacktrace") != nil)
+		})
+	}
+}
+
+func TestFiberLoggerRequest(t *testing.T) {
+	records := captureLogs(t)
+	var requestID string
+	app := fiber.New()
+	app.Use(middleware.Logger())
+	app.Post("/deeplink/:id", func(c *fiber.Ctx) error {
+		requestID = middleware.GetRequestID(c)
+		middleware.AddCustomAttributes(c, slog.String("partner", "acme"))
+		return c.JSON(fiber.Map{"id": c.Params("id")})
+	})
+
+	req := httptest.NewRequest(http.MethodPost, "/deeplink/abc?page=2", strings.NewReader(`{"password":"p-1"}`))
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := app.Test(req)
+	require.NoError(t, err)
+
+	assert.NotEmpty(t, requestID)
+	assert.Equal(t, requestID, resp.Header.Get(middleware.RequestIDHeaderKey))
+	logs := records()
+	require.Len(t, logs, 1)
+	assert.Equal(t, requestID, logs[0][middleware.RequestIDKey])
+	assert.Equal(t, "acme", logs[0]["partner"])
+	assert.Equal(t, "abc", lookup(logs[0], "request.params.id"))
+	assert.Equal(t, "2", lookup(logs[0], "request.query.page"))
+	assert.NotEqual(t, "p-1", lookup(logs[0], "request.body.password"))
+	assert.Equal(t, "abc", lookup(logs[0], "response.body.id"))
+}
+
+// gatedWriter holds the first write until release is closed, so records
+// queued behind it are written after later requests reused Fiber's buffers.
+type gatedWriter struct {
+	release chan struct{}
+	once    sync.Once
+	buf     bytes.Buffer
+}
+
+func (w *gatedWriter) Write(p []byte) (int, error) {
+	w.once.Do(func() { <-w.release })
+	return w.buf.Write(p)
+}
+
+func TestFiberLoggerAsync(t *testing.T) {
+	async, err := logx.NewAsync(logx.AsyncConfig{})
+	require.NoError(t, err)
+	writer := &gatedWriter{release: make(chan struct{})}
+	logger, err := logx.New(logx.Config{}, logx.WithWriter(writer), logx.WithRuntimeInfo(false), logx.WithAsync(async))
+	require.NoError(t, err)
+	previous := slog.Default()
+	slog.SetDefault(logger)
+	t.Cleanup(func() { slog.SetDefault(previous) })
+
+	app := fiber.New()
+	app.Use(middleware.Logger())
+	app.Get("/r/:id", func(c *fiber.Ctx) error { return c.SendString("ok") })
+
+	ids := []string{"aaaaaaaaaaaa", "bbbbbbbbbbbb", "cccccccccccc"}
+	for _, id := range ids {
+		req := httptest.NewRequest(http.MethodGet, "/r/"+id, nil)
+		req.Header.Set(middleware.RequestIDHeaderKey, "request-"+id)
+		_, err := app.Test(req)
+		require.NoError(t, err)
+	}
+	close(writer.release)
+	require.NoError(t, async.Close(context.Background()))
+
+	lines := strings.Split(strings.TrimSpace(writer.buf.String()), "\n")
+	require.Len(t, lines, len(ids))
+	for i, id := range ids {
+		record := map[string]any{}
+		require.NoError(t, json.Unmarshal([]byte(lines[i]), &record), lines[i])
+		// each record keeps the values of its own request
+		assert.Equal(t, "request-"+id, record[middleware.RequestIDKey])
+		assert.Equal(t, "/r/"+id, lookup(record, "request.path"))
+		assert.Equal(t, id, lookup(record, "request.params.id"))
+		assert.Equal(t, http.MethodGet, lookup(record, "request.method"))
+	}
+}
@@ -0,0 +1,170 @@
+package middleware
+
+import (
+	"regexp"
+	"slices"
+	"strings"
+)
+
+// Filter decides whether a request is logged. It runs after the handler, so
+// the response status is known. A request is logged only when every filter
+// of Config.Filters returns true.
+type Filter func(req *Request, resp *Response) bool
+
+// Basic
+
+// Accept logs the requests filter returns true for.
+func Accept(filter Filter) Filter { return filter }
+
+// Ignore logs the requests filter returns false for.
+func Ignore(filter Filter) Filter {
+	return func(req *Request, resp *Response) bool { return !filter(req, resp) }
+}
+
+// AllOf returns true when every filter returns true.
+func AllOf(filters ...Filter) Filter {
+	return func(req *Request, resp *Response) bool {
+		for _, filter := range filters {
+			if !filter(req, resp) {
+				return false
+			}
+		}
+		return true
+	}
+}
+
+// AnyOf returns true when at least one filter returns true.
+func AnyOf(filters ...Filter) Filter {
+	return func(req *Request, resp *Response) bool {
+		for _, filter := range filters {
+			if filter(req, resp) {
+				return true
+			}
+		}
+		return false
+	}
+}
+
+// Method
+
+// AcceptMethod logs requests with one of methods, case-insensitively.
+func AcceptMethod(methods ...string) Filter {
+	return func(req *Request, resp *Response) bool {
+		return slices.ContainsFunc(methods, func(method string) bool {
+			return strings.EqualFold(method, req.Method)
+		})
+	}
+}
+
+// IgnoreMethod does not log requests with one of methods.
+func IgnoreMethod(methods ...string) Filter {
+	return Ignore(AcceptMethod(methods...))
+}
+
+// Status
+
+// AcceptStatus logs requests answered with one of statuses.
+func AcceptStatus(statuses ...int) Filter {
+	return func(req *Request, resp *Response) bool {
+		return slices.Contains(statuses, resp.Status)
+	}
+}
+
+// IgnoreStatus does not log requests answered with one of statuses.
+func IgnoreStatus(statuses ...int) Filter {
+	return Ignore(AcceptStatus(statuses...))
+}
+
+// AcceptStatusRange logs requests answered with a status in [min, max].
+func AcceptStatusRange(min, max int) Filter {
+	return func(req *Request, resp *Response) bool {
+		status := resp.Status
+		return status >= min && status <= max
+	}
+}
+
+// IgnoreStatusRange does not log requests answered with a status in [min, max].
+func IgnoreStatusRange(min, max int) Filter {
+	return Ignore(AcceptStatusRange(min, max))
+}
+
+// Path
+
+// AcceptPath logs requests to one of paths exactly.
+func AcceptPath(paths ...string) Filter {
+	return func(req *Request, resp *Response) bool {
+		return slices.Contains(paths, req.Path)
+	}
+}
+
+// IgnorePath does not log requests to one of paths.
+func IgnorePath(paths ...string) Filter {
+	return Ignore(AcceptPath(paths...))
+}
+
+// AcceptPathPrefix logs requests whose path starts with one of prefixes.
+func AcceptPathPrefix(prefixes ...string) Filter {
+	return func(req *Request, resp *Response) bool {
+		path := req.Path
+		return slices.ContainsFunc(prefixes, func(prefix string) bool {
+			return strings.HasPrefix(path, prefix)
+		})
+	}
+}
+
+// IgnorePathPrefix does not log requests whose path starts with one of prefixes.
+func IgnorePathPrefix(prefixes ...string) Filter {
+	return Ignore(AcceptPathPrefix(prefixes...))
+}
+
+// AcceptPathMatch logs requests whose path matches one of regs.
+func AcceptPathMatch(regs ...*regexp.Regexp) Filter {
+	return func(req *Request, resp *Response) bool {
+		path := req.Path
+		return slices.ContainsFunc(regs, func(reg *regexp.Regexp) bool {
+			return reg.MatchString(path)
+		})
+	}
+}
+
+// IgnorePathMatch does not log requests whose path matches one of regs.
+func IgnorePathMatch(regs ...*regexp.Regexp) Filter {
+	return Ignore(AcceptPathMatch(regs...))
+}
+
+// Host
+
+// AcceptHost logs requests to one of hosts, case-insensitively. A host
+// without a port matches every port.
+func AcceptHost(hosts ...string) Filter {
+	return func(req *Request, resp *Response) bool {
+		host := req.Host
+		hostname, _, _ := strings.Cut(host, ":")
+		return slices.ContainsFunc(hosts, func(h string) bool {
+			return strings.EqualFold(h, host) || strings.EqualFold(h, hostname)
+		})
+	}
+}
+
+// IgnoreHost does not log requests to one of hosts.
+func IgnoreHost(hosts ...string) Filter {
+	return Ignore(AcceptHost(hosts...))
+}
+
+// User agent
+
+// AcceptUserAgent logs requests whose user agent contains one of parts,
+// case-insensitively, e.g. "kube-probe".
+func AcceptUserAgent(parts ...string) Filter {
+	return func(req *Request, resp *Response) bool {
+		userAgent := strings.ToLower(req.Header.Get("User-Agent"))
+		return slices.ContainsFunc(parts, func(part string) bool {
+			return strings.Contains(userAgent, strings.ToLower(part))
+		})
+	}
+}
+
+// IgnoreUserAgent does not log requests whose user agent contains one of parts.
+func IgnoreUserAgent(parts ...string) Filter {
+	return Ignore(AcceptUserAgent(parts...))
+}
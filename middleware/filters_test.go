@@ -0,0 +1,88 @@
+package middleware_test
+
+import (
+	"net/http"
+	"regexp"
+	"testing"
+
+	"deeplink-bff/middleware"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFilters(t *testing.T) {
+	stats := regexp.MustCompile(`^/api/v1/(deeplink|partner)/[^/]+/stats$`)
+	statsPolling := middleware.Ignore(middleware.AllOf(
+		middleware.AcceptMethod(http.MethodGet),
+		middleware.AcceptPathMatch(stats),
+		middleware.AcceptStatusRange(200, 299),
+	))
+
+	tests := []struct {
+		name   string
+		filter middleware.Filter
+		req    middleware.Request
+		status int
+		want   bool
+	}{
+		// Basic
+		{"accept", middleware.Accept(middleware.AcceptPath("/a")), middleware.Request{Path: "/a"}, 200, true},
+		{"ignore", middleware.Ignore(middleware.AcceptPath("/a")), middleware.Request{Path: "/a"}, 200, false},
+		{"all of", middleware.AllOf(middleware.AcceptPath("/a"), middleware.AcceptMethod("GET")), middleware.Request{Path: "/a", Method: "GET"}, 200, true},
+		{"all of one false", middleware.AllOf(middleware.AcceptPath("/a"), middleware.AcceptMethod("GET")), middleware.Request{Path: "/a", Method: "POST"}, 200, false},
+		{"all of empty", middleware.AllOf(), middleware.Request{}, 200, true},
+		{"any of", middleware.AnyOf(middleware.AcceptPath("/a"), middleware.AcceptMethod("GET")), middleware.Request{Path: "/b", Method: "GET"}, 200, true},
+		{"any of none", middleware.AnyOf(middleware.AcceptPath("/a"), middleware.AcceptMethod("GET")), middleware.Request{Path: "/b", Method: "POST"}, 200, false},
+		{"any of empty", middleware.AnyOf(), middleware.Request{}, 200, false},
+		{"nested", middleware.AnyOf(middleware.AllOf(middleware.AcceptPath("/a"), middleware.AcceptStatus(500)), middleware.IgnoreMethod("GET")), middleware.Request{Path: "/a", Method: "GET"}, 500, true},
+
+		// Composed
+		{"stats polling ignored", statsPolling, middleware.Request{Method: "GET", Path: "/api/v1/deeplink/abc/stats"}, 200, false},
+		{"failed stats polling logged", statsPolling, middleware.Request{Method: "GET", Path: "/api/v1/partner/acme/stats"}, 502, true},
+		{"other path logged", statsPolling, middleware.Request{Method: "GET", Path: "/api/v1/deeplink/abc"}, 200, true},
+		{"other method logged", statsPolling, middleware.Request{Method: "POST", Path: "/api/v1/deeplink/abc/stats"}, 200, true},
+
+		// Method
+		{"method case-insensitive", middleware.AcceptMethod("get"), middleware.Request{Method: "GET"}, 200, true},
+		{"ignore method", middleware.IgnoreMethod("OPTIONS", "HEAD"), middleware.Request{Method: "HEAD"}, 200, false},
+
+		// Status
+		{"status", middleware.AcceptStatus(200, 204), middleware.Request{}, 204, true},
+		{"ignore status", middleware.IgnoreStatus(404), middleware.Request{}, 404, false},
+		{"status range min", middleware.AcceptStatusRange(200, 299), middleware.Request{}, 200, true},
+		{"status range max", middleware.AcceptStatusRange(200, 299), middleware.Request{}, 299, true},
+		{"status range below", middleware.AcceptStatusRange(200, 299), middleware.Request{}, 199, false},
+		{"status range above", middleware.AcceptStatusRange(200, 299), middleware.Request{}, 300, false},
+		{"ignore status range", middleware.IgnoreStatusRange(500, 599), middleware.Request{}, 503, false},
+
+		// Path
+		{"path exact", middleware.AcceptPath("/health"), middleware.Request{Path: "/health/live"}, 200, false},
+		{"ignore path", middleware.IgnorePath("/health", "/version"), middleware.Request{Path: "/version"}, 200, false},
+		{"path prefix", middleware.AcceptPathPrefix("/docs/"), middleware.Request{Path: "/docs/index.html"}, 200, true},
+		{"ignore path prefix", middleware.IgnorePathPrefix("/docs/"), middleware.Request{Path: "/api/docs/"}, 200, true},
+		{"path match", middleware.AcceptPathMatch(stats), middleware.Request{Path: "/api/v1/deeplink/abc/stats"}, 200, true},
+		{"ignore path match", middleware.IgnorePathMatch(stats), middleware.Request{Path: "/api/v1/deeplink/abc/stats/x"}, 200, true},
+
+		// Host
+		{"host", middleware.AcceptHost("example.com"), middleware.Request{Host: "example.com"}, 200, true},
+		{"host case-insensitive", middleware.AcceptHost("Example.com"), middleware.Request{Host: "example.COM"}, 200, true},
+		{"host without port matches any port", middleware.AcceptHost("example.com"), middleware.Request{Host: "example.com:8080"}, 200, true},
+		{"host with port", middleware.AcceptHost("example.com:8080"), middleware.Request{Host: "example.com:8080"}, 200, true},
+		{"host with other port", middleware.AcceptHost("example.com:8080"), middleware.Request{Host: "example.com:9090"}, 200, false},
+		{"host with port needs a port", middleware.AcceptHost("example.com:8080"), middleware.Request{Host: "example.com"}, 200, false},
+		{"other host", middleware.AcceptHost("example.com"), middleware.Request{Host: "api.example.com"}, 200, false},
+		{"ignore host", middleware.IgnoreHost("localhost"), middleware.Request{Host: "localhost:4000"}, 200, false},
+
+		// User agent
+		{"user agent", middleware.AcceptUserAgent("kube-probe"), middleware.Request{Header: http.Header{"User-Agent": {"Kube-Probe/1.29"}}}, 200, true},
+		{"ignore user agent", middleware.IgnoreUserAgent("kube-probe"), middleware.Request{Header: http.Header{"User-Agent": {"curl/8.0"}}}, 200, true},
+		{"no user agent", middleware.AcceptUserAgent("kube-probe"), middleware.Request{Header: http.Header{}}, 200, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := tt.req
+			assert.Equal(t, tt.want, tt.filter(&req, &middleware.Response{Status: tt.status}))
+		})
+	}
+}
@@ -0,0 +1,113 @@
+package middleware
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/mdobak/go-xerrors"
+)
+
+var _ gin.ResponseWriter = (*ginBodyWriter)(nil)
+
+// ginBodyWriter captures the body of a Gin response.
+type ginBodyWriter struct {
+	gin.ResponseWriter
+	capture *bodyCapture
+}
+
+// implements gin.ResponseWriter
+func (w *ginBodyWriter) Write(b []byte) (int, error) {
+	n, err := w.ResponseWriter.Write(b)
+	w.capture.write(b[:n])
+	return n, err
+}
+
+// implements gin.ResponseWriter
+func (w *ginBodyWriter) WriteString(s string) (int, error) {
+	n, err := w.ResponseWriter.WriteString(s)
+	w.capture.write([]byte(s[:n]))
+	return n, err
+}
+
+// GinLogger returns a Gin middleware with default configuration, logging
+// only the requests every filter accepts.
+func GinLogger(filters ...Filter) gin.HandlerFunc {
+	return GinLoggerWithConfig(defaultConfig(filters))
+}
+
+// GinLoggerWithConfig sets up request logging based on the provided Config.
+func GinLoggerWithConfig(config Config) gin.HandlerFunc {
+	logger := newRequestLogger(config)
+
+	return func(c *gin.Context) {
+		ctx, requestID := logger.start(c.Request.Context(), c.GetHeader(RequestIDHeaderKey))
+		if requestID != "" {
+			c.Header(RequestIDHeaderKey, requestID)
+		}
+		c.Request = c.Request.WithContext(ctx)
+
+		var requestBody *bodyReader
+		if config.WithRequestBody && c.Request.Body != nil && c.Request.Body != http.NoBody {
+			requestBody = newBodyReader(c.Request.Body, RequestBodyMaxSize)
+			c.Request.Body = requestBody
+		}
+		writer := &ginBodyWriter{ResponseWriter: c.Writer, capture: newBodyCapture(ResponseBodyMaxSize)}
+		c.Writer = writer
+
+		c.Next()
+
+		req := newHTTPRequest(c.Request, requestBody)
+		req.Params = make(map[string]string, len(c.Params))
+		for _, param := range c.Params {
+			req.Params[param.Key] = param.Value
+		}
+		body := writer.capture.body()
+		resp := &Response{
+			Status: writer.Status(),
+			Header: writer.Header(),
+			Length: body.size,
+			body:   body,
+		}
+
+		var err error
+		if last := c.Errors.Last(); last != nil {
+			err = last
+		}
+		logger.log(ctx, req, resp, err)
+	}
+}
+
+// GinRecovery recovers from panics in Gin handlers, logs them and answers the
+// standard DL9999 error response, unless the handler already wrote the
+// response headers.
+func GinRecovery(stack bool) gin.HandlerFunc {
+	return GinRecoveryWithConfig(RecoveryConfig{Stack: stack})
+}
+
+// GinRecoveryWithConfig sets up panic recovery based on the provided RecoveryConfig.
+func GinRecoveryWithConfig(config RecoveryConfig) gin.HandlerFunc {
+	recoverer := newRecoverer(config)
+
+	return func(c *gin.Context) {
+		defer func() {
+			if rec := recover(); rec != nil {
+				ctx := c.Request.Context()
+				err := xerrors.FromRecover(rec)
+				if recoverer.handle(ctx, rec, err, c.Request.Method, c.Request.URL.String()) {
+					// If the connection is dead, we can't write a status to it.
+					c.Error(err) //nolint:errcheck
+					c.Abort()
+					return
+				}
+				// The handler already answered part of the response, a
+				// status and envelope cannot follow it.
+				if c.Writer.Written() {
+					c.Abort()
+					return
+				}
+				c.AbortWithStatusJSON(http.StatusInternalServerError, newPanicResponse(ctx))
+			}
+		}()
+		c.Next()
+	}
+}
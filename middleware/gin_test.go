@@ -0,0 +1,63 @@
+package middleware_test
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"deeplink-bff/middleware"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func init() {
+	gin.SetMode(gin.TestMode)
+}
+
+func TestGinLogger(t *testing.T) {
+	records := captureLogs(t)
+	router := gin.New()
+	router.Use(middleware.GinLogger())
+	router.POST("/deeplink/:id", func(c *gin.Context) {
+		_, _ = io.ReadAll(c.Request.Body)
+		c.String(http.StatusOK, "created %s", c.Param("id"))
+	})
+
+	req := httptest.NewRequest(http.MethodPost, "/deeplink/abc", strings.NewReader(`{"password":"p-1","user":"john"}`))
+	req.Header.Set("Content-Type", "application/json")
+	rec := serveHTTP(router, req)
+
+	assert.NotEmpty(t, rec.Header().Get(middleware.RequestIDHeaderKey))
+	logs := records()
+	require.Len(t, logs, 1)
+	assert.Equal(t, "INFO", logs[0]["level"])
+	assert.Equal(t, "abc", lookup(logs[0], "request.params.id"))
+	assert.Equal(t, "john", lookup(logs[0], "request.body.user"))
+	assert.NotEqual(t, "p-1", lookup(logs[0], "request.body.password"))
+	assert.Equal(t, "created abc", lookup(logs[0], "response.body"))
+	assert.EqualValues(t, len("created abc"), lookup(logs[0], "response.length"))
+}
+
+func TestGinLoggerError(t *testing.T) {
+	records := captureLogs(t)
+	router := gin.New()
+	router.Use(middleware.GinLogger())
+	router.GET("/", func(c *gin.Context) {
+		_ = c.Error(errors.New("partner api down"))
+		c.AbortWithStatus(http.StatusBadGateway)
+	})
+
+	rec := serveHTTP(router, httptest.NewRequest(http.MethodGet, "/", nil))
+
+	assert.Equal(t, http.StatusBadGateway, rec.Code)
+	logs := records()
+	require.Len(t, logs, 1)
+	assert.Equal(t, "ERROR", logs[0]["level"])
+	assert.Equal(t, "partner api down", logs[0]["msg"])
+	assert.EqualValues(t, http.StatusBadGateway, lookup(logs[0], "response.status"))
+}
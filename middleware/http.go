@@ -0,0 +1,96 @@
+package middleware
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/mdobak/go-xerrors"
+)
+
+// HTTPLogger returns a net/http middleware with default configuration,
+// logging only the requests every filter accepts.
+func HTTPLogger(filters ...Filter) func(http.Handler) http.Handler {
+	return HTTPLoggerWithConfig(defaultConfig(filters))
+}
+
+// HTTPLoggerWithConfig sets up request logging based on the provided Config.
+func HTTPLoggerWithConfig(config Config) func(http.Handler) http.Handler {
+	logger := newRequestLogger(config)
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			ctx, requestID := logger.start(r.Context(), r.Header.Get(RequestIDHeaderKey))
+			if requestID != "" {
+				w.Header().Set(RequestIDHeaderKey, requestID)
+			}
+			r = r.WithContext(ctx)
+
+			var requestBody *bodyReader
+			if config.WithRequestBody && r.Body != nil && r.Body != http.NoBody {
+				requestBody = newBodyReader(r.Body, RequestBodyMaxSize)
+				r.Body = requestBody
+			}
+			writer := newResponseWriter(w, ResponseBodyMaxSize)
+
+			next.ServeHTTP(writer, r)
+
+			logger.log(ctx, newHTTPRequest(r, requestBody), writer.response(), nil)
+		})
+	}
+}
+
+// newHTTPRequest returns the view of a net/http request, shared with Gin.
+// Route parameters are left to the caller.
+func newHTTPRequest(r *http.Request, body *bodyReader) *Request {
+	req := &Request{
+		Method: r.Method,
+		Host:   r.Host,
+		Path:   r.URL.Path,
+		Query:  r.URL.RawQuery,
+		Header: r.Header,
+	}
+	if body != nil {
+		req.body = body.capture.body()
+	}
+	return req
+}
+
+// HTTPRecovery recovers from panics in net/http handlers, logs them and
+// answers the standard DL9999 error response, unless the handler already
+// wrote the response headers. http.ErrAbortHandler is re-panicked, so the
+// server aborts the response.
+func HTTPRecovery(stack bool) func(http.Handler) http.Handler {
+	return HTTPRecoveryWithConfig(RecoveryConfig{Stack: stack})
+}
+
+// HTTPRecoveryWithConfig sets up panic recovery based on the provided RecoveryConfig.
+func HTTPRecoveryWithConfig(config RecoveryConfig) func(http.Handler) http.Handler {
+	recoverer := newRecoverer(config)
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			writer := newStatusWriter(w)
+			defer func() {
+				if rec := recover(); rec != nil {
+					if rec == http.ErrAbortHandler {
+						panic(rec)
+					}
+					ctx := r.Context()
+					if recoverer.handle(ctx, rec, xerrors.FromRecover(rec), r.Method, r.URL.String()) {
+						// If the connection is dead, we can't write a status to it.
+						return
+					}
+					// The handler already answered part of the response, a
+					// status and envelope cannot follow it.
+					if writer.status != 0 {
+						return
+					}
+					w.Header().Set("Content-Type", "application/json")
+					w.WriteHeader(http.StatusInternalServerError)
+					json.NewEncoder(w).Encode(newPanicResponse(ctx)) //nolint:errcheck
+				}
+			}()
+			next.ServeHTTP(writer, r)
+		})
+	}
+}
@@ -0,0 +1,40 @@
+package middleware_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"deeplink-bff/middleware"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHTTPLoggerDefaultStatus(t *testing.T) {
+	records := captureLogs(t)
+	handler := middleware.HTTPLogger()(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte("ok"))
+	}))
+	serveHTTP(handler, httptest.NewRequest(http.MethodGet, "/version", nil))
+
+	logs := records()
+	require.Len(t, logs, 1)
+	assert.EqualValues(t, http.StatusOK, lookup(logs[0], "response.status"))
+	assert.Equal(t, "/version", lookup(logs[0], "request.path"))
+	assert.Nil(t, lookup(logs[0], "request.params"))
+	assert.Equal(t, "ok", lookup(logs[0], "response.body"))
+}
+
+func TestHTTPLoggerFlush(t *testing.T) {
+	records := captureLogs(t)
+	handler := middleware.HTTPLogger()(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte("event"))
+		// the wrapped writer must still expose the optional interfaces
+		assert.NoError(t, http.NewResponseController(w).Flush())
+	}))
+	rec := serveHTTP(handler, httptest.NewRequest(http.MethodGet, "/events", nil))
+
+	assert.True(t, rec.Flushed)
+	assert.Equal(t, "event", lookup(records()[0], "response.body"))
+}
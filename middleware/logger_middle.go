@@ -1,22 +1,23 @@
 package middleware
 
 import (
+	"context"
 	"deeplink-bff/pkg/logx"
 	"log/slog"
-	"path/filepath"
-	"runtime"
+	"sync"
 
 	"net/http"
 	"strings"
 
-	"github.com/gofiber/fiber/v2"
 	"github.com/google/uuid"
 	"go.opentelemetry.io/otel/trace"
 )
 
+type ctxKey string
+
 const (
-	customAttributesCtxKey = "slog-fiber.custom-attributes"
-	requestIDCtx           = "slog-fiber.request-id"
+	requestStateCtxKey ctxKey = "middleware.request-state"
+	requestIDCtxKey    ctxKey = "middleware.request-id"
 )
 
 var (
@@ -41,9 +42,14 @@ var (
 
 	// Formatted with http.CanonicalHeaderKey
 	RequestIDHeaderKey = "X-Request-Id"
+
+	// RequestLogMessage is the message of request logs below the client
+	// error status. It is the same for every route.
+	RequestLogMessage = "Incoming request"
 )
 
-// Config defines logging behavior for the middleware
+// Config defines logging behavior for the middleware. It is shared by the
+// Fiber, Gin and net/http adapters.
 type Config struct {
 	DefaultLevel     slog.Level // Level for successful requests
 	ClientErrorLevel slog.Level // Level for 4xx responses
@@ -63,11 +69,14 @@ type Config struct {
 	WithResponseHeader bool
 	// AWS Group
 	WithXRay bool
+
+	// Filters decide which requests are logged, see Filter
+	Filters []Filter
 }
 
-// Logger returns a Fiber middleware with default configuration.
-func Logger() fiber.Handler {
-	return LoggerWithConfig(Config{
+// defaultConfig is the configuration of Logger, GinLogger and HTTPLogger.
+func defaultConfig(filters []Filter) Config {
+	return Config{
 		DefaultLevel:     slog.LevelInfo,
 		ClientErrorLevel: slog.LevelWarn,
 		ServerErrorLevel: slog.LevelError,
@@ -81,259 +90,270 @@ func Logger() fiber.Handler {
 		WithResponseBody:   true,
 		WithResponseHeader: false,
 		WithXRay:           false,
-	})
-}
 
-// LoggerWithConfig sets up request logging based on the provided Config.
-func LoggerWithConfig(config Config) fiber.Handler {
-	return func(c *fiber.Ctx) error {
-
-		attributes := []slog.Attr{}
+		Filters: filters,
+	}
+}
 
-		// ---------- 1. Base Group ----------
+// Request is the framework-neutral view of a request, filled in by the
+// adapters after the handler returned.
+type Request struct {
+	Method string
+	Host   string
+	Path   string
+	// Query is the raw query string
+	Query string
+	// Params holds the route parameters, if the framework has any
+	Params map[string]string
+	Header http.Header
+
+	body capturedBody
+}
 
-		// user-agent
-		if config.WithUserAgent {
-			userAgent := c.Get(fiber.HeaderUserAgent)
-			attributes = append(attributes, slog.String("user-agent", userAgent))
-		}
+// Response is the framework-neutral view of a response.
+type Response struct {
+	Status int
+	Header http.Header
+	// Length is the size of the body in bytes
+	Length int
 
-		// ---------- 2. Trace Group ----------
+	body capturedBody
+}
 
-		ctx := c.UserContext()
+// requestLogger emits the request logs of one Config. The adapters capture
+// the request and response and leave logging to it.
+type requestLogger struct {
+	config Config
+}
 
-		// request_id
-		if config.WithRequestID {
-			requestID := c.Get(RequestIDHeaderKey)
-			if requestID == "" {
-				requestID = uuid.New().String()
-				c.Set(RequestIDHeaderKey, requestID) // Set for response header
-			}
-			c.Locals(requestIDCtx, requestID)
+func newRequestLogger(config Config) *requestLogger {
+	return &requestLogger{config: config}
+}
 
-			// Add request_id to logx context
-			ctx = logx.AppendCtx(ctx, slog.String(RequestIDKey, requestID))
+// start runs before the handler. It returns ctx with the request ID, trace
+// and span IDs for every log of the request, and the request ID to send back,
+// which is requestID if the client sent one.
+func (l *requestLogger) start(ctx context.Context, requestID string) (context.Context, string) {
+	// ---------- 1. Trace Group ----------
+
+	// request_id
+	if l.config.WithRequestID {
+		if requestID == "" {
+			requestID = uuid.New().String()
+		} else {
+			// A header value may share a buffer the server reuses, and the
+			// ID outlives the request in async log records
+			requestID = strings.Clone(requestID)
 		}
+		ctx = context.WithValue(ctx, requestIDCtxKey, requestID)
 
-		// trace_id + span_id
-		if config.WithTraceID || config.WithSpanID {
-			spanCtx := trace.SpanContextFromContext(ctx)
-
-			if config.WithTraceID && spanCtx.HasTraceID() {
-				traceID := spanCtx.TraceID().String()
-				ctx = logx.AppendCtx(ctx, slog.String("trace_id", traceID))
-			}
-			if config.WithSpanID && spanCtx.HasSpanID() {
-				spanID := spanCtx.SpanID().String()
-				ctx = logx.AppendCtx(ctx, slog.String("span_id", spanID))
-			}
-		}
+		// Add request_id to logx context
+		ctx = logx.AppendCtx(ctx, slog.String(RequestIDKey, requestID))
+	} else {
+		requestID = ""
+	}
 
-		c.SetUserContext(ctx)
+	// trace_id + span_id
+	if l.config.WithTraceID || l.config.WithSpanID {
+		spanCtx := trace.SpanContextFromContext(ctx)
 
-		// Call the next handler in the middleware chain and capture any error after setting up context but before logging
-		err := c.Next()
+		if l.config.WithTraceID && spanCtx.HasTraceID() {
+			traceID := spanCtx.TraceID().String()
+			ctx = logx.AppendCtx(ctx, slog.String(TraceIDKey, traceID))
+		}
+		if l.config.WithSpanID && spanCtx.HasSpanID() {
+			spanID := spanCtx.SpanID().String()
+			ctx = logx.AppendCtx(ctx, slog.String(SpanIDKey, spanID))
+		}
+	}
 
-		// ---------- 3. Request Group ----------
+	ctx = context.WithValue(ctx, requestStateCtxKey, &requestState{})
+	return ctx, requestID
+}
 
-		requestAttributes := []slog.Attr{}
+// log emits the request log, unless a filter rejects the request. err is the
+// error returned by the handler, if the framework has one.
+func (l *requestLogger) log(ctx context.Context, req *Request, resp *Response, err error) {
+	for _, filter := range l.config.Filters {
+		if !filter(req, resp) {
+			return
+		}
+	}
 
-		// request.method
-		method := c.Method()
-		requestAttributes = append(requestAttributes, slog.String("method", method))
+	state, _ := ctx.Value(requestStateCtxKey).(*requestState)
+	if err == nil && state != nil {
+		err = state.getError()
+	}
 
-		// request.host
-		host := string(c.Context().Host())
-		requestAttributes = append(requestAttributes, slog.String("host", host))
+	attributes := []slog.Attr{}
 
-		// request.path
-		path := c.Path()
-		requestAttributes = append(requestAttributes, slog.String("path", path))
+	// ---------- 2. Base Group ----------
 
-		// request.header
-		if config.WithRequestHeader {
-			kv := []any{}
+	// user-agent
+	if l.config.WithUserAgent {
+		userAgent := req.Header.Get("User-Agent")
+		attributes = append(attributes, slog.String("user-agent", userAgent))
+	}
 
-			for k, v := range c.GetReqHeaders() {
-				if _, found := HiddenRequestHeaders[strings.ToLower(k)]; found {
-					continue
-				}
-				kv = append(kv, slog.Any(k, v))
-			}
-			requestAttributes = append(requestAttributes, slog.Group("header", kv...))
-		}
+	// ---------- 3. Request Group ----------
 
-		// request.query
-		query := string(c.Request().URI().QueryString())
-		requestAttributes = append(requestAttributes, slog.String("query", query))
-
-		// request.param
-		params := c.AllParams()
-		requestAttributes = append(requestAttributes, slog.Any("params", params))
-
-		// request.body
-		if config.WithRequestBody {
-			requestBodyBytes := c.Body()
-			resquestBodyString := string(requestBodyBytes)
-			if len(resquestBodyString) > ResponseBodyMaxSize {
-				resquestBodyString = resquestBodyString[:ResponseBodyMaxSize]
-			}
-			requestAttributes = append(requestAttributes, slog.String("body", resquestBodyString))
-		}
+	requestAttributes := []slog.Attr{
+		slog.String("method", req.Method),
+		slog.String("host", req.Host),
+		slog.String("path", req.Path),
+	}
 
-		// ---------- 4. Response Group ----------
+	// request.header
+	if l.config.WithRequestHeader {
+		requestAttributes = append(requestAttributes, headerAttr(req.Header, HiddenRequestHeaders))
+	}
 
-		responseAttributes := []slog.Attr{}
+	// request.query
+	requestAttributes = append(requestAttributes, slog.Attr{Key: "query", Value: queryValue(req.Query)})
 
-		// response.status
-		status := c.Response().StatusCode()
-		responseAttributes = append(responseAttributes, slog.Int("status", status))
+	// request.param
+	if req.Params != nil {
+		requestAttributes = append(requestAttributes, slog.Any("params", req.Params))
+	}
 
-		// response.header
-		if config.WithResponseHeader {
-			kv := []any{}
-			for k, v := range c.GetRespHeaders() {
-				if _, found := HiddenResponseHeaders[strings.ToLower(k)]; found {
-					continue
-				}
-				kv = append(kv, slog.Any(k, v))
-			}
+	// request.body
+	if l.config.WithRequestBody {
+		body := bodyValue(req.Header.Get("Content-Type"), req.body, RequestBodyMaxSize)
+		requestAttributes = append(requestAttributes, slog.Attr{Key: "body", Value: body})
+	}
 
-			responseAttributes = append(responseAttributes, slog.Group("header", kv...))
-		}
+	// ---------- 4. Response Group ----------
 
-		responseBodyBytes := c.Response().Body()
+	status := resp.Status
+	responseAttributes := []slog.Attr{slog.Int("status", status)}
 
-		// response.body
-		if config.WithResponseBody || status >= http.StatusBadRequest {
-			responseBodyString := string(responseBodyBytes)
-			if len(responseBodyString) > ResponseBodyMaxSize {
-				responseBodyString = responseBodyString[:ResponseBodyMaxSize]
-			}
-			responseAttributes = append(responseAttributes, slog.String("body", responseBodyString))
-		}
+	// response.header
+	if l.config.WithResponseHeader {
+		responseAttributes = append(responseAttributes, headerAttr(resp.Header, HiddenResponseHeaders))
+	}
 
-		// response.length
-		responseBodyLength := len(responseBodyBytes)
-		responseAttributes = append(responseAttributes, slog.Int("length", responseBodyLength))
+	// response.body
+	if l.config.WithResponseBody || status >= http.StatusBadRequest {
+		body := bodyValue(resp.Header.Get("Content-Type"), resp.body, ResponseBodyMaxSize)
+		responseAttributes = append(responseAttributes, slog.Attr{Key: "body", Value: body})
+	}
 
-		// response.stacktrace
-		if status >= http.StatusBadRequest {
-			responseAttributes = append(responseAttributes, slog.Any("stacktrace", marshalStack(0)))
-		}
+	// response.length
+	responseAttributes = append(responseAttributes, slog.Int("length", resp.Length))
 
-		attributes = append(
-			attributes,
-			slog.Attr{
-				Key:   "request",
-				Value: slog.GroupValue(requestAttributes...),
-			},
-			slog.Attr{
-				Key:   "response",
-				Value: slog.GroupValue(responseAttributes...),
-			},
-		)
-
-		// custom context values
-		if v, ok := c.Locals(customAttributesCtxKey).([]slog.Attr); ok {
-			attributes = append(attributes, v...)
+	// response.stacktrace, where the error or panic originated
+	if status >= http.StatusInternalServerError {
+		if stack := errorStack(err); len(stack) > 0 {
+			responseAttributes = append(responseAttributes, slog.Any("stacktrace", stack))
 		}
+	}
 
-		// ---------- Log Message ----------
-
-		level := config.DefaultLevel
-		msg := "Incoming request"
-		if status >= http.StatusInternalServerError {
-			level = config.ServerErrorLevel
-			if err != nil {
-				msg = extractErrorMessage(err)
-			} else {
-				msg = http.StatusText(status)
-			}
-		} else if status >= http.StatusBadRequest {
-			level = config.ClientErrorLevel
-			if err != nil {
-				msg = extractErrorMessage(err)
-			} else {
-				msg = http.StatusText(status)
-			}
-		}
+	attributes = append(
+		attributes,
+		slog.Attr{
+			Key:   "request",
+			Value: slog.GroupValue(requestAttributes...),
+		},
+		slog.Attr{
+			Key:   "response",
+			Value: slog.GroupValue(responseAttributes...),
+		},
+	)
+
+	// custom context values
+	if state != nil {
+		attributes = append(attributes, state.getAttrs()...)
+	}
 
-		slog.LogAttrs(c.UserContext(), level, msg, attributes...)
+	// ---------- Log Message ----------
 
-		return err // Important to return the error from c.Next()
+	level := l.config.DefaultLevel
+	msg := RequestLogMessage
+	if status >= http.StatusInternalServerError {
+		level = l.config.ServerErrorLevel
+		msg = statusMessage(status, err)
+	} else if status >= http.StatusBadRequest {
+		level = l.config.ClientErrorLevel
+		msg = statusMessage(status, err)
 	}
+
+	slog.LogAttrs(ctx, level, msg, attributes...)
 }
 
-// GetRequestID returns the request identifier.
-func GetRequestID(c *fiber.Ctx) string {
-	requestIDVal := c.Locals(requestIDCtx)
-	if requestIDVal == nil {
-		return ""
-	}
-	if id, ok := requestIDVal.(string); ok {
-		return id
+// headerAttr returns the header group without the hidden headers.
+func headerAttr(header http.Header, hidden map[string]struct{}) slog.Attr {
+	kv := []any{}
+	for k, v := range header {
+		if _, found := hidden[strings.ToLower(k)]; found {
+			continue
+		}
+		kv = append(kv, slog.Any(k, v))
 	}
-
-	return ""
+	return slog.Group("header", kv...)
 }
 
-// AddCustomAttributes adds custom attributes to the request context.
-func AddCustomAttributes(c *fiber.Ctx, attr slog.Attr) {
-	currentAttrsVal := c.Locals(customAttributesCtxKey)
-	if currentAttrsVal == nil {
-		c.Locals(customAttributesCtxKey, []slog.Attr{attr})
-		return
+// statusMessage returns the message of an error response: the error the
+// request failed with, e.g. the message of a *fiber.Error, or the status text.
+func statusMessage(status int, err error) string {
+	if err != nil {
+		return err.Error()
 	}
+	return http.StatusText(status)
+}
 
-	switch attrs := currentAttrsVal.(type) {
-	case []slog.Attr:
-		c.Locals(customAttributesCtxKey, append(attrs, attr))
-	}
+// RequestIDFromContext returns the request identifier set by the logger of
+// any adapter in the request context.
+func RequestIDFromContext(ctx context.Context) string {
+	requestID, _ := ctx.Value(requestIDCtxKey).(string)
+	return requestID
 }
 
-type stackFrame struct {
-	Func     string `json:"func"`
-	Source   string `json:"source"`
-	Line     int    `json:"line"`
-	FullPath string `json:"full_path,omitempty"`
+// requestState collects what handlers add to the request log: custom
+// attributes and the error the request failed with.
+type requestState struct {
+	mu    sync.Mutex
+	attrs []slog.Attr
+	err   error
 }
 
-// marshalStack captures the current call stack and parses it into structured frames
-func marshalStack(skip int) []stackFrame {
-	const depth = 32
-	pc := make([]uintptr, depth)
-	n := runtime.Callers(skip, pc)
-	if n == 0 {
-		return nil
-	}
+func (s *requestState) addAttrs(attrs ...slog.Attr) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.attrs = append(s.attrs, attrs...)
+}
 
-	frames := runtime.CallersFrames(pc[:n])
-	var s []stackFrame
+func (s *requestState) getAttrs() []slog.Attr {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.attrs
+}
 
-	for {
-		frame, more := frames.Next()
-		if frame.Function == "" {
-			break
-		}
+func (s *requestState) setError(err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.err = err
+}
 
-		s = append(s, stackFrame{
-			Func:     filepath.Base(frame.Function),
-			Source:   filepath.Join(filepath.Base(filepath.Dir(frame.File)), filepath.Base(frame.File)),
-			Line:     frame.Line,
-			FullPath: frame.File,
-		})
+func (s *requestState) getError() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.err
+}
 
-		if !more {
-			break
-		}
+// AddContextAttributes adds attributes to the request log of the request
+// ctx belongs to. It does nothing outside a request logged by the middleware.
+func AddContextAttributes(ctx context.Context, attrs ...slog.Attr) {
+	if state, ok := ctx.Value(requestStateCtxKey).(*requestState); ok {
+		state.addAttrs(attrs...)
 	}
-	return s
 }
 
-func extractErrorMessage(err error) string {
-	if fe, ok := err.(*fiber.Error); ok {
-		return fe.Message
+// RecordError records the error the request ctx belongs to failed with, for
+// handlers that write the error response themselves instead of returning the
+// error. The request log takes its message from err and, for 5xx responses,
+// its stack trace from the one err carries, see errorStack.
+func RecordError(ctx context.Context, err error) {
+	if state, ok := ctx.Value(requestStateCtxKey).(*requestState); ok {
+		state.setError(err)
 	}
-	return err.Error()
 }
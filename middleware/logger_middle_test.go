@@ -0,0 +1,275 @@
+package middleware_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"deeplink-bff/middleware"
+	"deeplink-bff/pkg/logx"
+
+	"github.com/mdobak/go-xerrors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// captureLogs sends the default logger to a buffer for the test and returns
+// a function decoding the records written so far.
+func captureLogs(t *testing.T) func() []map[string]any {
+	t.Helper()
+	buf := &bytes.Buffer{}
+	logger, err := logx.New(logx.Config{}, logx.WithWriter(buf), logx.WithRuntimeInfo(false))
+	require.NoError(t, err)
+
+	previous := slog.Default()
+	slog.SetDefault(logger)
+	t.Cleanup(func() { slog.SetDefault(previous) })
+
+	return func() []map[string]any {
+		var records []map[string]any
+		for _, line := range strings.Split(strings.TrimSpace(buf.String()), "\n") {
+			if line == "" {
+				continue
+			}
+			record := map[string]any{}
+			require.NoError(t, json.Unmarshal([]byte(line), &record), line)
+			records = append(records, record)
+		}
+		return records
+	}
+}
+
+// lookup returns the value at a dotted path of a decoded record.
+func lookup(record map[string]any, path string) any {
+	var value any = record
+	for _, key := range strings.Split(path, ".") {
+		group, ok := value.(map[string]any)
+		if !ok {
+			return nil
+		}
+		value = group[key]
+	}
+	return value
+}
+
+func serveHTTP(handler http.Handler, req *http.Request) *httptest.ResponseRecorder {
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+	return rec
+}
+
+func TestLoggerLevels(t *testing.T) {
+	tests := []struct {
+		name    string
+		handler http.HandlerFunc
+		status  int
+		level   string
+		msg     string
+	}{
+		{
+			name:    "success",
+			handler: func(w http.ResponseWriter, r *http.Request) { _, _ = w.Write([]byte("ok")) },
+			status:  http.StatusOK,
+			level:   "INFO",
+			msg:     "Incoming request",
+		},
+		{
+			name:    "client error",
+			handler: func(w http.ResponseWriter, r *http.Request) { w.WriteHeader(http.StatusNotFound) },
+			status:  http.StatusNotFound,
+			level:   "WARN",
+			msg:     "Not Found",
+		},
+		{
+			name: "recorded client error",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				middleware.RecordError(r.Context(), errors.New("invalid deeplink id"))
+				w.WriteHeader(http.StatusBadRequest)
+			},
+			status: http.StatusBadRequest,
+			level:  "WARN",
+			msg:    "invalid deeplink id",
+		},
+		{
+			name:    "server error",
+			handler: func(w http.ResponseWriter, r *http.Request) { w.WriteHeader(http.StatusBadGateway) },
+			status:  http.StatusBadGateway,
+			level:   "ERROR",
+			msg:     "Bad Gateway",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			records := captureLogs(t)
+			serveHTTP(middleware.HTTPLogger()(tt.handler), httptest.NewRequest(http.MethodGet, "/", nil))
+
+			logs := records()
+			require.Len(t, logs, 1)
+			assert.Equal(t, tt.level, logs[0]["level"])
+			assert.Equal(t, tt.msg, logs[0]["msg"])
+			assert.EqualValues(t, tt.status, lookup(logs[0], "response.status"))
+		})
+	}
+}
+
+func TestLoggerStacktrace(t *testing.T) {
+	records := captureLogs(t)
+	handler := middleware.HTTPLogger()(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		middleware.RecordError(r.Context(), xerrors.New("database unavailable"))
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	serveHTTP(handler, httptest.NewRequest(http.MethodGet, "/", nil))
+
+	logs := records()
+	require.Len(t, logs, 1)
+	assert.Equal(t, "database unavailable", logs[0]["msg"])
+	stack, ok := lookup(logs[0], "response.stacktrace").([]any)
+	require.True(t, ok, "stacktrace is logged")
+	require.NotEmpty(t, stack)
+	frame := stack[0].(map[string]any)
+	assert.Contains(t, frame["func"], "middleware_test.TestLoggerStacktrace")
+	assert.Equal(t, "middleware/logger_middle_test.go", frame["source"])
+	assert.Nil(t, frame["full_path"])
+}
+
+func TestLoggerStacktraceFullPath(t *testing.T) {
+	middleware.StackFullPath = true
+	t.Cleanup(func() { middleware.StackFullPath = false })
+
+	records := captureLogs(t)
+	handler := middleware.HTTPLogger()(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		middleware.RecordError(r.Context(), xerrors.New("database unavailable"))
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	serveHTTP(handler, httptest.NewRequest(http.MethodGet, "/", nil))
+
+	frame := lookup(records()[0], "response.stacktrace").([]any)[0].(map[string]any)
+	assert.Contains(t, frame["full_path"], "middleware/logger_middle_test.go")
+}
+
+func TestLoggerNoStacktraceWithoutOrigin(t *testing.T) {
+	records := captureLogs(t)
+	handler := middleware.HTTPLogger()(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		middleware.RecordError(r.Context(), errors.New("plain error"))
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	serveHTTP(handler, httptest.NewRequest(http.MethodGet, "/", nil))
+
+	logs := records()
+	require.Len(t, logs, 1)
+	assert.Equal(t, "plain error", logs[0]["msg"])
+	assert.Nil(t, lookup(logs[0], "response.stacktrace"))
+}
+
+func TestLoggerRequestID(t *testing.T) {
+	var fromContext string
+	handler := middleware.HTTPLogger()(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fromContext = middleware.RequestIDFromContext(r.Context())
+	}))
+
+	t.Run("echoed", func(t *testing.T) {
+		records := captureLogs(t)
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		req.Header.Set(middleware.RequestIDHeaderKey, "req-1")
+		rec := serveHTTP(handler, req)
+
+		assert.Equal(t, "req-1", rec.Header().Get(middleware.RequestIDHeaderKey))
+		assert.Equal(t, "req-1", fromContext)
+		assert.Equal(t, "req-1", records()[0][middleware.RequestIDKey])
+	})
+
+	t.Run("generated", func(t *testing.T) {
+		records := captureLogs(t)
+		rec := serveHTTP(handler, httptest.NewRequest(http.MethodGet, "/", nil))
+
+		requestID := rec.Header().Get(middleware.RequestIDHeaderKey)
+		assert.NotEmpty(t, requestID)
+		assert.Equal(t, requestID, fromContext)
+		assert.Equal(t, requestID, records()[0][middleware.RequestIDKey])
+	})
+}
+
+func TestLoggerRedaction(t *testing.T) {
+	records := captureLogs(t)
+	handler := middleware.HTTPLogger()(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// the request body is captured as the handler reads it
+		_, _ = io.ReadAll(r.Body)
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"token":"t-1","id":"abc"}`))
+	}))
+	req := httptest.NewRequest(http.MethodPost, "/login?password=p-1&page=2", strings.NewReader(`{"password":"p-2","user":"john"}`))
+	req.Header.Set("Content-Type", "application/json")
+	serveHTTP(handler, req)
+
+	logs := records()
+	require.Len(t, logs, 1)
+	assert.Equal(t, logx.DefaultRedactMessage, lookup(logs[0], "request.query.password"))
+	assert.Equal(t, "2", lookup(logs[0], "request.query.page"))
+	assert.Equal(t, logx.DefaultRedactMessage, lookup(logs[0], "request.body.password"))
+	assert.Equal(t, "john", lookup(logs[0], "request.body.user"))
+	assert.Equal(t, logx.DefaultRedactMessage, lookup(logs[0], "response.body.token"))
+	assert.Equal(t, "abc", lookup(logs[0], "response.body.id"))
+}
+
+func TestLoggerBinaryBody(t *testing.T) {
+	records := captureLogs(t)
+	handler := middleware.HTTPLogger()(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "image/png")
+		_, _ = w.Write([]byte{0x89, 'P', 'N', 'G'})
+	}))
+	serveHTTP(handler, httptest.NewRequest(http.MethodGet, "/qr.png", nil))
+
+	logs := records()
+	require.Len(t, logs, 1)
+	assert.EqualValues(t, 4, lookup(logs[0], "response.body.size"))
+	assert.Len(t, lookup(logs[0], "response.body.sha256"), 64)
+	assert.EqualValues(t, 4, lookup(logs[0], "response.length"))
+}
+
+func TestLoggerHiddenHeaders(t *testing.T) {
+	records := captureLogs(t)
+	config := middleware.Config{WithRequestHeader: true, WithResponseHeader: true}
+	handler := middleware.HTTPLoggerWithConfig(config)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Set-Cookie", "session=s-1")
+		w.Header().Set("X-Served-By", "test")
+	}))
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.Header.Set("Authorization", "Bearer b-1")
+	req.Header.Set("Accept", "text/plain")
+	serveHTTP(handler, req)
+
+	logs := records()
+	require.Len(t, logs, 1)
+	assert.Nil(t, lookup(logs[0], "request.header.Authorization"))
+	assert.NotNil(t, lookup(logs[0], "request.header.Accept"))
+	assert.Nil(t, lookup(logs[0], "response.header.Set-Cookie"))
+	assert.NotNil(t, lookup(logs[0], "response.header.X-Served-By"))
+}
+
+func TestLoggerFilters(t *testing.T) {
+	records := captureLogs(t)
+	handler := middleware.HTTPLogger(middleware.IgnorePath("/health"))(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+
+	serveHTTP(handler, httptest.NewRequest(http.MethodGet, "/health", nil))
+	assert.Empty(t, records())
+
+	serveHTTP(handler, httptest.NewRequest(http.MethodGet, "/api", nil))
+	assert.Len(t, records(), 1)
+}
+
+func TestLoggerContextAttributes(t *testing.T) {
+	records := captureLogs(t)
+	handler := middleware.HTTPLogger()(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		middleware.AddContextAttributes(r.Context(), slog.String("partner", "acme"))
+	}))
+	serveHTTP(handler, httptest.NewRequest(http.MethodGet, "/", nil))
+
+	assert.Equal(t, "acme", records()[0]["partner"])
+}
@@ -1,64 +1,94 @@
 package middleware
 
 import (
-	"fmt"
+	"context"
+	"deeplink-bff/constant"
+	"errors"
 	"log/slog"
-	"net"
-	"net/http"
-	"os"
-	"runtime/debug"
-	"strings"
-
-	"github.com/gofiber/fiber/v2"
+	"syscall"
 )
 
-// Recovery recovers from panics and logs them.
-func Recovery(stack bool) fiber.Handler {
-	return func(c *fiber.Ctx) error {
-		defer func() {
-			if err := recover(); err != nil {
-				// Check for a broken connection, as it is not really a
-				// condition that warrants a panic stack trace.
-				var brokenPipe bool
-				if ne, ok := err.(*net.OpError); ok {
-					if se, ok := ne.Err.(*os.SyscallError); ok {
-						if strings.Contains(strings.ToLower(se.Error()), "broken pipe") || strings.Contains(strings.ToLower(se.Error()), "connection reset by peer") {
-							brokenPipe = true
-						}
-					}
-				}
+// RecoveryConfig defines the behavior of the recovery middleware. It is
+// shared by the Fiber, Gin and net/http adapters.
+type RecoveryConfig struct {
+	// Stack adds the stack trace of the panic to the panic log
+	Stack bool
+	// OnPanic is called after a panic is logged, e.g. to send an alert. err
+	// carries the panic value and the stack trace of the panic. It is not
+	// called for broken connections.
+	OnPanic func(ctx context.Context, err error)
+}
+
+// panicResponse is the standard error envelope answered for a panic.
+type panicResponse struct {
+	Code      string `json:"code"`
+	Message   string `json:"message"`
+	RequestID string `json:"request_id,omitempty"`
+}
+
+func newPanicResponse(ctx context.Context) panicResponse {
+	return panicResponse{
+		Code:      constant.CodeInternal.String(),
+		Message:   "internal server error",
+		RequestID: RequestIDFromContext(ctx),
+	}
+}
+
+// recoverer handles the panics recovered by the adapters, which only write
+// the response.
+type recoverer struct {
+	config RecoveryConfig
+}
+
+func newRecoverer(config RecoveryConfig) *recoverer {
+	return &recoverer{config: config}
+}
 
-				// Simplified request dump for Fiber
-				// For more complex dumping, consider c.Request().Header.String() and c.Body() if needed
-				requestDump := fmt.Sprintf("%s %s", c.Method(), c.OriginalURL())
+// handle logs a panic recovered while serving method and url, records it for
+// the request log and calls the panic hook. rec is the recovered value and
+// err the same value converted by xerrors.FromRecover, which must be called
+// where the panic is recovered. handle reports whether the panic was caused
+// by a broken connection, in which case no response can be written.
+func (r *recoverer) handle(ctx context.Context, rec any, err error, method, url string) (brokenPipe bool) {
+	// Simplified request dump
+	requestDump := method + " " + url
 
-				if brokenPipe {
-					slog.Error( // Use ErrorCtx to include context attributes like request_id
-						c.Path(), // Use c.Path() for Fiber
-						slog.Any("error", err),
-						slog.String("request", requestDump),
-					)
-					// If the connection is dead, we can't write a status to it.
-					// No explicit abort needed in Fiber for broken pipe, just return.
-					// The error won't be sent back to client as connection is broken.
-					return
-				}
+	// A broken connection is not really a condition that warrants a panic
+	// stack trace.
+	if isBrokenPipe(rec) {
+		slog.WarnContext(ctx, "Broken connection",
+			slog.Any("error", rec),
+			slog.String("request", requestDump),
+		)
+		return true
+	}
 
-				logArgs := []any{
-					slog.Any("error", err),
-					slog.String("request", requestDump),
-				}
-				if stack {
-					logArgs = append(logArgs, slog.String("stack", string(debug.Stack())))
-				}
-				slog.Error("[PANIC RECOVER]", logArgs...)
+	// the stack of the request log is where the panic happened
+	RecordError(ctx, err)
+
+	logAttrs := []slog.Attr{
+		slog.Any("error", rec),
+		slog.String("request", requestDump),
+	}
+	if r.config.Stack {
+		logAttrs = append(logAttrs, slog.Any("stack", errorStack(err)))
+	}
+	slog.LogAttrs(ctx, slog.LevelError, "[PANIC RECOVER]", logAttrs...)
+
+	if r.config.OnPanic != nil {
+		r.config.OnPanic(ctx, err)
+	}
+	return false
+}
 
-				// If response has not been sent, send a 500 error.
-				// Fiber's default error handler might also catch this panic if not "handled" here.
-				// By sending a response, we effectively handle it.
-				c.Status(http.StatusInternalServerError)
-			}
-		}()
-		return c.Next() // Call the next handler in the chain.
+// isBrokenPipe reports whether a panic was caused by a client that closed the
+// connection: a net/http or Gin handler panicking with the error of a write
+// to it. Fiber handlers never see one, fasthttp writes the response after
+// the handler returned.
+func isBrokenPipe(rec any) bool {
+	err, ok := rec.(error)
+	if !ok {
+		return false
 	}
+	return errors.Is(err, syscall.EPIPE) || errors.Is(err, syscall.ECONNRESET)
 }
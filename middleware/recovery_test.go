@@ -0,0 +1,206 @@
+package middleware_test
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"syscall"
+	"testing"
+	"time"
+
+	"deeplink-bff/middleware"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type envelope struct {
+	Code      string `json:"code"`
+	Message   string `json:"message"`
+	RequestID string `json:"request_id"`
+}
+
+// panicHook records the errors OnPanic is called with.
+type panicHook struct {
+	errs []error
+}
+
+func (h *panicHook) config() middleware.RecoveryConfig {
+	return middleware.RecoveryConfig{
+		Stack:   true,
+		OnPanic: func(ctx context.Context, err error) { h.errs = append(h.errs, err) },
+	}
+}
+
+func assertEnvelope(t *testing.T, status int, header http.Header, body []byte) {
+	t.Helper()
+	assert.Equal(t, http.StatusInternalServerError, status)
+	var resp envelope
+	require.NoError(t, json.Unmarshal(body, &resp), string(body))
+	assert.Equal(t, "DL9999", resp.Code)
+	assert.Equal(t, "internal server error", resp.Message)
+	assert.NotEmpty(t, resp.RequestID)
+	assert.Equal(t, header.Get(middleware.RequestIDHeaderKey), resp.RequestID)
+}
+
+// assertPanicLogs checks the panic log and the request log of a recovered panic.
+func assertPanicLogs(t *testing.T, logs []map[string]any) {
+	t.Helper()
+	require.Len(t, logs, 2)
+	assert.Equal(t, "[PANIC RECOVER]", logs[0]["msg"])
+	assert.NotEmpty(t, logs[0]["stack"])
+	assert.Equal(t, "ERROR", logs[1]["level"])
+	assert.EqualValues(t, http.StatusInternalServerError, lookup(logs[1], "response.status"))
+	stack, ok := lookup(logs[1], "response.stacktrace").([]any)
+	require.True(t, ok, "the request log has the stack of the panic")
+	assert.Contains(t, stack[0].(map[string]any)["source"], "recovery_test.go")
+	assert.Equal(t, logs[0][middleware.RequestIDKey], logs[1][middleware.RequestIDKey])
+}
+
+var errBrokenPipe = fmt.Errorf("write tcp: %w", syscall.EPIPE)
+
+func TestFiberRecovery(t *testing.T) {
+	records := captureLogs(t)
+	hook := &panicHook{}
+	app := fiber.New()
+	app.Use(middleware.Logger(), middleware.RecoveryWithConfig(hook.config()))
+	app.Get("/panic", func(c *fiber.Ctx) error { panic("boom") })
+	app.Get("/broken", func(c *fiber.Ctx) error { panic(errBrokenPipe) })
+
+	resp, err := app.Test(httptest.NewRequest(http.MethodGet, "/panic", nil))
+	require.NoError(t, err)
+	body, err := io.ReadAll(resp.Body)
+	require.NoError(t, err)
+	assertEnvelope(t, resp.StatusCode, resp.Header, body)
+	require.Len(t, hook.errs, 1)
+	assert.ErrorContains(t, hook.errs[0], "boom")
+	assertPanicLogs(t, records())
+
+	_, err = app.Test(httptest.NewRequest(http.MethodGet, "/broken", nil))
+	require.NoError(t, err)
+	assert.Len(t, hook.errs, 1, "OnPanic is not called for broken connections")
+}
+
+func TestGinRecovery(t *testing.T) {
+	records := captureLogs(t)
+	hook := &panicHook{}
+	router := gin.New()
+	router.Use(middleware.GinLogger(), middleware.GinRecoveryWithConfig(hook.config()))
+	router.GET("/panic", func(c *gin.Context) { panic("boom") })
+	router.GET("/broken", func(c *gin.Context) { panic(errBrokenPipe) })
+	router.GET("/written", func(c *gin.Context) {
+		c.String(http.StatusOK, "partial")
+		panic("boom")
+	})
+
+	rec := serveHTTP(router, httptest.NewRequest(http.MethodGet, "/panic", nil))
+	assertEnvelope(t, rec.Code, rec.Header(), rec.Body.Bytes())
+	require.Len(t, hook.errs, 1)
+	assert.ErrorContains(t, hook.errs[0], "boom")
+	assertPanicLogs(t, records())
+
+	serveHTTP(router, httptest.NewRequest(http.MethodGet, "/broken", nil))
+	assert.Len(t, hook.errs, 1, "OnPanic is not called for broken connections")
+
+	rec = serveHTTP(router, httptest.NewRequest(http.MethodGet, "/written", nil))
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, "partial", rec.Body.String(), "no envelope after the response headers")
+}
+
+func TestHTTPRecovery(t *testing.T) {
+	records := captureLogs(t)
+	hook := &panicHook{}
+	recovery := middleware.HTTPRecoveryWithConfig(hook.config())
+	handler := middleware.HTTPLogger()(recovery(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		panic("boom")
+	})))
+
+	rec := serveHTTP(handler, httptest.NewRequest(http.MethodGet, "/", nil))
+	assertEnvelope(t, rec.Code, rec.Header(), rec.Body.Bytes())
+	require.Len(t, hook.errs, 1)
+	assert.ErrorContains(t, hook.errs[0], "boom")
+	assertPanicLogs(t, records())
+}
+
+func TestHTTPRecoveryAfterHeaders(t *testing.T) {
+	captureLogs(t)
+	hook := &panicHook{}
+	handler := middleware.HTTPRecoveryWithConfig(hook.config())(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusAccepted)
+		_, _ = w.Write([]byte("partial"))
+		panic("boom")
+	}))
+
+	rec := serveHTTP(handler, httptest.NewRequest(http.MethodGet, "/", nil))
+	assert.Equal(t, http.StatusAccepted, rec.Code)
+	assert.Equal(t, "partial", rec.Body.String(), "no envelope after the response headers")
+	assert.Len(t, hook.errs, 1)
+}
+
+func TestHTTPRecoveryAbortHandler(t *testing.T) {
+	handler := middleware.HTTPRecovery(false)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		panic(http.ErrAbortHandler)
+	}))
+	assert.PanicsWithValue(t, http.ErrAbortHandler, func() {
+		serveHTTP(handler, httptest.NewRequest(http.MethodGet, "/", nil))
+	})
+}
+
+// TestHTTPRecoveryBrokenPipe panics with the error net/http returns when the
+// client closed the connection.
+func TestHTTPRecoveryBrokenPipe(t *testing.T) {
+	records := captureLogs(t)
+	hook := &panicHook{}
+	clientClosed := make(chan struct{})
+	served := make(chan struct{})
+
+	recovery := middleware.HTTPRecoveryWithConfig(hook.config())
+	handler := recovery(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+		http.NewResponseController(w).Flush() //nolint:errcheck
+		<-clientClosed
+
+		chunk := make([]byte, 32<<10)
+		for deadline := time.Now().Add(5 * time.Second); time.Now().Before(deadline); time.Sleep(10 * time.Millisecond) {
+			if _, err := w.Write(chunk); err != nil {
+				panic(err)
+			}
+			http.NewResponseController(w).Flush() //nolint:errcheck
+		}
+	}))
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		defer close(served)
+		handler.ServeHTTP(w, r)
+	}))
+	defer server.Close()
+
+	conn, err := net.Dial("tcp", server.Listener.Addr().String())
+	require.NoError(t, err)
+	_, err = conn.Write([]byte("GET / HTTP/1.1\r\nHost: test\r\n\r\n"))
+	require.NoError(t, err)
+	_, err = http.ReadResponse(bufio.NewReader(conn), nil)
+	require.NoError(t, err)
+	// reset the connection instead of closing it gracefully
+	require.NoError(t, conn.(*net.TCPConn).SetLinger(0))
+	require.NoError(t, conn.Close())
+	close(clientClosed)
+
+	select {
+	case <-served:
+	case <-time.After(10 * time.Second):
+		t.Fatal("the handler did not return")
+	}
+
+	assert.Empty(t, hook.errs, "OnPanic is not called for broken connections")
+	logs := records()
+	require.Len(t, logs, 1)
+	assert.Equal(t, "WARN", logs[0]["level"])
+	assert.Equal(t, "Broken connection", logs[0]["msg"])
+}
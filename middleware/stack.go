@@ -0,0 +1,92 @@
+package middleware
+
+import (
+	"path/filepath"
+	"reflect"
+	"runtime/debug"
+	"strings"
+
+	"github.com/mdobak/go-xerrors"
+)
+
+// StackFullPath adds the absolute file path of each frame to the stack
+// traces of request logs. Enable it only in development: the paths are those
+// of the build machine.
+var StackFullPath = false
+
+type stackFrame struct {
+	Func     string `json:"func"`
+	Source   string `json:"source"`
+	Line     int    `json:"line"`
+	FullPath string `json:"full_path,omitempty"`
+}
+
+var (
+	// appModule is the path of the main module, whose frames are kept
+	appModule = mainModule()
+	// middlewarePackage frames are dropped, they are the same for every request
+	middlewarePackage = reflect.TypeOf(Request{}).PkgPath()
+)
+
+func mainModule() string {
+	if info, ok := debug.ReadBuildInfo(); ok {
+		return info.Main.Path
+	}
+	return ""
+}
+
+// errorStack returns the stack trace carried by err, recorded where the
+// error was created or the panic was recovered, trimmed to the frames of the
+// application. It returns nil when err has no stack trace.
+func errorStack(err error) []stackFrame {
+	trace := xerrors.StackTrace(err)
+	if len(trace) == 0 {
+		return nil
+	}
+
+	var s []stackFrame
+	for _, frame := range trace.Frames() {
+		if !isAppFrame(frame.Function) {
+			continue
+		}
+		f := stackFrame{
+			Func:   filepath.Base(frame.Function),
+			Source: filepath.Join(filepath.Base(filepath.Dir(frame.File)), filepath.Base(frame.File)),
+			Line:   frame.Line,
+		}
+		if StackFullPath {
+			f.FullPath = frame.File
+		}
+		s = append(s, f)
+	}
+	return s
+}
+
+// isAppFrame reports whether function belongs to the application rather than
+// to the runtime, a dependency or this middleware. Without build info, every
+// frame but those of the runtime and this middleware is kept.
+func isAppFrame(function string) bool {
+	pkg := packageOf(function)
+	switch {
+	case pkg == middlewarePackage, pkg == "runtime":
+		return false
+	case pkg == "main":
+		return true
+	case appModule == "":
+		return true
+	default:
+		return pkg == appModule || strings.HasPrefix(pkg, appModule+"/")
+	}
+}
+
+// packageOf returns the package path of a fully qualified function name,
+// e.g. "deeplink-bff/bff/internal/adapters/client" for
+// "deeplink-bff/bff/internal/adapters/client.(*DeeplinkClient).GetDeeplink".
+func packageOf(function string) string {
+	slash := strings.LastIndexByte(function, '/')
+	dot := strings.IndexByte(function[slash+1:], '.')
+	if dot < 0 {
+		return function
+	}
+	return function[:slash+1+dot]
+}
@@ -0,0 +1,36 @@
+package middleware
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPackageOf(t *testing.T) {
+	tests := map[string]string{
+		"deeplink-bff/bff/internal/adapters/client.(*DeeplinkClient).GetDeeplink": "deeplink-bff/bff/internal/adapters/client",
+		"github.com/gofiber/fiber/v2.(*App).next":                                 "github.com/gofiber/fiber/v2",
+		"main.newRouters.func1":                                                   "main",
+		"runtime.gopanic":                                                         "runtime",
+	}
+	for function, want := range tests {
+		assert.Equal(t, want, packageOf(function), function)
+	}
+}
+
+func TestIsAppFrame(t *testing.T) {
+	tests := []struct {
+		function string
+		want     bool
+	}{
+		{"deeplink-bff/bff/internal/adapters/client.(*DeeplinkClient).GetDeeplink", true},
+		{"main.newRouters.func1", true},
+		{"deeplink-bff/middleware.LoggerWithConfig.func1", false},
+		{"runtime.gopanic", false},
+		{"github.com/gofiber/fiber/v2.(*App).next", false},
+		{"deeplink-bffx/other.Func", false},
+	}
+	for _, tt := range tests {
+		assert.Equal(t, tt.want, isAppFrame(tt.function), tt.function)
+	}
+}
@@ -0,0 +1,246 @@
+package logq
+
+import (
+	"fmt"
+	"log/slog"
+	"path"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Query selects records. Zero fields match every record.
+type Query struct {
+	// Level is the minimum level; nil matches every level
+	Level slog.Leveler
+	// Since and Until bound the record time, inclusive
+	Since, Until time.Time
+	RequestID    string
+	// Path matches request.path by prefix, or as a glob when it holds *, ? or [
+	Path   string
+	Status StatusRange
+	// Exprs must all match
+	Exprs []Expr
+}
+
+// Match reports whether r is selected by q.
+func (q Query) Match(r Record) bool {
+	if q.Level != nil && r.Level < q.Level.Level() {
+		return false
+	}
+	if !q.Since.IsZero() && r.Time.Before(q.Since) {
+		return false
+	}
+	if !q.Until.IsZero() && r.Time.After(q.Until) {
+		return false
+	}
+	if q.RequestID != "" && r.RequestID() != q.RequestID {
+		return false
+	}
+	if q.Path != "" && !matchPath(q.Path, r.String("request.path")) {
+		return false
+	}
+	if !q.Status.IsZero() && !q.Status.Contains(r.Status()) {
+		return false
+	}
+	for _, expr := range q.Exprs {
+		if !expr.Match(r) {
+			return false
+		}
+	}
+	return true
+}
+
+func matchPath(pattern, p string) bool {
+	if p == "" {
+		return false
+	}
+	if strings.ContainsAny(pattern, "*?[") {
+		matched, _ := path.Match(pattern, p)
+		return matched
+	}
+	return strings.HasPrefix(p, pattern)
+}
+
+// StatusRange is an inclusive range of HTTP statuses.
+type StatusRange struct {
+	Min, Max int
+}
+
+// ParseStatusRange parses a status ("500"), a class ("5xx") or a range ("400-499").
+func ParseStatusRange(s string) (StatusRange, error) {
+	s = strings.ToLower(strings.TrimSpace(s))
+	if len(s) == 3 && strings.HasSuffix(s, "xx") && s[0] >= '1' && s[0] <= '5' {
+		class := int(s[0]-'0') * 100
+		return StatusRange{Min: class, Max: class + 99}, nil
+	}
+
+	from, to, isRange := strings.Cut(s, "-")
+	if !isRange {
+		to = from
+	}
+	minStatus, err := strconv.Atoi(from)
+	if err != nil {
+		return StatusRange{}, fmt.Errorf("invalid status %q", s)
+	}
+	maxStatus, err := strconv.Atoi(to)
+	if err != nil || maxStatus < minStatus {
+		return StatusRange{}, fmt.Errorf("invalid status %q", s)
+	}
+	return StatusRange{Min: minStatus, Max: maxStatus}, nil
+}
+
+// IsZero reports whether the range is unset.
+func (s StatusRange) IsZero() bool {
+	return s == StatusRange{}
+}
+
+// Contains reports whether status is in the range.
+func (s StatusRange) Contains(status int) bool {
+	return status >= s.Min && status <= s.Max
+}
+
+// ParseTime parses an RFC 3339 time, or a duration such as "15m" meaning
+// that long before now.
+func ParseTime(s string, now time.Time) (time.Time, error) {
+	if d, err := time.ParseDuration(s); err == nil {
+		return now.Add(-d), nil
+	}
+	t, err := time.Parse(time.RFC3339Nano, s)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid time %q: use RFC 3339 or a duration such as 15m", s)
+	}
+	return t, nil
+}
+
+// Operator compares an attribute with the value of an expression.
+type Operator string
+
+// Operators of attribute expressions, see ParseExpr.
+const (
+	OpExists   Operator = ""
+	OpNotExist Operator = "!"
+	OpEqual    Operator = "="
+	OpNotEqual Operator = "!="
+	OpRegexp   Operator = "~"
+	OpLess     Operator = "<"
+	OpLessEq   Operator = "<="
+	OpGreater  Operator = ">"
+	OpGreatEq  Operator = ">="
+)
+
+// operators are ordered so two character operators are found first.
+var operators = []Operator{OpNotEqual, OpLessEq, OpGreatEq, OpEqual, OpRegexp, OpLess, OpGreater}
+
+// cutOperator splits s at its leftmost operator.
+func cutOperator(s string) (key string, op Operator, value string, ok bool) {
+	for i := range s {
+		for _, op := range operators {
+			if strings.HasPrefix(s[i:], string(op)) {
+				return s[:i], op, s[i+len(op):], true
+			}
+		}
+	}
+	return "", "", "", false
+}
+
+// Expr is an attribute expression such as request.method=GET.
+type Expr struct {
+	Key   string
+	Op    Operator
+	Value string
+	re    *regexp.Regexp
+}
+
+// ParseExpr parses an attribute expression. Keys are dotted paths:
+//
+//	error                 the attribute exists
+//	!error                the attribute does not exist
+//	request.method=GET    equal; != is not equal
+//	msg~GetDeeplink       matches the regular expression
+//	response.length>1024  compares numbers; <, <=, > and >= are supported
+func ParseExpr(s string) (Expr, error) {
+	s = strings.TrimSpace(s)
+	if key, ok := strings.CutPrefix(s, "!"); ok && !strings.ContainsAny(key, "=~<>") {
+		return Expr{Key: key, Op: OpNotExist}, validKey(key)
+	}
+
+	key, op, value, ok := cutOperator(s)
+	if !ok {
+		return Expr{Key: s, Op: OpExists}, validKey(s)
+	}
+
+	expr := Expr{Key: strings.TrimSpace(key), Op: op, Value: strings.TrimSpace(value)}
+	if err := validKey(expr.Key); err != nil {
+		return Expr{}, err
+	}
+	switch op {
+	case OpRegexp:
+		re, err := regexp.Compile(expr.Value)
+		if err != nil {
+			return Expr{}, fmt.Errorf("invalid expression %q: %w", s, err)
+		}
+		expr.re = re
+	case OpLess, OpLessEq, OpGreater, OpGreatEq:
+		if _, err := strconv.ParseFloat(expr.Value, 64); err != nil {
+			return Expr{}, fmt.Errorf("invalid expression %q: %s needs a number", s, op)
+		}
+	}
+	return expr, nil
+}
+
+func validKey(key string) error {
+	if key == "" || strings.HasPrefix(key, ".") || strings.HasSuffix(key, ".") {
+		return fmt.Errorf("invalid attribute key %q", key)
+	}
+	return nil
+}
+
+// Match reports whether the attribute of r satisfies the expression.
+func (e Expr) Match(r Record) bool {
+	_, exists := r.Lookup(e.Key)
+	switch e.Op {
+	case OpExists:
+		return exists
+	case OpNotExist:
+		return !exists
+	case OpNotEqual:
+		return !exists || r.String(e.Key) != e.Value
+	}
+	if !exists {
+		return false
+	}
+
+	value := r.String(e.Key)
+	switch e.Op {
+	case OpEqual:
+		return value == e.Value
+	case OpRegexp:
+		return e.re.MatchString(value)
+	}
+
+	got, err := strconv.ParseFloat(value, 64)
+	if err != nil {
+		return false
+	}
+	want, _ := strconv.ParseFloat(e.Value, 64)
+	switch e.Op {
+	case OpLess:
+		return got < want
+	case OpLessEq:
+		return got <= want
+	case OpGreater:
+		return got > want
+	default:
+		return got >= want
+	}
+}
+
+// String returns the expression as it is written.
+func (e Expr) String() string {
+	if e.Op == OpNotExist {
+		return "!" + e.Key
+	}
+	return e.Key + string(e.Op) + e.Value
+}
@@ -0,0 +1,150 @@
+package logq
+
+import (
+	"log/slog"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const requestLog = `{"time":"2025-07-09T17:00:13.813458+07:00","level":"ERROR","msg":"Internal Server Error","source":"deeplink-bff","env":"dev","request_id":"dd806e2f","request":{"method":"GET","host":"localhost:4000","path":"/api/v1/deeplink/4d16"},"response":{"status":500,"length":26}}`
+
+func mustParse(t *testing.T, line string) Record {
+	t.Helper()
+	record, err := Parse([]byte(line))
+	require.NoError(t, err)
+	return record
+}
+
+func TestParse(t *testing.T) {
+	record := mustParse(t, requestLog)
+	assert.Equal(t, slog.LevelError, record.Level)
+	assert.Equal(t, "Internal Server Error", record.Msg)
+	assert.Equal(t, time.Date(2025, 7, 9, 10, 0, 13, 813458000, time.UTC), record.Time.UTC())
+	assert.Equal(t, "dd806e2f", record.RequestID())
+	assert.Equal(t, 500, record.Status())
+	assert.Equal(t, "/api/v1/deeplink/4d16", record.String("request.path"))
+	assert.Equal(t, "26", record.String("response.length"))
+	assert.Empty(t, record.String("request.missing.key"))
+
+	_, err := Parse([]byte(" ┌───────────────┐ "))
+	assert.Error(t, err)
+	_, err = Parse([]byte(`{"time":`))
+	assert.Error(t, err)
+}
+
+func TestQueryMatch(t *testing.T) {
+	record := mustParse(t, requestLog)
+	at := record.Time
+
+	tests := []struct {
+		name  string
+		query func(t *testing.T) Query
+		want  bool
+	}{
+		{"empty", func(*testing.T) Query { return Query{} }, true},
+		{"level below", func(*testing.T) Query { return Query{Level: slog.LevelWarn} }, true},
+		{"level above", func(*testing.T) Query { return Query{Level: slog.LevelError + 4} }, false},
+		{"since", func(*testing.T) Query { return Query{Since: at} }, true},
+		{"since after", func(*testing.T) Query { return Query{Since: at.Add(time.Millisecond)} }, false},
+		{"until before", func(*testing.T) Query { return Query{Until: at.Add(-time.Millisecond)} }, false},
+		{"request id", func(*testing.T) Query { return Query{RequestID: "dd806e2f"} }, true},
+		{"other request id", func(*testing.T) Query { return Query{RequestID: "other"} }, false},
+		{"path prefix", func(*testing.T) Query { return Query{Path: "/api/v1/deeplink"} }, true},
+		{"path glob", func(*testing.T) Query { return Query{Path: "/api/v1/*/4d16"} }, true},
+		{"other path", func(*testing.T) Query { return Query{Path: "/s/"} }, false},
+		{"status class", func(t *testing.T) Query { return Query{Status: mustStatus(t, "5xx")} }, true},
+		{"status range", func(t *testing.T) Query { return Query{Status: mustStatus(t, "400-499")} }, false},
+		{"exprs", func(t *testing.T) Query {
+			return Query{Exprs: []Expr{mustExpr(t, "request.method=GET"), mustExpr(t, "response.length>20")}}
+		}, true},
+		{"one expr fails", func(t *testing.T) Query {
+			return Query{Exprs: []Expr{mustExpr(t, "request.method=GET"), mustExpr(t, "!response")}}
+		}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, tt.query(t).Match(record))
+		})
+	}
+}
+
+func mustStatus(t *testing.T, s string) StatusRange {
+	t.Helper()
+	status, err := ParseStatusRange(s)
+	require.NoError(t, err)
+	return status
+}
+
+func mustExpr(t *testing.T, s string) Expr {
+	t.Helper()
+	expr, err := ParseExpr(s)
+	require.NoError(t, err)
+	return expr
+}
+
+func TestParseStatusRange(t *testing.T) {
+	assert.Equal(t, StatusRange{Min: 500, Max: 500}, mustStatus(t, "500"))
+	assert.Equal(t, StatusRange{Min: 400, Max: 499}, mustStatus(t, "4XX"))
+	assert.Equal(t, StatusRange{Min: 200, Max: 299}, mustStatus(t, "200-299"))
+
+	for _, s := range []string{"", "abc", "6xx", "500-400"} {
+		_, err := ParseStatusRange(s)
+		assert.Error(t, err, s)
+	}
+}
+
+func TestParseTime(t *testing.T) {
+	now := time.Date(2025, 7, 9, 17, 0, 0, 0, time.UTC)
+	got, err := ParseTime("15m", now)
+	require.NoError(t, err)
+	assert.Equal(t, now.Add(-15*time.Minute), got)
+
+	got, err = ParseTime("2025-07-09T17:00:13+07:00", now)
+	require.NoError(t, err)
+	assert.Equal(t, time.Date(2025, 7, 9, 10, 0, 13, 0, time.UTC), got.UTC())
+
+	_, err = ParseTime("yesterday", now)
+	assert.Error(t, err)
+}
+
+func TestExpr(t *testing.T) {
+	record := mustParse(t, requestLog)
+
+	tests := []struct {
+		expr    string
+		wantKey string
+		wantOp  Operator
+		want    bool
+	}{
+		{"request", "request", OpExists, true},
+		{"error", "error", OpExists, false},
+		{"!error", "error", OpNotExist, true},
+		{"request.method=GET", "request.method", OpEqual, true},
+		{"request.method != GET", "request.method", OpNotEqual, false},
+		{"error!=boom", "error", OpNotEqual, true},
+		{"msg~^Internal", "msg", OpRegexp, true},
+		{"msg~a=b", "msg", OpRegexp, false},
+		{"response.status>=500", "response.status", OpGreatEq, true},
+		{"response.status<500", "response.status", OpLess, false},
+		{"response.length<=26", "response.length", OpLessEq, true},
+		{"request.method>1", "request.method", OpGreater, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.expr, func(t *testing.T) {
+			expr := mustExpr(t, tt.expr)
+			assert.Equal(t, tt.wantKey, expr.Key)
+			assert.Equal(t, tt.wantOp, expr.Op)
+			assert.Equal(t, tt.want, expr.Match(record))
+		})
+	}
+
+	for _, s := range []string{"", "=GET", "msg~(", "response.status>abc", "request."} {
+		_, err := ParseExpr(s)
+		assert.Error(t, err, s)
+	}
+}
@@ -0,0 +1,119 @@
+package logq
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"io"
+	"os"
+	"time"
+)
+
+// DefaultPollInterval is how often Follow checks a file for new lines.
+const DefaultPollInterval = 250 * time.Millisecond
+
+// Scan reads log lines from r and calls fn with each record. Lines that are
+// not JSON log records, such as the Fiber startup banner, are skipped.
+func Scan(r io.Reader, fn func(Record) error) error {
+	reader := bufio.NewReader(r)
+	for {
+		line, err := reader.ReadBytes('\n')
+		if len(line) > 0 {
+			if record, parseErr := Parse(line); parseErr == nil {
+				if err := fn(record); err != nil {
+					return err
+				}
+			}
+		}
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+	}
+}
+
+// Follow reads the file at path like tail -f and calls fn with each record
+// appended to it until ctx is done. With fromStart, the records already in the
+// file are read first. The file is reopened when it is rotated and read from
+// the start when it is truncated.
+func Follow(ctx context.Context, path string, fromStart bool, poll time.Duration, fn func(Record) error) error {
+	if poll <= 0 {
+		poll = DefaultPollInterval
+	}
+
+	file, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = file.Close() }()
+
+	var offset int64
+	if !fromStart {
+		if offset, err = file.Seek(0, io.SeekEnd); err != nil {
+			return err
+		}
+	}
+
+	reader := bufio.NewReader(file)
+	// partial holds a line that is still being written
+	var partial []byte
+	rotated := false
+	for {
+		line, err := reader.ReadBytes('\n')
+		offset += int64(len(line))
+		if err == nil {
+			line = append(partial, line...)
+			partial = nil
+			if record, parseErr := Parse(line); parseErr == nil {
+				if err := fn(record); err != nil {
+					return err
+				}
+			}
+			continue
+		}
+		if !errors.Is(err, io.EOF) {
+			return err
+		}
+		partial = append(partial, line...)
+
+		if rotated {
+			// the old file is drained, continue with the new one
+			next, err := os.Open(path)
+			if err != nil {
+				return err
+			}
+			_ = file.Close()
+			file, offset, partial, rotated = next, 0, nil, false
+			reader.Reset(file)
+			continue
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-time.After(poll):
+		}
+
+		current, err := file.Stat()
+		if err != nil {
+			return err
+		}
+		latest, err := os.Stat(path)
+		switch {
+		case err != nil && !errors.Is(err, os.ErrNotExist):
+			return err
+		case err == nil && !os.SameFile(current, latest):
+			// read what was written to the old file before it was rotated first
+			rotated = true
+		case current.Size() < offset:
+			// truncated
+			if _, err := file.Seek(0, io.SeekStart); err != nil {
+				return err
+			}
+			offset, partial = 0, nil
+			reader.Reset(file)
+		}
+	}
+}
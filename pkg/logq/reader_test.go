@@ -0,0 +1,91 @@
+package logq
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// followed collects the messages of the records Follow reads.
+type followed struct {
+	mu   sync.Mutex
+	msgs []string
+}
+
+func (f *followed) add(r Record) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.msgs = append(f.msgs, r.Msg)
+	return nil
+}
+
+func (f *followed) get() []string {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return append([]string(nil), f.msgs...)
+}
+
+func appendLine(t *testing.T, path, line string) {
+	t.Helper()
+	file, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o644)
+	require.NoError(t, err)
+	_, err = file.WriteString(line)
+	require.NoError(t, err)
+	require.NoError(t, file.Close())
+}
+
+func TestFollow(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "app.log")
+	appendLine(t, path, `{"msg":"before follow"}`+"\n")
+
+	ctx, cancel := context.WithCancel(t.Context())
+	out := &followed{}
+	done := make(chan error, 1)
+	go func() { done <- Follow(ctx, path, false, 5*time.Millisecond, out.add) }()
+
+	// wait until Follow has seeked to the end
+	time.Sleep(50 * time.Millisecond)
+	appendLine(t, path, `{"msg":"appended"}`+"\n")
+	appendLine(t, path, `{"msg":"partial`)
+	time.Sleep(50 * time.Millisecond)
+	appendLine(t, path, ` line"}`+"\n")
+	assert.Eventually(t, func() bool { return len(out.get()) == 2 }, time.Second, 5*time.Millisecond)
+
+	// rotated: the old file is renamed and a new one created
+	appendLine(t, path, `{"msg":"last in old file"}`+"\n")
+	require.NoError(t, os.Rename(path, path+".1"))
+	appendLine(t, path, `{"msg":"first in new file"}`+"\n")
+	assert.Eventually(t, func() bool { return len(out.get()) == 4 }, time.Second, 5*time.Millisecond)
+
+	// truncated
+	require.NoError(t, os.Truncate(path, 0))
+	time.Sleep(50 * time.Millisecond)
+	appendLine(t, path, `{"msg":"after truncate"}`+"\n")
+	assert.Eventually(t, func() bool { return len(out.get()) == 5 }, time.Second, 5*time.Millisecond)
+
+	cancel()
+	require.NoError(t, <-done)
+	assert.Equal(t, []string{"appended", "partial line", "last in old file", "first in new file", "after truncate"}, out.get())
+}
+
+func TestFollowFromStart(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "app.log")
+	appendLine(t, path, `{"msg":"before follow"}`+"\n")
+
+	ctx, cancel := context.WithCancel(t.Context())
+	out := &followed{}
+	done := make(chan error, 1)
+	go func() { done <- Follow(ctx, path, true, 5*time.Millisecond, out.add) }()
+	assert.Eventually(t, func() bool { return len(out.get()) == 1 }, time.Second, 5*time.Millisecond)
+
+	cancel()
+	require.NoError(t, <-done)
+
+	assert.Error(t, Follow(t.Context(), filepath.Join(t.TempDir(), "missing.log"), false, 0, out.add))
+}
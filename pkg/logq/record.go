@@ -0,0 +1,101 @@
+// Package logq reads the JSON logs written by logx and selects records by
+// level, time, request and attribute expressions.
+package logq
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+)
+
+// Record is one decoded log line.
+type Record struct {
+	Time  time.Time
+	Level slog.Level
+	Msg   string
+	// Fields holds every attribute of the line, including time, level and msg
+	Fields map[string]any
+	// Raw is the line as it was read, without the trailing newline
+	Raw []byte
+}
+
+// Parse decodes one JSON log line.
+func Parse(line []byte) (Record, error) {
+	line = bytes.TrimSpace(line)
+	if len(line) == 0 || line[0] != '{' {
+		return Record{}, fmt.Errorf("not a JSON log line")
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(line))
+	decoder.UseNumber()
+	var fields map[string]any
+	if err := decoder.Decode(&fields); err != nil {
+		return Record{}, fmt.Errorf("failed to decode log line: %w", err)
+	}
+
+	r := Record{Fields: fields, Raw: line}
+	if s, ok := fields[slog.TimeKey].(string); ok {
+		r.Time, _ = time.Parse(time.RFC3339Nano, s)
+	}
+	if s, ok := fields[slog.LevelKey].(string); ok {
+		_ = r.Level.UnmarshalText([]byte(s))
+	}
+	r.Msg, _ = fields[slog.MessageKey].(string)
+	return r, nil
+}
+
+// Lookup returns the value at a dotted path such as "request.path".
+func (r Record) Lookup(path string) (any, bool) {
+	var value any = r.Fields
+	for _, key := range strings.Split(path, ".") {
+		group, ok := value.(map[string]any)
+		if !ok {
+			return nil, false
+		}
+		if value, ok = group[key]; !ok {
+			return nil, false
+		}
+	}
+	return value, true
+}
+
+// String returns the value at path formatted as text, or "" when it is missing.
+func (r Record) String(path string) string {
+	value, ok := r.Lookup(path)
+	if !ok || value == nil {
+		return ""
+	}
+	if s, ok := value.(string); ok {
+		return s
+	}
+	if _, ok := value.(map[string]any); ok {
+		data, _ := json.Marshal(value)
+		return string(data)
+	}
+	return fmt.Sprint(value)
+}
+
+// RequestID returns the request_id attribute added by the logger middleware.
+func (r Record) RequestID() string {
+	return r.String("request_id")
+}
+
+// Status returns the response status of a request log, or 0.
+func (r Record) Status() int {
+	n, ok := r.Lookup("response.status")
+	if !ok {
+		return 0
+	}
+	number, ok := n.(json.Number)
+	if !ok {
+		return 0
+	}
+	status, err := number.Int64()
+	if err != nil {
+		return 0
+	}
+	return int(status)
+}
@@ -0,0 +1,151 @@
+package logq
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Request holds every record logged for one request ID.
+type Request struct {
+	ID      string
+	Records []Record
+}
+
+// Start returns the time of the first record.
+func (r Request) Start() time.Time {
+	return r.Records[0].Time
+}
+
+// Duration returns the time between the first and the last record.
+func (r Request) Duration() time.Duration {
+	return r.Records[len(r.Records)-1].Time.Sub(r.Start())
+}
+
+// summary returns the request line and status of the request log written by
+// the logger middleware, if any.
+func (r Request) summary() (method, path string, status int) {
+	for _, record := range r.Records {
+		if m := record.String("request.method"); m != "" {
+			method, path = m, record.String("request.path")
+		}
+		if s := record.Status(); s != 0 {
+			status = s
+		}
+	}
+	return method, path, status
+}
+
+// Timeline groups records by request ID, ordered by the time of their first
+// record. Records of one request are ordered by time. Records without a
+// request ID are left out.
+func Timeline(records []Record) []Request {
+	index := make(map[string]int)
+	var requests []Request
+	for _, record := range records {
+		id := record.RequestID()
+		if id == "" {
+			continue
+		}
+		i, ok := index[id]
+		if !ok {
+			i = len(requests)
+			index[id] = i
+			requests = append(requests, Request{ID: id})
+		}
+		requests[i].Records = append(requests[i].Records, record)
+	}
+
+	for _, request := range requests {
+		sort.SliceStable(request.Records, func(i, j int) bool {
+			return request.Records[i].Time.Before(request.Records[j].Time)
+		})
+	}
+	sort.SliceStable(requests, func(i, j int) bool {
+		return requests[i].Start().Before(requests[j].Start())
+	})
+	return requests
+}
+
+// timelineKeys are written in the header of a request rather than on each line.
+var timelineKeys = map[string]bool{
+	"time": true, "level": true, "msg": true, "source": true, "env": true,
+	"request_id": true, "program_info": true,
+}
+
+// WriteTimeline writes each request as a header line followed by its records
+// with their offset from the first one:
+//
+//	dd806e2f-ac77-4ac9-817e-1d0e6cf971d3 GET /api/v1/deeplink/4d16 500 0.671ms 4 records
+//	  +0.000ms INFO  Calling GetDeeplink in handler test3=testinfo3
+//	  +0.354ms ERROR Calling GetDeeplink in service failed error=testerror4
+func WriteTimeline(w io.Writer, requests []Request) error {
+	for _, request := range requests {
+		header := []string{request.ID}
+		if method, path, status := request.summary(); method != "" {
+			header = append(header, method, path)
+			if status != 0 {
+				header = append(header, fmt.Sprint(status))
+			}
+		}
+		header = append(header, formatMillis(request.Duration()), fmt.Sprintf("%d records", len(request.Records)))
+		if _, err := fmt.Fprintln(w, strings.Join(header, " ")); err != nil {
+			return err
+		}
+
+		for _, record := range request.Records {
+			line := fmt.Sprintf("  +%s %-5s %s", formatMillis(record.Time.Sub(request.Start())), record.Level, record.Msg)
+			if attrs := formatAttrs(record); attrs != "" {
+				line += " " + attrs
+			}
+			if _, err := fmt.Fprintln(w, line); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func formatMillis(d time.Duration) string {
+	return fmt.Sprintf("%.3fms", float64(d)/float64(time.Millisecond))
+}
+
+// formatAttrs formats the attributes of record not in the timeline header as
+// sorted key=value pairs. Groups are flattened into dotted keys and lists,
+// such as stack traces, are only counted.
+func formatAttrs(record Record) string {
+	var pairs []string
+	var flatten func(prefix string, fields map[string]any)
+	flatten = func(prefix string, fields map[string]any) {
+		for key, value := range fields {
+			if prefix == "" && timelineKeys[key] {
+				continue
+			}
+			switch v := value.(type) {
+			case map[string]any:
+				flatten(prefix+key+".", v)
+			case []any:
+				pairs = append(pairs, fmt.Sprintf("%s%s=[%d items]", prefix, key, len(v)))
+			default:
+				pairs = append(pairs, prefix+key+"="+formatValue(v))
+			}
+		}
+	}
+	flatten("", record.Fields)
+	sort.Strings(pairs)
+	return strings.Join(pairs, " ")
+}
+
+// formatValue quotes strings that would be ambiguous in a key=value pair.
+func formatValue(value any) string {
+	if value == nil {
+		return "null"
+	}
+	if s, ok := value.(string); ok && (s == "" || strings.ContainsAny(s, " =\"\n")) {
+		return strconv.Quote(s)
+	}
+	return fmt.Sprint(value)
+}
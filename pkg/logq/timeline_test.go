@@ -0,0 +1,59 @@
+package logq
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const appLog = `{"time":"2025-07-09T17:00:10.109987+07:00","level":"INFO","msg":"Starting server","source":"deeplink-bff","env":"dev","addr":"0.0.0.0:4000"}
+{"time":"2025-07-09T17:00:13.812788+07:00","level":"INFO","msg":"Calling GetDeeplink in handler","request_id":"r-1","id":"4d16"}
+{"time":"2025-07-09T17:00:13.900000+07:00","level":"INFO","msg":"Incoming request","request_id":"r-2","request":{"method":"GET","path":"/s/abc"},"response":{"status":302}}
+{"time":"2025-07-09T17:00:13.813142+07:00","level":"ERROR","msg":"Calling GetDeeplink in service failed","request_id":"r-1","error":"connection refused"}
+ ┌───────────────────────────────────────────────────┐
+{"time":"2025-07-09T17:00:13.813458+07:00","level":"ERROR","msg":"Internal Server Error","request_id":"r-1","request":{"method":"GET","path":"/api/v1/deeplink/4d16","query":""},"response":{"status":500,"stacktrace":[{"func":"main.handler"}]}}
+`
+
+func scanAll(t *testing.T, log string) []Record {
+	t.Helper()
+	var records []Record
+	require.NoError(t, Scan(strings.NewReader(log), func(r Record) error {
+		records = append(records, r)
+		return nil
+	}))
+	return records
+}
+
+func TestScanSkipsNonJSONLines(t *testing.T) {
+	records := scanAll(t, appLog)
+	assert.Len(t, records, 5)
+
+	// the last line may have no trailing newline
+	assert.Len(t, scanAll(t, strings.TrimSuffix(appLog, "\n")), 5)
+}
+
+func TestTimeline(t *testing.T) {
+	requests := Timeline(scanAll(t, appLog))
+	require.Len(t, requests, 2)
+
+	first := requests[0]
+	assert.Equal(t, "r-1", first.ID)
+	require.Len(t, first.Records, 3)
+	assert.Equal(t, "Calling GetDeeplink in handler", first.Records[0].Msg)
+	assert.Equal(t, "Internal Server Error", first.Records[2].Msg)
+	assert.Equal(t, "0.670ms", formatMillis(first.Duration()))
+	assert.Equal(t, "r-2", requests[1].ID)
+
+	buf := &bytes.Buffer{}
+	require.NoError(t, WriteTimeline(buf, requests))
+	assert.Equal(t, `r-1 GET /api/v1/deeplink/4d16 500 0.670ms 3 records
+  +0.000ms INFO  Calling GetDeeplink in handler id=4d16
+  +0.354ms ERROR Calling GetDeeplink in service failed error="connection refused"
+  +0.670ms ERROR Internal Server Error request.method=GET request.path=/api/v1/deeplink/4d16 request.query="" response.stacktrace=[1 items] response.status=500
+r-2 GET /s/abc 302 0.000ms 1 records
+  +0.000ms INFO  Incoming request request.method=GET request.path=/s/abc response.status=302
+`, buf.String())
+}
@@ -0,0 +1,169 @@
+package logx
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"sync"
+	"sync/atomic"
+)
+
+// OverflowPolicy selects what Async does with a record when its queue is full.
+type OverflowPolicy string
+
+const (
+	// OverflowBlock makes the caller wait until the queue has room.
+	OverflowBlock OverflowPolicy = "block"
+	// OverflowDrop discards the record and counts it, see Async.Dropped.
+	OverflowDrop OverflowPolicy = "drop"
+)
+
+// DefaultQueueSize is the queue size used when AsyncConfig.QueueSize is zero.
+const DefaultQueueSize = 4096
+
+// AsyncConfig configures an Async.
+type AsyncConfig struct {
+	// QueueSize bounds the number of records waiting to be written.
+	QueueSize int
+	// Overflow defaults to OverflowBlock.
+	Overflow OverflowPolicy
+}
+
+// Async moves formatting and writing of log records off the calling
+// goroutine. Records are censored before they are queued, so sensitive
+// values never wait in the queue. Pass it with WithAsync and call Close on
+// shutdown to flush the queue.
+type Async struct {
+	cfg   AsyncConfig
+	queue chan asyncRecord
+	done  chan struct{}
+
+	// mu guards closed; Handle holds it for reading while sending so the
+	// queue is never closed under a sender
+	mu     sync.RWMutex
+	closed bool
+
+	dropped atomic.Uint64
+	failed  atomic.Uint64
+}
+
+// asyncRecord is a record waiting to be written by the handler that received it.
+type asyncRecord struct {
+	ctx     context.Context
+	handler slog.Handler
+	record  slog.Record
+}
+
+// NewAsync creates an Async and starts its writer goroutine.
+func NewAsync(cfg AsyncConfig) (*Async, error) {
+	if cfg.QueueSize < 0 {
+		return nil, fmt.Errorf("async queue size must not be negative")
+	}
+	if cfg.QueueSize == 0 {
+		cfg.QueueSize = DefaultQueueSize
+	}
+	switch cfg.Overflow {
+	case "":
+		cfg.Overflow = OverflowBlock
+	case OverflowBlock, OverflowDrop:
+	default:
+		return nil, fmt.Errorf("unknown overflow policy %q", cfg.Overflow)
+	}
+
+	a := &Async{
+		cfg:   cfg,
+		queue: make(chan asyncRecord, cfg.QueueSize),
+		done:  make(chan struct{}),
+	}
+	go a.run()
+	return a, nil
+}
+
+func (a *Async) run() {
+	defer close(a.done)
+	for r := range a.queue {
+		if err := r.handler.Handle(r.ctx, r.record); err != nil {
+			a.failed.Add(1)
+		}
+	}
+}
+
+// enqueue queues a record. After Close, records are written synchronously so
+// late records are not lost.
+func (a *Async) enqueue(ctx context.Context, handler slog.Handler, r slog.Record) error {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+
+	if a.closed {
+		return handler.Handle(ctx, r)
+	}
+
+	// the caller may cancel ctx or reuse the record once Handle returns
+	queued := asyncRecord{ctx: context.WithoutCancel(ctx), handler: handler, record: r.Clone()}
+	if a.cfg.Overflow == OverflowDrop {
+		select {
+		case a.queue <- queued:
+		default:
+			a.dropped.Add(1)
+		}
+		return nil
+	}
+
+	a.queue <- queued
+	return nil
+}
+
+// Close stops accepting records, flushes the queue and waits for it to be
+// written or for ctx to be done. Records logged after Close are written synchronously.
+func (a *Async) Close(ctx context.Context) error {
+	a.mu.Lock()
+	if !a.closed {
+		a.closed = true
+		close(a.queue)
+	}
+	a.mu.Unlock()
+
+	select {
+	case <-a.done:
+		return nil
+	case <-ctx.Done():
+		return fmt.Errorf("failed to flush %d queued log records: %w", len(a.queue), ctx.Err())
+	}
+}
+
+// Dropped returns the number of records discarded because the queue was full.
+func (a *Async) Dropped() uint64 {
+	return a.dropped.Load()
+}
+
+// Failed returns the number of queued records the underlying handler failed to write.
+func (a *Async) Failed() uint64 {
+	return a.failed.Load()
+}
+
+// Pending returns the number of records waiting in the queue.
+func (a *Async) Pending() int {
+	return len(a.queue)
+}
+
+// asyncHandler queues records for the handler it wraps.
+type asyncHandler struct {
+	handler slog.Handler
+	async   *Async
+}
+
+func (h *asyncHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	return h.handler.Enabled(ctx, level)
+}
+
+func (h *asyncHandler) Handle(ctx context.Context, r slog.Record) error {
+	return h.async.enqueue(ctx, h.handler, r)
+}
+
+func (h *asyncHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return &asyncHandler{handler: h.handler.WithAttrs(attrs), async: h.async}
+}
+
+func (h *asyncHandler) WithGroup(name string) slog.Handler {
+	return &asyncHandler{handler: h.handler.WithGroup(name), async: h.async}
+}
@@ -0,0 +1,130 @@
+package logx
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"log/slog"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// gatedWriter blocks writes until the gate is opened.
+type gatedWriter struct {
+	gate chan struct{}
+	mu   sync.Mutex
+	buf  bytes.Buffer
+}
+
+func (w *gatedWriter) Write(p []byte) (int, error) {
+	<-w.gate
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.buf.Write(p)
+}
+
+func (w *gatedWriter) String() string {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.buf.String()
+}
+
+func TestAsyncFlushOnClose(t *testing.T) {
+	async, err := NewAsync(AsyncConfig{QueueSize: 16})
+	require.NoError(t, err)
+	w := &gatedWriter{gate: make(chan struct{})}
+	logger, err := New(Config{}, WithWriter(w), WithAsync(async))
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithCancel(AppendCtx(context.Background(), slog.String("request_id", "r-1")))
+	logger.InfoContext(ctx, "queued", slog.String("password", "secret"))
+	cancel()
+	assert.Empty(t, w.String(), "records are written off the calling goroutine")
+
+	close(w.gate)
+	require.NoError(t, async.Close(context.Background()))
+
+	var record map[string]any
+	require.NoError(t, json.Unmarshal([]byte(w.String()), &record))
+	assert.Equal(t, "queued", record["msg"])
+	assert.Equal(t, "r-1", record["request_id"])
+	assert.Equal(t, DefaultRedactMessage, record["password"])
+
+	// records logged after Close are written synchronously
+	logger.Info("late")
+	assert.Contains(t, w.String(), "late")
+}
+
+func TestAsyncDrop(t *testing.T) {
+	async, err := NewAsync(AsyncConfig{QueueSize: 2, Overflow: OverflowDrop})
+	require.NoError(t, err)
+	w := &gatedWriter{gate: make(chan struct{})}
+	logger, err := New(Config{}, WithWriter(w), WithAsync(async))
+	require.NoError(t, err)
+
+	// one record is held by the blocked writer, two fill the queue
+	logger.Info("first")
+	require.Eventually(t, func() bool { return async.Pending() == 0 }, time.Second, time.Millisecond)
+	for i := 0; i < 5; i++ {
+		logger.Info("burst")
+	}
+	assert.Equal(t, uint64(3), async.Dropped())
+
+	close(w.gate)
+	require.NoError(t, async.Close(context.Background()))
+	assert.Equal(t, 3, strings.Count(w.String(), "\n"))
+}
+
+func TestAsyncBlock(t *testing.T) {
+	async, err := NewAsync(AsyncConfig{QueueSize: 1})
+	require.NoError(t, err)
+	w := &gatedWriter{gate: make(chan struct{})}
+	logger, err := New(Config{}, WithWriter(w), WithAsync(async))
+	require.NoError(t, err)
+
+	logged := make(chan struct{})
+	go func() {
+		defer close(logged)
+		for i := 0; i < 3; i++ {
+			logger.Info("blocking")
+		}
+	}()
+
+	select {
+	case <-logged:
+		t.Fatal("logging must block while the queue is full")
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	close(w.gate)
+	<-logged
+	require.NoError(t, async.Close(context.Background()))
+	assert.Equal(t, 3, strings.Count(w.String(), "\n"))
+	assert.Zero(t, async.Dropped())
+}
+
+func TestAsyncCloseTimeout(t *testing.T) {
+	async, err := NewAsync(AsyncConfig{})
+	require.NoError(t, err)
+	w := &gatedWriter{gate: make(chan struct{})}
+	logger, err := New(Config{}, WithWriter(w), WithAsync(async))
+	require.NoError(t, err)
+	logger.Info("stuck")
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	assert.ErrorIs(t, async.Close(ctx), context.DeadlineExceeded)
+	close(w.gate)
+}
+
+func TestNewAsyncInvalid(t *testing.T) {
+	_, err := NewAsync(AsyncConfig{QueueSize: -1})
+	assert.Error(t, err)
+	_, err = NewAsync(AsyncConfig{Overflow: "spill"})
+	assert.Error(t, err)
+}
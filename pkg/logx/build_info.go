@@ -1,17 +1,62 @@
 package logx
 
-import "runtime/debug"
+import (
+	"log/slog"
+	"os"
+	"runtime/debug"
+	"sync"
 
-type buildInfo struct {
+	"github.com/google/uuid"
+)
+
+// BuildInfo identifies the build. It holds nothing about the host, so it can
+// be served publicly.
+type BuildInfo struct {
 	GoVersion string `json:"go_version"`
 	GitCommit string `json:"git_commit"`
 	BuildDate string `json:"build_date"`
 }
 
-func newBuildInfo() buildInfo {
+// RuntimeInfo identifies the build and the process writing the logs.
+type RuntimeInfo struct {
+	BuildInfo
+	Hostname string `json:"hostname"`
+	PID      int    `json:"pid"`
+	// InstanceID is random per process, so restarts on the same host are told apart
+	InstanceID string `json:"instance_id"`
+}
+
+var runtimeInfo = sync.OnceValue(func() RuntimeInfo {
+	hostname, _ := os.Hostname()
+	return RuntimeInfo{
+		BuildInfo:  newBuildInfo(),
+		Hostname:   hostname,
+		PID:        os.Getpid(),
+		InstanceID: uuid.NewString(),
+	}
+})
+
+// Runtime returns the runtime information of this process. It is computed once.
+func Runtime() RuntimeInfo {
+	return runtimeInfo()
+}
+
+// attr returns the runtime information as the program_info group.
+func (info RuntimeInfo) attr() slog.Attr {
+	return slog.Group("program_info",
+		slog.String("go_version", info.GoVersion),
+		slog.String("git_commit", info.GitCommit),
+		slog.String("build_date", info.BuildDate),
+		slog.String("hostname", info.Hostname),
+		slog.Int("pid", info.PID),
+		slog.String("instance_id", info.InstanceID),
+	)
+}
+
+func newBuildInfo() BuildInfo {
 	info, ok := debug.ReadBuildInfo()
 	if !ok {
-		return buildInfo{}
+		return BuildInfo{}
 	}
 
 	var (
@@ -37,7 +82,7 @@ func newBuildInfo() buildInfo {
 		gitCommit += "+CHANGES"
 	}
 
-	return buildInfo{
+	return BuildInfo{
 		GoVersion: info.GoVersion,
 		GitCommit: gitCommit,
 		BuildDate: buildDate,
@@ -1,28 +1,195 @@
 package logx
 
 import (
-	"context"
+	"bytes"
+	"encoding/json"
 	"log/slog"
 	"reflect"
+	"strings"
 )
 
 // censorAttribute returns a new slog.Attr with sensitive data redacted.
 // If debug mode is enabled (h.withDebug is true), it returns the original attribute unmodified.
-// Otherwise, it performs deep cloning of the attribute value while redacting sensitive data
-// based on the configured sensitiveKeys.
+// Otherwise, it redacts sensitive data based on the configured sensitiveKeys and their
+// masking strategies.
 //
 // The redaction process handles various data types including:
 //   - Basic types (strings, numbers, etc.)
 //   - Complex types (structs, maps, slices)
 //   - JSON-encoded strings that may contain sensitive data
+//   - Sensitive values inside any string, found by the enabled detectors
+//   - Groups, which are censored recursively at every depth
+//   - Groups and maps under a sensitive key, which are redacted as a whole
 //
+// Strings and other primitive kinds take a fast path that never uses reflection.
+// Only values logged with slog.Any are deep cloned, using a cached plan per type.
 // For JSON strings, it attempts to parse and redact sensitive fields within the JSON
 // before re-encoding.
 func (h *censoringHandler) censorAttribute(attr slog.Attr) slog.Attr {
 	if h.withDebug {
 		return attr
 	}
-	ctx := context.Background()
-	masked := clone(ctx, attr.Key, reflect.ValueOf(attr.Value.Any()), "", h.sensitiveKeys)
+	return h.censorAttr(0, attr)
+}
+
+// censorAttrs censors a list of attributes, e.g. those of a group or those
+// added with slog.Logger.With. The input slice is never modified.
+func (h *censoringHandler) censorAttrs(depth int, attrs []slog.Attr) []slog.Attr {
+	censored := make([]slog.Attr, len(attrs))
+	for i, attr := range attrs {
+		censored[i] = h.censorAttr(depth, attr)
+	}
+	return censored
+}
+
+func (h *censoringHandler) censorAttr(depth int, attr slog.Attr) slog.Attr {
+	if depth >= maxDepth {
+		return attr
+	}
+
+	attr.Value = attr.Value.Resolve()
+	switch attr.Value.Kind() {
+	case slog.KindGroup:
+		// e.g. slog.Group("credentials", ...) is redacted before its
+		// attributes are looked at
+		if _, ok := h.maskFor(attr.Key, ""); ok {
+			return slog.String(attr.Key, h.redaction.redactMessage())
+		}
+		return slog.Attr{Key: attr.Key, Value: slog.GroupValue(h.censorAttrs(depth+1, attr.Value.Group())...)}
+
+	case slog.KindString:
+		if censored, changed := h.censorString(depth, attr.Key, "", attr.Value.String()); changed {
+			return slog.String(attr.Key, censored)
+		}
+		return attr
+
+	case slog.KindInt64, slog.KindUint64, slog.KindFloat64, slog.KindBool, slog.KindDuration, slog.KindTime:
+		if m, ok := h.maskFor(attr.Key, ""); ok {
+			return slog.String(attr.Key, m.apply(attr.Value.String(), h.redaction))
+		}
+		return attr
+	}
+
+	value := attr.Value.Any()
+	if value == nil {
+		return attr
+	}
+	src := reflect.ValueOf(value)
+	if isMap(src) {
+		if _, ok := h.maskFor(attr.Key, ""); ok {
+			return slog.String(attr.Key, h.redaction.redactMessage())
+		}
+	}
+	masked := plainString(src, h.clone(depth, attr.Key, src, ""))
 	return slog.Any(attr.Key, masked.Interface())
 }
+
+// censorMessage redacts sensitive values the detectors find in a record message.
+func (h *censoringHandler) censorMessage(msg string) string {
+	if h.withDebug {
+		return msg
+	}
+	return redactValue(msg, h.detectors, h.redaction.redactMessage())
+}
+
+// censorString redacts a string value. Sensitive fields are masked, JSON
+// objects are censored field by field and any other string is scanned by the
+// detectors. The boolean reports whether the value changed.
+func (h *censoringHandler) censorString(depth int, fieldName, tag, s string) (string, bool) {
+	// Check if this field should be redacted based on:
+	// 1. If the field has a sensitive tag, e.g. "true" or "last4"
+	// 2. If the field name is in sensitiveKeys map
+	if m, ok := h.maskFor(fieldName, tag); ok {
+		return m.apply(s, h.redaction), true
+	}
+
+	// Attempt to parse the string value as JSON to handle nested sensitive data
+	if looksLikeJSONObject(s) {
+		if censored, ok := h.censorJSON(depth, s); ok {
+			return censored, censored != s
+		}
+	}
+
+	censored := redactValue(s, h.detectors, h.redaction.redactMessage())
+	return censored, censored != s
+}
+
+// looksLikeJSONObject is a cheap check run before attempting to decode a
+// string, so plain values never pay for json.Unmarshal.
+func looksLikeJSONObject(s string) bool {
+	s = strings.TrimSpace(s)
+	return len(s) >= 2 && s[0] == '{' && s[len(s)-1] == '}'
+}
+
+// censorJSON decodes a JSON object, censors it and encodes it again.
+// Numbers are decoded as json.Number so large integers survive the round trip.
+func (h *censoringHandler) censorJSON(depth int, s string) (string, bool) {
+	var object map[string]any
+	decoder := json.NewDecoder(strings.NewReader(s))
+	decoder.UseNumber()
+	if err := decoder.Decode(&object); err != nil || decoder.More() {
+		return "", false
+	}
+
+	h.censorJSONObject(depth, object)
+
+	var buf bytes.Buffer
+	encoder := json.NewEncoder(&buf)
+	if err := encoder.Encode(object); err != nil {
+		return "", false
+	}
+	// Encode terminates the document with a newline
+	return string(bytes.TrimSuffix(buf.Bytes(), []byte{'\n'})), true
+}
+
+// censorJSONObject censors a decoded JSON object in place.
+func (h *censoringHandler) censorJSONObject(depth int, object map[string]any) {
+	for key, value := range object {
+		if m, ok := h.maskFor(key, ""); ok {
+			object[key] = maskJSONValue(m, value, h.redaction)
+			continue
+		}
+		object[key] = h.censorJSONValue(depth+1, key, value)
+	}
+}
+
+func (h *censoringHandler) censorJSONValue(depth int, key string, value any) any {
+	if depth >= maxDepth {
+		return value
+	}
+
+	switch v := value.(type) {
+	case string:
+		censored, _ := h.censorString(depth, key, "", v)
+		return censored
+	case json.Number:
+		// a number is only replaced when a detector matches, e.g. a card number
+		if censored := redactValue(string(v), h.detectors, h.redaction.redactMessage()); censored != string(v) {
+			return censored
+		}
+		return v
+	case map[string]any:
+		h.censorJSONObject(depth, v)
+		return v
+	case []any:
+		for i := range v {
+			v[i] = h.censorJSONValue(depth+1, key, v[i])
+		}
+		return v
+	default:
+		return v
+	}
+}
+
+// maskJSONValue masks a value decoded from JSON. Only strings and numbers can
+// be partially shown; objects and arrays are always fully redacted.
+func maskJSONValue(m mask, value any, r redaction) string {
+	switch v := value.(type) {
+	case string:
+		return m.apply(v, r)
+	case json.Number:
+		return m.apply(string(v), r)
+	default:
+		return r.redactMessage()
+	}
+}
@@ -0,0 +1,84 @@
+package logx
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"testing"
+)
+
+type benchProfile struct {
+	Name     string `json:"name"`
+	Email    string `json:"email"`
+	Password string `json:"password" sensitive:"true"`
+	Card     string `json:"card_number,omitempty" sensitive:"last4"`
+	Age      int    `json:"age"`
+	Tags     []string
+}
+
+func newBenchLogger(b *testing.B) *slog.Logger {
+	b.Helper()
+	logger, err := New(Config{Environment: "bench", Source: "bench"}, WithWriter(io.Discard))
+	if err != nil {
+		b.Fatal(err)
+	}
+	return logger
+}
+
+// BenchmarkCensoringHandler measures one log call through the censoring
+// handler and the JSON handler writing to io.Discard.
+func BenchmarkCensoringHandler(b *testing.B) {
+	ctx := AppendCtx(context.Background(), slog.String("request_id", "dd806e2f-ac77-4ac9-817e-1d0e6cf971d3"))
+
+	b.Run("primitives", func(b *testing.B) {
+		logger := newBenchLogger(b)
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			logger.InfoContext(ctx, "Calling GetDeeplink in handler",
+				slog.String("deeplink_id", "4d16c3c4-865a-41e8-ab47-2ea773415277"),
+				slog.String("password", "secret123"),
+				slog.Int("status", 200),
+				slog.Bool("cached", false),
+			)
+		}
+	})
+
+	// mirrors the record written by middleware.LoggerWithConfig
+	b.Run("request_log", func(b *testing.B) {
+		logger := newBenchLogger(b)
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			logger.LogAttrs(ctx, slog.LevelInfo, "Incoming request",
+				slog.Group("request",
+					slog.String("method", "POST"),
+					slog.String("host", "localhost:4000"),
+					slog.String("path", "/api/v1/deeplink"),
+					slog.String("query", ""),
+					slog.Any("params", map[string]string{"id": "4d16c3c4-865a-41e8-ab47-2ea773415277"}),
+					slog.String("body", `{"product_code":"loan","email":"john@example.com","amount":1500}`),
+				),
+				slog.Group("response",
+					slog.Int("status", 200),
+					slog.String("body", `{"deeplinks":[{"product_code":"loan","partner_txn_ref":"TXN-0001"}]}`),
+					slog.Int("length", 64),
+				),
+			)
+		}
+	})
+
+	b.Run("struct", func(b *testing.B) {
+		logger := newBenchLogger(b)
+		profile := benchProfile{
+			Name:     "John",
+			Email:    "john@example.com",
+			Password: "secret",
+			Card:     "4111111111111111",
+			Age:      30,
+			Tags:     []string{"a", "b"},
+		}
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			logger.InfoContext(ctx, "profile", slog.Any("profile", profile))
+		}
+	})
+}
@@ -1,164 +1,185 @@
 package logx
 
 import (
-	"context"
 	snake "deeplink-bff/pkg/string"
-	"encoding/json"
 	"reflect"
+	"strings"
+	"sync"
+	"time"
 	"unsafe"
 )
 
-type ctxKeyDepth struct{}
-
 const (
 	maxDepth = 32
+	// maxCachedKeys bounds the per-logger cache of field name lookups, since
+	// map keys and JSON keys may be unbounded
+	maxCachedKeys = 4096
 )
 
-func clone(ctx context.Context, fieldName string, src reflect.Value, tag string, sensitiveKeys map[string]struct{}) reflect.Value {
-	if v, ok := ctx.Value(ctxKeyDepth{}).(int); !ok {
-		ctx = context.WithValue(ctx, ctxKeyDepth{}, 0)
-	} else {
-		if v >= maxDepth {
-			return src
-		}
-		ctx = context.WithValue(ctx, ctxKeyDepth{}, v+1)
+// fieldPlan describes a struct field that may hold sensitive data.
+type fieldPlan struct {
+	index    int
+	name     string
+	key      string
+	tag      string
+	exported bool
+}
+
+// typePlan lists the fields of a struct type that must be censored.
+// Fields of primitive kinds are copied as they are and are not listed.
+type typePlan struct {
+	fields []fieldPlan
+}
+
+var (
+	// typePlans caches a *typePlan per reflect.Type
+	typePlans sync.Map
+
+	opaqueTypes = map[reflect.Type]struct{}{
+		reflect.TypeOf(time.Time{}):     {},
+		reflect.TypeOf(time.Location{}): {},
 	}
 
-	if src.Kind() == reflect.Ptr && src.IsNil() {
-		return reflect.New(src.Type()).Elem()
+	stringType = reflect.TypeOf("")
+)
+
+// planFor returns the cached plan of a struct type, building it on first use.
+func planFor(t reflect.Type) *typePlan {
+	if plan, ok := typePlans.Load(t); ok {
+		return plan.(*typePlan)
 	}
 
-	switch src.Kind() {
-	case reflect.String:
-		dst := reflect.New(src.Type())
-		strValue := src.String()
-
-		// Check if this field should be redacted based on:
-		// 1. If the field name is in sensitiveKeys map
-		// 2. If the field has a redact tag set to "true"
-		if _, ok := sensitiveKeys[snake.SnakeCase(fieldName)]; ok || tag == "true" {
-			if dst.Elem().CanSet() {
-				dst.Elem().SetString(DefaultRedactMessage)
+	plan := &typePlan{}
+	if _, opaque := opaqueTypes[t]; !opaque {
+		for i := 0; i < t.NumField(); i++ {
+			f := t.Field(i)
+			if !mayHoldString(f.Type) {
+				continue
 			}
-			return dst.Elem()
-		}
-
-		// Attempt to parse the string value as JSON to handle nested sensitive data
-		var jsonData interface{}
-		if err := json.Unmarshal([]byte(strValue), &jsonData); err == nil {
-			if jsonStruct := reflect.ValueOf(jsonData); jsonStruct.Kind() == reflect.Map {
-				// Create a new map to store the processed values
-				dstMap := reflect.MakeMap(jsonStruct.Type())
-
-				for _, key := range jsonStruct.MapKeys() {
-					mValue := jsonStruct.MapIndex(key)
-					keyStr := key.String()
-
-					// Handle unexported fields by creating a new addressable value
-					if !mValue.CanInterface() {
-						mValue = reflect.NewAt(mValue.Type(), unsafe.Pointer(mValue.UnsafeAddr())).Elem()
-					}
-
-					var copiedValue reflect.Value
-					if _, ok := sensitiveKeys[snake.SnakeCase(keyStr)]; ok {
-						// If sensitive, replace with redaction message
-						copiedValue = reflect.ValueOf(DefaultRedactMessage)
-					} else {
-						// If not sensitive, recursively clone the value
-						// Empty tag ("") is passed as we can't get struct tags from a JSON map
-						copiedValue = clone(ctx, keyStr, mValue, "", sensitiveKeys)
-					}
-
-					dstMap.SetMapIndex(key, copiedValue)
-				}
 
-				// Convert the processed map back to a JSON string
-				if newJSON, err := json.Marshal(dstMap.Interface()); err == nil {
-					dst.Elem().SetString(string(newJSON))
-					return dst.Elem()
-				}
+			// Check if has tag json using the tag key
+			name := f.Name
+			if tagjson, _, _ := strings.Cut(f.Tag.Get("json"), ","); tagjson != "" && tagjson != "-" {
+				name = tagjson
 			}
+
+			plan.fields = append(plan.fields, fieldPlan{
+				index:    i,
+				name:     name,
+				key:      snake.SnakeCase(name),
+				tag:      f.Tag.Get(DefaultTagKey),
+				exported: f.IsExported(),
+			})
 		}
+	}
 
-		// If not JSON or no modifications needed, copy the original string as is
-		dst.Elem().SetString(strValue)
-		return dst.Elem()
+	actual, _ := typePlans.LoadOrStore(t, plan)
+	return actual.(*typePlan)
+}
 
-	case reflect.Struct:
-		dst := reflect.New(src.Type())
-		t := src.Type()
+// mayHoldString reports whether values of t can contain a string, and so
+// may need censoring. Values of other types are shared instead of copied.
+func mayHoldString(t reflect.Type) bool {
+	switch t.Kind() {
+	case reflect.String, reflect.Struct, reflect.Map, reflect.Ptr, reflect.Interface:
+		return true
+	case reflect.Slice, reflect.Array:
+		return mayHoldString(t.Elem())
+	default:
+		return false
+	}
+}
 
-		for i := 0; i < t.NumField(); i++ {
-			f := t.Field(i)
-			srcValue := src.Field(i)
-			dstValue := dst.Elem().Field(i)
-			tagjson := f.Tag.Get("json")
-
-			if !srcValue.CanInterface() {
-				dstValue = reflect.NewAt(dstValue.Type(), unsafe.Pointer(dstValue.UnsafeAddr())).Elem()
-
-				if !srcValue.CanAddr() {
-					switch {
-					case srcValue.CanInt():
-						dstValue.SetInt(srcValue.Int())
-					case srcValue.CanUint():
-						dstValue.SetUint(srcValue.Uint())
-					case srcValue.CanFloat():
-						dstValue.SetFloat(srcValue.Float())
-					case srcValue.CanComplex():
-						dstValue.SetComplex(srcValue.Complex())
-					case srcValue.Kind() == reflect.Bool:
-						dstValue.SetBool(srcValue.Bool())
-					}
+// clone returns a deep copy of src with sensitive data redacted.
+// Parts of src that cannot hold sensitive data are shared with the copy.
+func (h *censoringHandler) clone(depth int, fieldName string, src reflect.Value, tag string) reflect.Value {
+	if depth >= maxDepth || !src.IsValid() {
+		return src
+	}
 
-					continue
-				}
+	switch src.Kind() {
+	case reflect.String:
+		censored, changed := h.censorString(depth, fieldName, tag, src.String())
+		if !changed {
+			return src
+		}
+		dst := reflect.New(src.Type()).Elem()
+		dst.SetString(censored)
+		return dst
 
-				srcValue = reflect.NewAt(srcValue.Type(), unsafe.Pointer(srcValue.UnsafeAddr())).Elem()
-			}
+	case reflect.Struct:
+		plan := planFor(src.Type())
+		if len(plan.fields) == 0 {
+			return src
+		}
 
-			// Check if has tag json using the tag key
-			fieldName := f.Name
-			if tagjson != "" {
-				fieldName = tagjson
+		// Copy the whole struct first so primitive and unexported fields are
+		// kept, then replace the fields that may hold sensitive data.
+		dst := reflect.New(src.Type()).Elem()
+		dst.Set(src)
+		for _, f := range plan.fields {
+			field := dst.Field(f.index)
+			if !f.exported {
+				// Handle unexported fields by creating a new addressable value
+				field = reflect.NewAt(field.Type(), unsafe.Pointer(field.UnsafeAddr())).Elem()
 			}
-
-			tagValue := f.Tag.Get(DefaultTagKey)
-			copied := clone(ctx, fieldName, srcValue, tagValue, sensitiveKeys)
-			dstValue.Set(copied)
+			field.Set(h.clone(depth+1, f.name, field, f.tag))
 		}
-		return dst.Elem()
+		return dst
 
 	case reflect.Map:
-		dst := reflect.MakeMap(src.Type())
-		keys := src.MapKeys()
-		for i := 0; i < src.Len(); i++ {
-			mValue := src.MapIndex(keys[i])
-			dst.SetMapIndex(keys[i], clone(ctx, keys[i].String(), mValue, "", sensitiveKeys))
+		if src.IsNil() || !mayHoldString(src.Type().Elem()) {
+			return src
+		}
+		// a map under a sensitive key is redacted as a whole when the map
+		// can hold the redact message in its place
+		redacted := reflect.ValueOf(h.redaction.redactMessage())
+		canRedact := redacted.Type().AssignableTo(src.Type().Elem())
+
+		dst := reflect.MakeMapWithSize(src.Type(), src.Len())
+		iter := src.MapRange()
+		for iter.Next() {
+			key := iter.Key()
+			name := ""
+			if key.Kind() == reflect.String {
+				name = key.String()
+			}
+			if canRedact && isMap(iter.Value()) {
+				if _, ok := h.maskFor(name, ""); ok {
+					dst.SetMapIndex(key, redacted)
+					continue
+				}
+			}
+			dst.SetMapIndex(key, h.clone(depth+1, name, iter.Value(), ""))
 		}
 		return dst
 
 	case reflect.Slice:
-		dst := reflect.MakeSlice(src.Type(), src.Len(), src.Cap())
+		if src.IsNil() || !mayHoldString(src.Type().Elem()) {
+			return src
+		}
+		dst := reflect.MakeSlice(src.Type(), src.Len(), src.Len())
 		for i := 0; i < src.Len(); i++ {
-			dst.Index(i).Set(clone(ctx, fieldName, src.Index(i), "", sensitiveKeys))
+			dst.Index(i).Set(h.clone(depth+1, fieldName, src.Index(i), ""))
 		}
 		return dst
 
 	case reflect.Array:
-		if src.Len() == 0 {
-			return src // can not access to src.Index(0)
+		if src.Len() == 0 || !mayHoldString(src.Type().Elem()) {
+			return src
 		}
-
 		dst := reflect.New(src.Type()).Elem()
 		for i := 0; i < src.Len(); i++ {
-			dst.Index(i).Set(clone(ctx, fieldName, src.Index(i), "", sensitiveKeys))
+			dst.Index(i).Set(h.clone(depth+1, fieldName, src.Index(i), ""))
 		}
 		return dst
 
 	case reflect.Ptr:
-		dst := reflect.New(src.Elem().Type())
-		copied := clone(ctx, fieldName, src.Elem(), tag, sensitiveKeys)
+		if src.IsNil() {
+			return src
+		}
+		copied := h.clone(depth+1, fieldName, src.Elem(), tag)
+		dst := reflect.New(copied.Type())
 		dst.Elem().Set(copied)
 		return dst
 
@@ -166,11 +187,88 @@ func clone(ctx context.Context, fieldName string, src reflect.Value, tag string,
 		if src.IsNil() {
 			return src
 		}
-		return clone(ctx, fieldName, src.Elem(), tag, sensitiveKeys)
+		return plainString(src.Elem(), h.clone(depth, fieldName, src.Elem(), tag))
 
 	default:
-		dst := reflect.New(src.Type())
-		dst.Elem().Set(src)
-		return dst.Elem()
+		return src
+	}
+}
+
+// plainString turns a redacted value of a named string type into a plain
+// string for slots of interface type. The redact message need not be valid
+// for the named type: a json.Number holding it fails to marshal.
+func plainString(src, copied reflect.Value) reflect.Value {
+	if copied.Kind() != reflect.String || copied.Type() == stringType || copied.String() == src.String() {
+		return copied
+	}
+	return reflect.ValueOf(copied.String())
+}
+
+// isMap reports whether v holds a map, possibly behind interfaces and pointers.
+// Under a sensitive key, maps are redacted as a whole like groups and JSON
+// objects. Structs are censored field by field instead, so their sensitive
+// tags still select partial masks.
+func isMap(v reflect.Value) bool {
+	for v.Kind() == reflect.Interface || v.Kind() == reflect.Ptr {
+		if v.IsNil() {
+			return false
+		}
+		v = v.Elem()
+	}
+	return v.Kind() == reflect.Map
+}
+
+// maskFor returns the mask for a field, preferring the struct tag over the
+// key configuration. The boolean is false when the field is not sensitive.
+func (h *censoringHandler) maskFor(fieldName, tag string) (mask, bool) {
+	if m, ok := tagMask(tag); ok {
+		return m, true
+	}
+	return h.keys.lookup(fieldName, h.sensitiveKeys, h.keyMasks)
+}
+
+// keyLookup is the cached result of matching a field name against the sensitive keys.
+type keyLookup struct {
+	mask      mask
+	sensitive bool
+}
+
+// keyCache memoizes field name lookups so snake casing runs once per name.
+// A nil cache computes every lookup.
+type keyCache struct {
+	entries sync.Map
+	size    sync.Mutex
+	count   int
+}
+
+func (c *keyCache) lookup(fieldName string, sensitiveKeys map[string]struct{}, keyMasks map[string]mask) (mask, bool) {
+	if c != nil {
+		if cached, ok := c.entries.Load(fieldName); ok {
+			l := cached.(keyLookup)
+			return l.mask, l.sensitive
+		}
+	}
+
+	key := snake.SnakeCase(fieldName)
+	_, sensitive := sensitiveKeys[key]
+	if !sensitive {
+		// dotted keys such as "request.header.Authorization" are matched by their last segment
+		if i := strings.LastIndexByte(fieldName, '.'); i >= 0 && i < len(fieldName)-1 {
+			key = snake.SnakeCase(fieldName[i+1:])
+			_, sensitive = sensitiveKeys[key]
+		}
+	}
+	l := keyLookup{mask: keyMasks[key], sensitive: sensitive}
+
+	if c != nil {
+		c.size.Lock()
+		if c.count < maxCachedKeys {
+			// field names may alias reusable buffers, so the cache keeps a copy
+			if _, loaded := c.entries.LoadOrStore(strings.Clone(fieldName), l); !loaded {
+				c.count++
+			}
+		}
+		c.size.Unlock()
 	}
+	return l.mask, l.sensitive
 }
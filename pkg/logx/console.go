@@ -0,0 +1,387 @@
+package logx
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"runtime"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+	"unicode"
+)
+
+const (
+	consoleTimeFormat = "2006-01-02 15:04:05.000"
+	// prettyIndent indents each nesting level of a pretty record
+	prettyIndent = "  "
+
+	ansiReset  = "\x1b[0m"
+	ansiFaint  = "\x1b[2m"
+	ansiRed    = "\x1b[31m"
+	ansiGreen  = "\x1b[32m"
+	ansiYellow = "\x1b[33m"
+	ansiCyan   = "\x1b[36m"
+)
+
+// consoleHandler writes human readable lines for terminals:
+//
+//	2025-07-09 16:14:33.025 INFO  Incoming request request.method=GET request.path=/health
+//
+// Levels are colorized when color is set. Groups are flattened into dotted keys.
+//
+// In pretty mode, groups are written as indented blocks below the line and
+// stack traces frame by frame instead:
+//
+//	2025-07-09 16:14:33.025 ERROR Request failed source=bff
+//	  request:
+//	    method: GET
+//	  error:
+//	    msg: boom
+//	    trace:
+//	      handler.GetDeeplink
+//	          deeplink/deeplink_handler.go:42
+type consoleHandler struct {
+	opts   slog.HandlerOptions
+	color  bool
+	pretty bool
+
+	// mu is shared with derived handlers so lines are never interleaved
+	mu *sync.Mutex
+	w  io.Writer
+
+	// groups are the groups opened with WithGroup
+	groups []string
+	// preformatted holds the attributes added with WithAttrs
+	preformatted []byte
+	// goas are the groups and attributes added in pretty mode, which needs
+	// the attribute tree of each record
+	goas []groupOrAttrs
+}
+
+func newConsoleHandler(w io.Writer, opts *slog.HandlerOptions, color bool) *consoleHandler {
+	h := &consoleHandler{color: color, mu: &sync.Mutex{}, w: w}
+	if opts != nil {
+		h.opts = *opts
+	}
+	return h
+}
+
+func newPrettyHandler(w io.Writer, opts *slog.HandlerOptions, color bool) *consoleHandler {
+	h := newConsoleHandler(w, opts, color)
+	h.pretty = true
+	return h
+}
+
+func (h *consoleHandler) Enabled(_ context.Context, level slog.Level) bool {
+	minLevel := slog.LevelInfo
+	if h.opts.Level != nil {
+		minLevel = h.opts.Level.Level()
+	}
+	return level >= minLevel
+}
+
+func (h *consoleHandler) Handle(_ context.Context, r slog.Record) error {
+	buf := h.appendHeader(make([]byte, 0, 256), r)
+
+	if h.pretty {
+		buf = h.appendPretty(buf, nestAttrs(h.goas, r))
+	} else {
+		buf = append(buf, h.preformatted...)
+		prefix := h.prefix()
+		r.Attrs(func(attr slog.Attr) bool {
+			buf = h.appendAttr(buf, prefix, h.groups, attr)
+			return true
+		})
+		buf = append(buf, '\n')
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	_, err := h.w.Write(buf)
+	return err
+}
+
+// appendHeader appends the time, level, message and source of r.
+func (h *consoleHandler) appendHeader(buf []byte, r slog.Record) []byte {
+	if !r.Time.IsZero() {
+		buf = h.faint(buf, r.Time.Format(consoleTimeFormat))
+		buf = append(buf, ' ')
+	}
+	buf = h.appendLevel(buf, r.Level)
+	buf = append(buf, ' ')
+	buf = append(buf, r.Message...)
+
+	if h.opts.AddSource && r.PC != 0 {
+		frame, _ := runtime.CallersFrames([]uintptr{r.PC}).Next()
+		buf = append(buf, ' ')
+		buf = h.faint(buf, fmt.Sprintf("%s:%d", shortPath(frame.File), frame.Line))
+	}
+	return buf
+}
+
+func (h *consoleHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	if len(attrs) == 0 {
+		return h
+	}
+	h2 := *h
+	if h.pretty {
+		h2.goas = withGroupOrAttrs(h.goas, groupOrAttrs{attrs: attrs})
+		return &h2
+	}
+	h2.preformatted = append([]byte(nil), h.preformatted...)
+	prefix := h.prefix()
+	for _, attr := range attrs {
+		h2.preformatted = h.appendAttr(h2.preformatted, prefix, h.groups, attr)
+	}
+	return &h2
+}
+
+func (h *consoleHandler) WithGroup(name string) slog.Handler {
+	if name == "" {
+		return h
+	}
+	h2 := *h
+	if h.pretty {
+		h2.goas = withGroupOrAttrs(h.goas, groupOrAttrs{group: name})
+		return &h2
+	}
+	h2.groups = append(append([]string(nil), h.groups...), name)
+	return &h2
+}
+
+func (h *consoleHandler) prefix() string {
+	if len(h.groups) == 0 {
+		return ""
+	}
+	return strings.Join(h.groups, ".") + "."
+}
+
+// appendAttr appends " key=value", flattening groups into dotted keys.
+func (h *consoleHandler) appendAttr(buf []byte, prefix string, groups []string, attr slog.Attr) []byte {
+	attr.Value = attr.Value.Resolve()
+	if attr.Value.Kind() != slog.KindGroup && h.opts.ReplaceAttr != nil {
+		attr = h.opts.ReplaceAttr(groups, attr)
+		attr.Value = attr.Value.Resolve()
+	}
+	if attr.Equal(slog.Attr{}) {
+		return buf
+	}
+
+	if attr.Value.Kind() == slog.KindGroup {
+		groupAttrs := attr.Value.Group()
+		if len(groupAttrs) == 0 {
+			return buf
+		}
+		// an inline group with an empty key adds its attributes to the parent
+		if attr.Key != "" {
+			prefix += attr.Key + "."
+			groups = append(groups[:len(groups):len(groups)], attr.Key)
+		}
+		for _, groupAttr := range groupAttrs {
+			buf = h.appendAttr(buf, prefix, groups, groupAttr)
+		}
+		return buf
+	}
+
+	buf = append(buf, ' ')
+	buf = h.faint(buf, prefix+attr.Key+"=")
+	return appendConsoleValue(buf, attr.Value)
+}
+
+// appendPretty appends the scalar attributes to the header line and the
+// groups and stack traces as indented blocks below it.
+func (h *consoleHandler) appendPretty(buf []byte, attrs []slog.Attr) []byte {
+	attrs = h.resolveAttrs(nil, attrs)
+
+	var blocks []slog.Attr
+	for _, attr := range attrs {
+		if isPrettyBlock(attr.Value) {
+			blocks = append(blocks, attr)
+			continue
+		}
+		buf = append(buf, ' ')
+		buf = h.faint(buf, attr.Key+"=")
+		buf = appendConsoleValue(buf, attr.Value)
+	}
+	buf = append(buf, '\n')
+
+	for _, attr := range blocks {
+		buf = h.appendBlock(buf, 1, attr)
+	}
+	return buf
+}
+
+// resolveAttrs resolves the values of attrs and their groups, applies
+// ReplaceAttr and drops empty attributes and groups. Attributes of inline
+// groups with an empty key are added to the parent.
+func (h *consoleHandler) resolveAttrs(groups []string, attrs []slog.Attr) []slog.Attr {
+	resolved := make([]slog.Attr, 0, len(attrs))
+	for _, attr := range attrs {
+		attr.Value = attr.Value.Resolve()
+		if attr.Value.Kind() != slog.KindGroup && h.opts.ReplaceAttr != nil {
+			attr = h.opts.ReplaceAttr(groups, attr)
+			attr.Value = attr.Value.Resolve()
+		}
+		if attr.Equal(slog.Attr{}) {
+			continue
+		}
+		if attr.Value.Kind() != slog.KindGroup {
+			resolved = append(resolved, attr)
+			continue
+		}
+
+		if attr.Key == "" {
+			resolved = append(resolved, h.resolveAttrs(groups, attr.Value.Group())...)
+			continue
+		}
+		groupAttrs := h.resolveAttrs(append(groups[:len(groups):len(groups)], attr.Key), attr.Value.Group())
+		if len(groupAttrs) > 0 {
+			resolved = append(resolved, slog.Attr{Key: attr.Key, Value: slog.GroupValue(groupAttrs...)})
+		}
+	}
+	return resolved
+}
+
+// isPrettyBlock reports whether v is written as a block below the header line.
+func isPrettyBlock(v slog.Value) bool {
+	if v.Kind() == slog.KindGroup {
+		return true
+	}
+	if v.Kind() == slog.KindAny {
+		switch v.Any().(type) {
+		case []stackFrame, []errorCause:
+			return true
+		}
+	}
+	return false
+}
+
+// appendBlock appends attr on its own line at depth, with groups nested below it.
+func (h *consoleHandler) appendBlock(buf []byte, depth int, attr slog.Attr) []byte {
+	indent := strings.Repeat(prettyIndent, depth)
+	buf = append(buf, indent...)
+	buf = h.faint(buf, attr.Key+":")
+
+	switch v := attr.Value.Any().(type) {
+	case []slog.Attr:
+		buf = append(buf, '\n')
+		for _, groupAttr := range v {
+			buf = h.appendBlock(buf, depth+1, groupAttr)
+		}
+		return buf
+	case []stackFrame:
+		buf = append(buf, '\n')
+		return h.appendFrames(buf, depth+1, v)
+	case []errorCause:
+		buf = append(buf, '\n')
+		causeIndent := strings.Repeat(prettyIndent, depth+1)
+		for _, cause := range v {
+			buf = append(buf, causeIndent...)
+			buf = append(buf, "- "...)
+			buf = appendMaybeQuoted(buf, cause.Msg)
+			details := cause.Type
+			if cause.Code != "" {
+				details += " " + cause.Code
+			}
+			if cause.Status != 0 {
+				details += " " + strconv.Itoa(cause.Status)
+			}
+			buf = append(buf, ' ')
+			buf = h.faint(buf, "("+details+")")
+			buf = append(buf, '\n')
+			buf = h.appendFrames(buf, depth+2, cause.Trace)
+		}
+		return buf
+	}
+
+	buf = append(buf, ' ')
+	buf = appendConsoleValue(buf, attr.Value)
+	return append(buf, '\n')
+}
+
+// appendFrames appends one frame per two lines, as Go prints stack traces.
+func (h *consoleHandler) appendFrames(buf []byte, depth int, frames []stackFrame) []byte {
+	indent := strings.Repeat(prettyIndent, depth)
+	for _, frame := range frames {
+		buf = append(buf, indent...)
+		buf = append(buf, frame.Func...)
+		buf = append(buf, '\n')
+		buf = append(buf, indent...)
+		buf = append(buf, "    "...)
+		buf = h.faint(buf, frame.Source+":"+strconv.Itoa(frame.Line))
+		buf = append(buf, '\n')
+	}
+	return buf
+}
+
+func appendConsoleValue(buf []byte, v slog.Value) []byte {
+	switch v.Kind() {
+	case slog.KindString:
+		return appendMaybeQuoted(buf, v.String())
+	case slog.KindTime:
+		return v.Time().AppendFormat(buf, time.RFC3339Nano)
+	case slog.KindAny:
+		if err, ok := v.Any().(error); ok {
+			return appendMaybeQuoted(buf, err.Error())
+		}
+		return appendMaybeQuoted(buf, fmt.Sprintf("%+v", v.Any()))
+	default:
+		return append(buf, v.String()...)
+	}
+}
+
+// appendMaybeQuoted quotes s when it is empty or holds spaces, quotes, '=' or
+// control characters, so every line stays parseable.
+func appendMaybeQuoted(buf []byte, s string) []byte {
+	if s == "" || strings.IndexFunc(s, func(r rune) bool {
+		return unicode.IsSpace(r) || r == '"' || r == '=' || !unicode.IsPrint(r)
+	}) >= 0 {
+		return strconv.AppendQuote(buf, s)
+	}
+	return append(buf, s...)
+}
+
+// appendLevel appends the level padded to a fixed width, colorized by severity.
+func (h *consoleHandler) appendLevel(buf []byte, level slog.Level) []byte {
+	text := fmt.Sprintf("%-5s", level.String())
+	if !h.color {
+		return append(buf, text...)
+	}
+
+	color := ansiCyan
+	switch {
+	case level >= slog.LevelError:
+		color = ansiRed
+	case level >= slog.LevelWarn:
+		color = ansiYellow
+	case level >= slog.LevelInfo:
+		color = ansiGreen
+	}
+	buf = append(buf, color...)
+	buf = append(buf, text...)
+	return append(buf, ansiReset...)
+}
+
+func (h *consoleHandler) faint(buf []byte, s string) []byte {
+	if !h.color {
+		return append(buf, s...)
+	}
+	buf = append(buf, ansiFaint...)
+	buf = append(buf, s...)
+	return append(buf, ansiReset...)
+}
+
+// shortPath keeps the last directory and the file name of a source path.
+func shortPath(file string) string {
+	i := strings.LastIndexByte(file, '/')
+	if i < 0 {
+		return file
+	}
+	if j := strings.LastIndexByte(file[:i], '/'); j >= 0 {
+		return file[j+1:]
+	}
+	return file
+}
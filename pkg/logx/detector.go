@@ -0,0 +1,212 @@
+package logx
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// Detector names a built-in value-level PII detector.
+// Detectors redact sensitive values found inside any string, regardless of the field name.
+type Detector string
+
+const (
+	// DetectorPAN matches Luhn-valid payment card numbers (13-19 digits, optionally space or dash separated).
+	DetectorPAN Detector = "pan"
+	// DetectorThaiNationalID matches 13-digit Thai national ID numbers with a valid check digit.
+	DetectorThaiNationalID Detector = "thai_national_id"
+	// DetectorPhone matches Thai and E.164 formatted phone numbers.
+	DetectorPhone Detector = "phone"
+	// DetectorEmail matches email addresses.
+	DetectorEmail Detector = "email"
+	// DetectorJWT matches JSON Web Tokens.
+	DetectorJWT Detector = "jwt"
+	// DetectorBearer matches bearer tokens such as the value of an Authorization header.
+	DetectorBearer Detector = "bearer"
+)
+
+// valueDetector finds one kind of sensitive value inside a string.
+type valueDetector struct {
+	name Detector
+	// hint is a cheap check that must pass before the pattern is run
+	hint func(s string) bool
+	// pattern matches candidates; a first capture group is kept unredacted
+	pattern *regexp.Regexp
+	// valid filters candidates, e.g. by checksum; nil accepts every match
+	valid func(match string) bool
+}
+
+// builtinDetectors are applied in this order. Token detectors run first so
+// digits inside a token are not reported as a card or phone number.
+var builtinDetectors = []valueDetector{
+	{
+		name:    DetectorJWT,
+		hint:    func(s string) bool { return strings.Contains(s, "eyJ") },
+		pattern: regexp.MustCompile(`\beyJ[A-Za-z0-9_-]+\.eyJ[A-Za-z0-9_-]+\.[A-Za-z0-9_-]*`),
+	},
+	{
+		name:    DetectorBearer,
+		hint:    func(s string) bool { return strings.Contains(s, "earer") || strings.Contains(s, "EARER") },
+		pattern: regexp.MustCompile(`(?i)(\bbearer\s+)[A-Za-z0-9._~+/-]+=*`),
+	},
+	{
+		name:    DetectorEmail,
+		hint:    func(s string) bool { return strings.IndexByte(s, '@') >= 0 },
+		pattern: regexp.MustCompile(`[A-Za-z0-9._%+-]+@[A-Za-z0-9-]+(?:\.[A-Za-z0-9-]+)*\.[A-Za-z]{2,}`),
+	},
+	{
+		name:    DetectorThaiNationalID,
+		hint:    hasDigitRun(13),
+		pattern: regexp.MustCompile(`\b\d[- ]?\d{4}[- ]?\d{5}[- ]?\d{2}[- ]?\d\b`),
+		valid:   validThaiNationalID,
+	},
+	{
+		name:    DetectorPAN,
+		hint:    hasDigitRun(13),
+		pattern: regexp.MustCompile(`\b\d(?:[ -]?\d){12,18}\b`),
+		valid:   validLuhn,
+	},
+	{
+		name:    DetectorPhone,
+		hint:    hasDigitRun(9),
+		pattern: regexp.MustCompile(`(?:\+\d{1,3}[- ]?|\b0)\d{1,2}[- ]?\d{3,4}[- ]?\d{4}\b`),
+	},
+}
+
+// defaultDetectors enables every built-in detector.
+func defaultDetectors() map[Detector]bool {
+	enabled := make(map[Detector]bool, len(builtinDetectors))
+	for _, d := range builtinDetectors {
+		enabled[d.name] = true
+	}
+	return enabled
+}
+
+// newDetectors returns the enabled detectors in application order.
+func newDetectors(enabled map[Detector]bool) []valueDetector {
+	detectors := make([]valueDetector, 0, len(builtinDetectors))
+	for _, d := range builtinDetectors {
+		if enabled[d.name] {
+			detectors = append(detectors, d)
+		}
+	}
+	return detectors
+}
+
+func validateDetector(detector Detector) error {
+	for _, d := range builtinDetectors {
+		if d.name == detector {
+			return nil
+		}
+	}
+	return fmt.Errorf("unknown detector %q", detector)
+}
+
+// redactValue replaces every sensitive value the detectors find in s with message.
+func redactValue(s string, detectors []valueDetector, message string) string {
+	for _, d := range detectors {
+		if !d.hint(s) {
+			continue
+		}
+		s = replaceMatches(s, d, message)
+	}
+	return s
+}
+
+func replaceMatches(s string, d valueDetector, message string) string {
+	matches := d.pattern.FindAllStringSubmatchIndex(s, -1)
+	if matches == nil {
+		return s
+	}
+
+	var b strings.Builder
+	last := 0
+	for _, m := range matches {
+		start, end := m[0], m[1]
+		if d.valid != nil && !d.valid(s[start:end]) {
+			continue
+		}
+		// keep the first capture group, e.g. the "Bearer " prefix
+		if len(m) > 2 && m[2] >= 0 {
+			start = m[3]
+		}
+		b.WriteString(s[last:start])
+		b.WriteString(message)
+		last = end
+	}
+	if last == 0 {
+		return s
+	}
+	b.WriteString(s[last:])
+	return b.String()
+}
+
+// hasDigitRun returns a hint that passes when s holds a run of at least n
+// digits, where single space or dash separators do not break the run.
+// Identifiers such as UUIDs hold many digits but never a long run, so they
+// skip the patterns entirely.
+func hasDigitRun(n int) func(string) bool {
+	return func(s string) bool {
+		run := 0
+		for i := 0; i < len(s); i++ {
+			switch c := s[i]; {
+			case c >= '0' && c <= '9':
+				run++
+				if run >= n {
+					return true
+				}
+			case (c == ' ' || c == '-') && run > 0 && i+1 < len(s) && s[i+1] >= '0' && s[i+1] <= '9':
+				// a single separator inside a number
+			default:
+				run = 0
+			}
+		}
+		return false
+	}
+}
+
+// digitsOf strips separators from a matched number.
+func digitsOf(s string) []byte {
+	digits := make([]byte, 0, len(s))
+	for i := 0; i < len(s); i++ {
+		if s[i] >= '0' && s[i] <= '9' {
+			digits = append(digits, s[i]-'0')
+		}
+	}
+	return digits
+}
+
+func validLuhn(match string) bool {
+	digits := digitsOf(match)
+	if len(digits) < 13 || len(digits) > 19 {
+		return false
+	}
+
+	sum := 0
+	double := false
+	for i := len(digits) - 1; i >= 0; i-- {
+		d := int(digits[i])
+		if double {
+			d *= 2
+			if d > 9 {
+				d -= 9
+			}
+		}
+		sum += d
+		double = !double
+	}
+	return sum%10 == 0
+}
+
+func validThaiNationalID(match string) bool {
+	digits := digitsOf(match)
+	if len(digits) != 13 {
+		return false
+	}
+
+	sum := 0
+	for i := 0; i < 12; i++ {
+		sum += int(digits[i]) * (13 - i)
+	}
+	return (11-sum%11)%10 == int(digits[12])
+}
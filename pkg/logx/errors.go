@@ -0,0 +1,182 @@
+package logx
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"path/filepath"
+
+	"github.com/mdobak/go-xerrors"
+)
+
+// maxCauses bounds the causes rendered for one error.
+const maxCauses = 32
+
+// ErrorCoder is implemented by errors carrying an application error code,
+// e.g. "DL9999". The code is rendered as the code field of the error.
+type ErrorCoder interface {
+	error
+	ErrorCode() string
+}
+
+// StatusCoder is implemented by errors carrying the HTTP status they are
+// reported with. The status is rendered as the status field of the error.
+type StatusCoder interface {
+	error
+	StatusCode() int
+}
+
+type stackFrame struct {
+	Func   string `json:"func"`
+	Source string `json:"source"`
+	Line   int    `json:"line"`
+}
+
+// errorCause is one error of the cause chain.
+type errorCause struct {
+	Msg    string       `json:"msg"`
+	Type   string       `json:"type"`
+	Code   string       `json:"code,omitempty"`
+	Status int          `json:"status,omitempty"`
+	Trace  []stackFrame `json:"trace,omitempty"`
+}
+
+func replaceErrorAttribute(groups []string, attr slog.Attr) slog.Attr {
+	switch attr.Value.Kind() {
+	case slog.KindAny:
+		switch v := attr.Value.Any().(type) {
+		case error:
+			attr.Value = formatErrorWithTrace(v)
+		}
+	}
+
+	return attr
+}
+
+// extractStackFrames extracts and formats stack frames from an error
+func extractStackFrames(err error) []stackFrame {
+	trace := xerrors.StackTrace(err)
+
+	if len(trace) == 0 {
+		return nil
+	}
+
+	return formatFrames(trace.Frames())
+}
+
+func formatFrames(frames []xerrors.Frame) []stackFrame {
+	formattedFrames := make([]stackFrame, len(frames))
+
+	for i, frame := range frames {
+		formattedFrames[i] = stackFrame{
+			Source: filepath.Join(
+				filepath.Base(filepath.Dir(frame.File)),
+				filepath.Base(frame.File),
+			),
+			Func: filepath.Base(frame.Function),
+			Line: frame.Line,
+		}
+	}
+
+	return formattedFrames
+}
+
+// formatErrorWithTrace returns a slog.Value containing the error message and stack trace.
+// If the error doesn't implement the StackTrace interface, only the message is included.
+//
+// The code and status come from the first error in the chain implementing
+// ErrorCoder and StatusCoder. When the error wraps or joins other errors, the
+// whole chain is rendered as causes, depth first. A cause only lists the stack
+// frames not rendered before it.
+func formatErrorWithTrace(err error) slog.Value {
+	var attributes []slog.Attr
+
+	attributes = append(attributes, slog.String("msg", err.Error()))
+
+	var coder ErrorCoder
+	if errors.As(err, &coder) {
+		attributes = append(attributes, slog.String("code", coder.ErrorCode()))
+	}
+	var statusCoder StatusCoder
+	if errors.As(err, &statusCoder) {
+		attributes = append(attributes, slog.Int("status", statusCoder.StatusCode()))
+	}
+
+	seen := make(map[stackFrame]struct{})
+	frames := extractStackFrames(err)
+	if frames != nil {
+		for _, frame := range frames {
+			seen[frame] = struct{}{}
+		}
+		attributes = append(attributes,
+			slog.Any("trace", frames),
+		)
+	}
+
+	if causes := collectCauses(err, seen); len(causes) > 1 {
+		attributes = append(attributes, slog.Any("causes", causes))
+	}
+
+	return slog.GroupValue(attributes...)
+}
+
+// collectCauses flattens the tree of err into a list, depth first. Wrappers
+// only adding a stack trace, such as xerrors.WithStackTrace, are merged into
+// the error they wrap.
+func collectCauses(err error, seen map[stackFrame]struct{}) []errorCause {
+	var causes []errorCause
+	var walk func(err error, inherited []xerrors.Frame)
+	walk = func(err error, inherited []xerrors.Frame) {
+		if err == nil || len(causes) >= maxCauses {
+			return
+		}
+
+		frames := inherited
+		if tracer, ok := err.(xerrors.StackTracer); ok {
+			frames = append(frames, tracer.StackTrace().Frames()...)
+		}
+
+		children := unwrapAll(err)
+		if len(children) == 1 && children[0] != nil && children[0].Error() == err.Error() {
+			walk(children[0], frames)
+			return
+		}
+
+		cause := errorCause{Msg: err.Error(), Type: fmt.Sprintf("%T", err)}
+		if coder, ok := err.(ErrorCoder); ok {
+			cause.Code = coder.ErrorCode()
+		}
+		if statusCoder, ok := err.(StatusCoder); ok {
+			cause.Status = statusCoder.StatusCode()
+		}
+		for _, frame := range formatFrames(frames) {
+			if _, ok := seen[frame]; ok {
+				continue
+			}
+			seen[frame] = struct{}{}
+			cause.Trace = append(cause.Trace, frame)
+		}
+		causes = append(causes, cause)
+
+		for _, child := range children {
+			walk(child, nil)
+		}
+	}
+	walk(err, nil)
+	return causes
+}
+
+// unwrapAll returns the errors err wraps, supporting errors.Join and xerrors lists.
+func unwrapAll(err error) []error {
+	switch e := err.(type) {
+	case interface{ Unwrap() []error }:
+		return e.Unwrap()
+	case xerrors.MultiError:
+		return e.Errors()
+	case interface{ Unwrap() error }:
+		if inner := e.Unwrap(); inner != nil {
+			return []error{inner}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,152 @@
+package logx
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"testing"
+
+	"github.com/mdobak/go-xerrors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// codedError stands for an application error such as domain.Error.
+type codedError struct {
+	code   string
+	status int
+	msg    string
+}
+
+func (e *codedError) Error() string     { return e.msg }
+func (e *codedError) ErrorCode() string { return e.code }
+func (e *codedError) StatusCode() int   { return e.status }
+
+type renderedError struct {
+	Msg    string       `json:"msg"`
+	Code   string       `json:"code"`
+	Status int          `json:"status"`
+	Trace  []stackFrame `json:"trace"`
+	Causes []errorCause `json:"causes"`
+}
+
+func logError(t *testing.T, err error) renderedError {
+	t.Helper()
+	buf := &bytes.Buffer{}
+	logger, err2 := New(Config{}, WithWriter(buf))
+	require.NoError(t, err2)
+	logger.Error("failed", slog.Any("error", err))
+
+	var output struct {
+		Error renderedError `json:"error"`
+	}
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &output))
+	return output.Error
+}
+
+func TestErrorRendering(t *testing.T) {
+	expired := &codedError{code: "DL4021", status: http.StatusBadRequest, msg: "deeplink expired"}
+
+	tests := []struct {
+		name       string
+		err        error
+		wantCode   string
+		wantStatus int
+		wantCauses []string
+	}{
+		{
+			name: "plain error has no causes",
+			err:  errors.New("boom"),
+		},
+		{
+			name:       "coded error",
+			err:        expired,
+			wantCode:   "DL4021",
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name:       "wrapped chain",
+			err:        fmt.Errorf("get deeplink: %w", fmt.Errorf("resolve: %w", expired)),
+			wantCode:   "DL4021",
+			wantStatus: http.StatusBadRequest,
+			wantCauses: []string{"get deeplink: resolve: deeplink expired", "resolve: deeplink expired", "deeplink expired"},
+		},
+		{
+			name:       "joined errors",
+			err:        errors.Join(errors.New("close file"), fmt.Errorf("flush: %w", expired)),
+			wantCode:   "DL4021",
+			wantStatus: http.StatusBadRequest,
+			wantCauses: []string{"close file\nflush: deeplink expired", "close file", "flush: deeplink expired", "deeplink expired"},
+		},
+		{
+			name:       "xerrors list",
+			err:        xerrors.Append(errors.New("a"), errors.New("b")),
+			wantCauses: []string{"the following errors occurred: [a, b]", "a", "b"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rendered := logError(t, tt.err)
+			assert.Equal(t, tt.err.Error(), rendered.Msg)
+			assert.Equal(t, tt.wantCode, rendered.Code)
+			assert.Equal(t, tt.wantStatus, rendered.Status)
+
+			var causes []string
+			for _, cause := range rendered.Causes {
+				causes = append(causes, cause.Msg)
+			}
+			assert.Equal(t, tt.wantCauses, causes)
+		})
+	}
+}
+
+func TestErrorRenderingCauseFields(t *testing.T) {
+	expired := &codedError{code: "DL4021", status: http.StatusBadRequest, msg: "deeplink expired"}
+	rendered := logError(t, fmt.Errorf("get deeplink: %w", expired))
+
+	require.Len(t, rendered.Causes, 2)
+	assert.Equal(t, "*fmt.wrapError", rendered.Causes[0].Type)
+	assert.Empty(t, rendered.Causes[0].Code)
+	assert.Equal(t, "*logx.codedError", rendered.Causes[1].Type)
+	assert.Equal(t, "DL4021", rendered.Causes[1].Code)
+	assert.Equal(t, http.StatusBadRequest, rendered.Causes[1].Status)
+}
+
+func newInnerError() error {
+	return xerrors.New("connection refused")
+}
+
+func TestErrorRenderingDedupesFrames(t *testing.T) {
+	inner := newInnerError()
+	err := xerrors.New("get deeplink", inner)
+
+	rendered := logError(t, err)
+	require.NotEmpty(t, rendered.Trace)
+	require.Len(t, rendered.Causes, 2, "the stack trace wrappers are merged into their errors")
+	assert.Equal(t, "get deeplink: connection refused", rendered.Causes[0].Msg)
+	assert.Empty(t, rendered.Causes[0].Trace, "its frames are the top level trace")
+	assert.Equal(t, "connection refused", rendered.Causes[1].Msg)
+
+	// the inner cause only lists the frames not rendered before it
+	require.NotEmpty(t, rendered.Causes[1].Trace)
+	assert.Equal(t, "logx.newInnerError", rendered.Causes[1].Trace[0].Func)
+	seen := make(map[stackFrame]bool)
+	for _, frame := range rendered.Trace {
+		seen[frame] = true
+	}
+	for _, frame := range rendered.Causes[1].Trace {
+		assert.False(t, seen[frame], "frame %v is rendered twice", frame)
+	}
+}
+
+func TestErrorRenderingIsCensored(t *testing.T) {
+	rendered := logError(t, fmt.Errorf("charge card 4111111111111111: %w", errors.New("declined")))
+	assert.Equal(t, "charge card *: declined", rendered.Msg)
+	for _, cause := range rendered.Causes {
+		assert.NotContains(t, cause.Msg, "4111111111111111")
+	}
+}
@@ -19,13 +19,30 @@ type censoringHandler struct {
 	handler slog.Handler
 	// withDebug enables debug mode which bypasses censoring
 	withDebug bool
-	// sensitiveKeys defines the set of field names to be redacted
+	// sensitiveKeys defines the set of field names to be redacted. It is built
+	// by New for each logger and never modified afterwards.
 	sensitiveKeys map[string]struct{}
+	// detectors redact sensitive values inside strings regardless of the field name
+	detectors []valueDetector
+	// keyMasks holds the masking strategy of sensitive keys that are not fully redacted
+	keyMasks map[string]mask
+	// redaction holds the redact message and the salt of the hash masking strategy
+	redaction redaction
+	// keys caches sensitive key lookups by field name; it is shared with derived handlers
+	keys *keyCache
+	// levels filters records by the runtime adjustable level of the named logger
+	levels *Levels
+	// name is the logger name set with Named, used to look up level overrides
+	name string
 }
 
 // Enabled reports whether the handler handles records at the given level.
-// The implementation delegates to the underlying handler's Enabled method.
+// The level of the named logger is checked first, then the implementation
+// delegates to the underlying handler's Enabled method.
 func (h *censoringHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	if h.levels != nil && !h.levels.Enabled(h.name, level) {
+		return false
+	}
 	return h.handler.Enabled(ctx, level)
 }
 
@@ -38,64 +55,20 @@ func (h *censoringHandler) Enabled(ctx context.Context, level slog.Level) bool {
 //   - Creating a new record with censored attributes
 //   - Forwarding the censored record to the underlying handler
 func (h *censoringHandler) Handle(ctx context.Context, r slog.Record) error {
-
 	ctxAttrs := getAttrsFromContext(ctx)
-	totalCap := r.NumAttrs()
-	if ctxAttrs != nil {
-		totalCap += len(ctxAttrs)
-	}
-
-	newAttrs := make([]slog.Attr, 0, totalCap)
-	// Add context attributes first
-	if ctxAttrs != nil {
-		for _, attr := range ctxAttrs {
-			if attr.Value.Kind() == slog.KindGroup {
-				groupAttrs := attr.Value.Group()
-				newGroupAttrs := make([]slog.Attr, 0, len(groupAttrs))
+	newAttrs := make([]slog.Attr, 0, len(ctxAttrs)+r.NumAttrs())
 
-				for _, groupAttr := range groupAttrs {
-					newGroupAttrs = append(newGroupAttrs, h.censorAttribute(groupAttr))
-				}
-
-				anyGroupAttrs := make([]any, len(newGroupAttrs))
-				for i, groupAttr := range newGroupAttrs {
-					anyGroupAttrs[i] = groupAttr
-				}
-				newAttrs = append(newAttrs, slog.Group(attr.Key, anyGroupAttrs...))
-			} else {
-				newAttrs = append(newAttrs, h.censorAttribute(attr))
-			}
-		}
+	// Add context attributes first. Groups are censored at every depth.
+	for _, attr := range ctxAttrs {
+		newAttrs = append(newAttrs, h.censorAttribute(attr))
 	}
-
 	r.Attrs(func(attr slog.Attr) bool {
-		// Handle both direct attributes and groups
-		if attr.Value.Kind() == slog.KindGroup {
-			// Process group attributes
-			groupAttrs := attr.Value.Group()
-			newGroupAttrs := make([]slog.Attr, 0, len(groupAttrs))
-
-			// Censor each attribute in the group
-			for _, groupAttr := range groupAttrs {
-				newGroupAttrs = append(newGroupAttrs, h.censorAttribute(groupAttr))
-			}
-
-			// Create new group with censored attributes
-			anyGroupAttrs := make([]any, len(newGroupAttrs))
-			for i, groupAttr := range newGroupAttrs {
-				anyGroupAttrs[i] = groupAttr
-			}
-			newAttrs = append(newAttrs, slog.Group(attr.Key, anyGroupAttrs...))
-		} else {
-			newAttrs = append(newAttrs, h.censorAttribute(attr))
-		}
+		newAttrs = append(newAttrs, h.censorAttribute(attr))
 		return true
 	})
 
-	newRecord := slog.NewRecord(r.Time, r.Level, r.Message, r.PC)
-	for _, attr := range newAttrs {
-		newRecord.AddAttrs(attr)
-	}
+	newRecord := slog.NewRecord(r.Time, r.Level, h.censorMessage(r.Message), r.PC)
+	newRecord.AddAttrs(newAttrs...)
 
 	return h.handler.Handle(ctx, newRecord)
 }
@@ -104,13 +77,30 @@ func (h *censoringHandler) Handle(ctx context.Context, r slog.Record) error {
 // consist of both the receiver's attributes and the arguments. The receiver's attributes
 // appear first.
 //
+// The attributes are censored once here, before the underlying handler
+// pre-formats them, so values added with slog.Logger.With are redacted too.
 // The returned Handler maintains the same censoring configuration while wrapping a new
 // underlying handler with the additional attributes.
 func (h *censoringHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	name := h.name
+	for _, attr := range attrs {
+		if attr.Key == LoggerKey && attr.Value.Kind() == slog.KindString {
+			name = attr.Value.String()
+		}
+	}
+	if !h.withDebug {
+		attrs = h.censorAttrs(0, attrs)
+	}
 	return &censoringHandler{
 		handler:       h.handler.WithAttrs(attrs),
 		withDebug:     h.withDebug,
 		sensitiveKeys: h.sensitiveKeys,
+		detectors:     h.detectors,
+		keyMasks:      h.keyMasks,
+		redaction:     h.redaction,
+		keys:          h.keys,
+		levels:        h.levels,
+		name:          name,
 	}
 }
 
@@ -125,6 +115,12 @@ func (h *censoringHandler) WithGroup(name string) slog.Handler {
 		handler:       h.handler.WithGroup(name),
 		withDebug:     h.withDebug,
 		sensitiveKeys: h.sensitiveKeys,
+		detectors:     h.detectors,
+		keyMasks:      h.keyMasks,
+		redaction:     h.redaction,
+		keys:          h.keys,
+		levels:        h.levels,
+		name:          h.name,
 	}
 }
 
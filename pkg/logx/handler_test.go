@@ -0,0 +1,221 @@
+package logx
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"log/slog"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// credentials resolves to a group through slog.LogValuer.
+type credentials struct {
+	password string
+}
+
+func (c credentials) LogValue() slog.Value {
+	return slog.GroupValue(slog.String("password", c.password))
+}
+
+// valueAt walks a decoded JSON record along a dotted path.
+func valueAt(t *testing.T, record map[string]any, path string) any {
+	t.Helper()
+	var current any = record
+	for _, key := range strings.Split(path, ".") {
+		object, ok := current.(map[string]any)
+		require.True(t, ok, "%s: %q is not an object", path, key)
+		current, ok = object[key]
+		require.True(t, ok, "%s: missing %q", path, key)
+	}
+	return current
+}
+
+func TestCensoringHandlerDepth(t *testing.T) {
+	tests := []struct {
+		name string
+		log  func(*slog.Logger)
+		// want maps a dotted path in the output to its expected value
+		want map[string]any
+	}{
+		{
+			name: "record attr",
+			log: func(l *slog.Logger) {
+				l.Info("msg", slog.String("password", "secret"), slog.String("name", "John"))
+			},
+			want: map[string]any{"password": DefaultRedactMessage, "name": "John"},
+		},
+		{
+			name: "group one level",
+			log: func(l *slog.Logger) {
+				l.Info("msg", slog.Group("user", slog.String("email", "john@example.com")))
+			},
+			want: map[string]any{"user.email": DefaultRedactMessage},
+		},
+		{
+			name: "nested request header group",
+			log: func(l *slog.Logger) {
+				l.Info("msg", slog.Group("request",
+					slog.String("method", "GET"),
+					slog.Group("header",
+						slog.String("Authorization", "Basic dXNlcjpwYXNz"),
+						slog.String("Accept", "application/json"),
+					),
+				))
+			},
+			want: map[string]any{
+				"request.method":               "GET",
+				"request.header.Authorization": DefaultRedactMessage,
+				"request.header.Accept":        "application/json",
+			},
+		},
+		{
+			name: "deeply nested group",
+			log: func(l *slog.Logger) {
+				l.Info("msg", slog.Group("a", slog.Group("b", slog.Group("c", slog.Group("d",
+					slog.String("api_key", "k-123"),
+					slog.String("body", `{"token":"t-1","id":"1"}`),
+				)))))
+			},
+			want: map[string]any{
+				"a.b.c.d.api_key": DefaultRedactMessage,
+				"a.b.c.d.body":    `{"id":"1","token":"*"}`,
+			},
+		},
+		{
+			name: "dotted key",
+			log: func(l *slog.Logger) {
+				l.Info("msg", slog.String("request.header.Authorization", "Basic dXNlcjpwYXNz"))
+			},
+			want: map[string]any{},
+		},
+		{
+			name: "logger with attrs",
+			log: func(l *slog.Logger) {
+				l.With(slog.String("password", "secret"), slog.String("service", "bff")).Info("msg")
+			},
+			want: map[string]any{"password": DefaultRedactMessage, "service": "bff"},
+		},
+		{
+			name: "logger with nested group attrs",
+			log: func(l *slog.Logger) {
+				l.With(slog.Group("request", slog.Group("header", slog.String("Authorization", "Basic dXNlcjpwYXNz")))).Info("msg")
+			},
+			want: map[string]any{"request.header.Authorization": DefaultRedactMessage},
+		},
+		{
+			name: "logger with group",
+			log: func(l *slog.Logger) {
+				l.WithGroup("request").WithGroup("header").Info("msg", slog.String("Authorization", "Basic dXNlcjpwYXNz"))
+			},
+			want: map[string]any{"request.header.Authorization": DefaultRedactMessage},
+		},
+		{
+			name: "logger with group then attrs",
+			log: func(l *slog.Logger) {
+				l.WithGroup("user").With(slog.String("email", "john@example.com")).Info("msg", slog.String("name", "John"))
+			},
+			want: map[string]any{"user.email": DefaultRedactMessage, "user.name": "John"},
+		},
+		{
+			name: "context group",
+			log: func(l *slog.Logger) {
+				ctx := AppendCtx(context.Background(), slog.Group("client", slog.Group("auth", slog.String("token", "t-1"))))
+				l.InfoContext(ctx, "msg")
+			},
+			want: map[string]any{"client.auth.token": DefaultRedactMessage},
+		},
+		{
+			name: "log valuer in group",
+			log: func(l *slog.Logger) {
+				l.Info("msg", slog.Group("user", slog.Any("credentials", credentials{password: "p-1"})))
+			},
+			want: map[string]any{"user.credentials.password": DefaultRedactMessage},
+		},
+		{
+			name: "sensitive group",
+			log: func(l *slog.Logger) {
+				l.Info("msg", slog.Group("password", slog.String("old", "p-1"), slog.String("new", "p-2")))
+			},
+			want: map[string]any{"password": DefaultRedactMessage},
+		},
+		{
+			name: "nested sensitive group",
+			log: func(l *slog.Logger) {
+				l.Info("msg", slog.Group("request", slog.Group("session", slog.String("id", "s-1")), slog.String("path", "/")))
+			},
+			want: map[string]any{"request.session": DefaultRedactMessage, "request.path": "/"},
+		},
+		{
+			name: "log valuer under sensitive key",
+			log: func(l *slog.Logger) {
+				l.Info("msg", slog.Any("token", credentials{password: "p-1"}))
+			},
+			want: map[string]any{"token": DefaultRedactMessage},
+		},
+		{
+			name: "map under sensitive key",
+			log: func(l *slog.Logger) {
+				l.Info("msg", slog.Any("session", map[string]any{"id": "s-1"}))
+			},
+			want: map[string]any{"session": DefaultRedactMessage},
+		},
+		{
+			name: "nested map under sensitive key",
+			log: func(l *slog.Logger) {
+				l.Info("msg", slog.Any("user", map[string]any{"cookie": map[string]string{"sid": "s-1"}, "id": "1"}))
+			},
+			want: map[string]any{"user.cookie": DefaultRedactMessage, "user.id": "1"},
+		},
+		{
+			name: "number under sensitive key",
+			log: func(l *slog.Logger) {
+				l.Info("msg", slog.Int("cvv", 98765432), slog.Int("status", 200))
+			},
+			want: map[string]any{"cvv": DefaultRedactMessage, "status": float64(200)},
+		},
+		{
+			name: "json number under sensitive key",
+			log: func(l *slog.Logger) {
+				l.Info("msg", slog.Any("customer_id", json.Number("98765432")),
+					slog.Any("body", map[string]any{"cvv": json.Number("98765432"), "amount": json.Number("10")}))
+			},
+			want: map[string]any{"customer_id": DefaultRedactMessage, "body.cvv": DefaultRedactMessage, "body.amount": float64(10)},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			buf := &bytes.Buffer{}
+			logger, err := New(Config{Environment: "test", Source: "test-app"}, WithWriter(buf))
+			require.NoError(t, err)
+
+			tt.log(logger)
+
+			var record map[string]any
+			require.NoError(t, json.Unmarshal(buf.Bytes(), &record))
+			for path, want := range tt.want {
+				assert.Equal(t, want, valueAt(t, record, path), path)
+			}
+			assert.NotContains(t, buf.String(), "dXNlcjpwYXNz")
+			for _, secret := range []string{"p-1", "p-2", "s-1", "98765432"} {
+				assert.NotContains(t, buf.String(), secret)
+			}
+		})
+	}
+}
+
+func TestCensoringHandlerWithAttrsDebug(t *testing.T) {
+	buf := &bytes.Buffer{}
+	logger, err := New(Config{Environment: "test", Source: "test-app"}, WithWriter(buf), WithDebugMode(true))
+	require.NoError(t, err)
+
+	logger.With(slog.String("password", "secret")).Info("msg")
+
+	var record map[string]any
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &record))
+	assert.Equal(t, "secret", record["password"])
+}
@@ -0,0 +1,162 @@
+package logx
+
+import (
+	"fmt"
+	"log/slog"
+	"strings"
+	"sync"
+	"sync/atomic"
+)
+
+// LoggerKey is the attribute key naming a logger, see Named.
+// Level overrides are matched against this name.
+const LoggerKey = "logger"
+
+// Levels holds log levels that can be changed at runtime: a base level backed
+// by slog.LevelVar and optional overrides per logger name. It is safe for
+// concurrent use.
+//
+// An override applies to the named logger and to every logger below it, so an
+// override for "deeplink" also applies to "deeplink.resolve". The most
+// specific override wins.
+type Levels struct {
+	base *slog.LevelVar
+	// floor is the lowest enabled level across the base level and the
+	// overrides. The underlying handler is filtered by it.
+	floor *slog.LevelVar
+
+	// mu serializes writers; readers load the overrides snapshot without locking
+	mu        sync.Mutex
+	overrides atomic.Pointer[map[string]slog.Level]
+}
+
+// NewLevels creates Levels with the given base level and no overrides.
+func NewLevels(level slog.Level) *Levels {
+	l := &Levels{
+		base:  new(slog.LevelVar),
+		floor: new(slog.LevelVar),
+	}
+	l.base.Set(level)
+	l.floor.Set(level)
+	l.overrides.Store(&map[string]slog.Level{})
+	return l
+}
+
+// Level returns the base level.
+func (l *Levels) Level() slog.Level {
+	return l.base.Level()
+}
+
+// SetLevel changes the base level.
+func (l *Levels) SetLevel(level slog.Level) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.base.Set(level)
+	l.updateFloor(*l.overrides.Load())
+}
+
+// Overrides returns a copy of the level overrides keyed by logger name.
+func (l *Levels) Overrides() map[string]slog.Level {
+	overrides := *l.overrides.Load()
+	copied := make(map[string]slog.Level, len(overrides))
+	for name, level := range overrides {
+		copied[name] = level
+	}
+	return copied
+}
+
+// SetOverrides replaces every level override. A nil or empty map removes them all.
+func (l *Levels) SetOverrides(overrides map[string]slog.Level) error {
+	copied := make(map[string]slog.Level, len(overrides))
+	for name, level := range overrides {
+		name = strings.TrimSpace(name)
+		if name == "" {
+			return fmt.Errorf("level override must name a logger")
+		}
+		copied[name] = level
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.overrides.Store(&copied)
+	l.updateFloor(copied)
+	return nil
+}
+
+// Set changes the base level and replaces every override at once.
+func (l *Levels) Set(level slog.Level, overrides map[string]slog.Level) error {
+	if err := l.SetOverrides(overrides); err != nil {
+		return err
+	}
+	l.SetLevel(level)
+	return nil
+}
+
+// Enabled reports whether a record at level is logged by the named logger.
+// An empty name uses the base level.
+func (l *Levels) Enabled(name string, level slog.Level) bool {
+	return level >= l.levelFor(name)
+}
+
+// levelFor returns the level of the most specific override matching name,
+// falling back to the base level.
+func (l *Levels) levelFor(name string) slog.Level {
+	overrides := *l.overrides.Load()
+	if len(overrides) == 0 || name == "" {
+		return l.base.Level()
+	}
+
+	for {
+		if level, ok := overrides[name]; ok {
+			return level
+		}
+		i := strings.LastIndexByte(name, '.')
+		if i < 0 {
+			return l.base.Level()
+		}
+		name = name[:i]
+	}
+}
+
+// updateFloor must be called with mu held.
+func (l *Levels) updateFloor(overrides map[string]slog.Level) {
+	floor := l.base.Level()
+	for _, level := range overrides {
+		if level < floor {
+			floor = level
+		}
+	}
+	l.floor.Set(floor)
+}
+
+// ParseLevel parses a level name such as "debug", "INFO" or "warn+2".
+func ParseLevel(s string) (slog.Level, error) {
+	var level slog.Level
+	if err := level.UnmarshalText([]byte(strings.TrimSpace(s))); err != nil {
+		return 0, fmt.Errorf("invalid log level %q: %w", s, err)
+	}
+	return level, nil
+}
+
+// ParseLevelOverrides parses level overrides keyed by logger name, e.g.
+// {"deeplink_service": "debug"}.
+func ParseLevelOverrides(overrides map[string]string) (map[string]slog.Level, error) {
+	levels := make(map[string]slog.Level, len(overrides))
+	for name, s := range overrides {
+		level, err := ParseLevel(s)
+		if err != nil {
+			return nil, fmt.Errorf("invalid level override for %q: %w", name, err)
+		}
+		levels[name] = level
+	}
+	return levels, nil
+}
+
+// Named returns a logger whose records carry the given name under LoggerKey.
+// Level overrides set on Levels apply to it. Names are dot separated, e.g.
+// "deeplink_service.resolve".
+func Named(logger *slog.Logger, name string) *slog.Logger {
+	return logger.With(slog.String(LoggerKey, name))
+}
@@ -0,0 +1,116 @@
+package logx
+
+import (
+	"bytes"
+	"log/slog"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLevelsOverrides(t *testing.T) {
+	levels := NewLevels(slog.LevelInfo)
+	require.NoError(t, levels.SetOverrides(map[string]slog.Level{
+		"deeplink":         slog.LevelDebug,
+		"deeplink.resolve": slog.LevelError,
+	}))
+
+	tests := []struct {
+		name  string
+		level slog.Level
+		want  bool
+	}{
+		{name: "", level: slog.LevelDebug, want: false},
+		{name: "", level: slog.LevelInfo, want: true},
+		{name: "shortlink", level: slog.LevelDebug, want: false},
+		{name: "deeplink", level: slog.LevelDebug, want: true},
+		{name: "deeplink.qr", level: slog.LevelDebug, want: true},
+		{name: "deeplink.resolve", level: slog.LevelWarn, want: false},
+		{name: "deeplink.resolve.client", level: slog.LevelError, want: true},
+		{name: "deeplinks", level: slog.LevelDebug, want: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name+"/"+tt.level.String(), func(t *testing.T) {
+			assert.Equal(t, tt.want, levels.Enabled(tt.name, tt.level))
+		})
+	}
+
+	assert.Equal(t, slog.LevelDebug, levels.floor.Level())
+	require.NoError(t, levels.SetOverrides(nil))
+	assert.Equal(t, slog.LevelInfo, levels.floor.Level())
+	assert.Empty(t, levels.Overrides())
+
+	assert.Error(t, levels.SetOverrides(map[string]slog.Level{" ": slog.LevelDebug}))
+}
+
+func TestLevelsInLogger(t *testing.T) {
+	buf := &bytes.Buffer{}
+	levels := NewLevels(slog.LevelInfo)
+	logger, err := New(Config{}, WithWriter(buf), WithLevel(slog.LevelError), WithLevels(levels))
+	require.NoError(t, err)
+	service := Named(logger, "deeplink_service")
+
+	logger.Debug("base debug")
+	service.Debug("service debug")
+	assert.Empty(t, buf.String())
+
+	require.NoError(t, levels.SetOverrides(map[string]slog.Level{"deeplink_service": slog.LevelDebug}))
+	logger.Debug("base debug")
+	service.Debug("service debug")
+	assert.NotContains(t, buf.String(), "base debug")
+	assert.Contains(t, buf.String(), `"logger":"deeplink_service"`)
+	assert.Contains(t, buf.String(), "service debug")
+
+	buf.Reset()
+	levels.SetLevel(slog.LevelDebug)
+	logger.Debug("base debug")
+	assert.Contains(t, buf.String(), "base debug")
+}
+
+func TestLevelsConcurrent(t *testing.T) {
+	levels := NewLevels(slog.LevelInfo)
+	logger, err := New(Config{}, WithWriter(&bytes.Buffer{}), WithLevels(levels))
+	require.NoError(t, err)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 100; j++ {
+				_ = levels.Set(slog.LevelDebug, map[string]slog.Level{"a": slog.LevelWarn})
+				levels.SetLevel(slog.LevelInfo)
+			}
+		}()
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 100; j++ {
+				Named(logger, "a").Info("msg")
+			}
+		}()
+	}
+	wg.Wait()
+}
+
+func TestParseLevel(t *testing.T) {
+	for input, want := range map[string]slog.Level{
+		"debug":  slog.LevelDebug,
+		"INFO":   slog.LevelInfo,
+		" warn ": slog.LevelWarn,
+		"error":  slog.LevelError,
+		"info+2": slog.LevelInfo + 2,
+	} {
+		level, err := ParseLevel(input)
+		require.NoError(t, err, input)
+		assert.Equal(t, want, level, input)
+	}
+
+	_, err := ParseLevel("verbose")
+	assert.Error(t, err)
+
+	_, err = ParseLevelOverrides(map[string]string{"deeplink": "loud"})
+	assert.True(t, err != nil && strings.Contains(err.Error(), "deeplink"))
+}
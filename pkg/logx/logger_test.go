@@ -4,6 +4,9 @@ import (
 	"bytes"
 	"encoding/json"
 	"log/slog"
+	"maps"
+	"os"
+	"slices"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -46,8 +49,10 @@ func TestNew(t *testing.T) {
 			},
 			check: func(t *testing.T, logger *slog.Logger) {
 				assert.NotNil(t, logger)
-				// Verify custom key is in redactKeys
-				_, exists := sensitivekeys["custom_secret"]
+				// Verify custom key is in the sensitive keys of this logger
+				handler, ok := logger.Handler().(*censoringHandler)
+				require.True(t, ok)
+				_, exists := handler.sensitiveKeys["custom_secret"]
 				assert.True(t, exists)
 			},
 		},
@@ -155,7 +160,7 @@ func TestRedactingHandler(t *testing.T) {
 			opts := &slog.HandlerOptions{Level: slog.LevelDebug}
 			handler := slog.NewJSONHandler(buf, opts)
 
-			sensitivekeys = make(map[string]struct{})
+			sensitivekeys := make(map[string]struct{})
 			// Set sensitive keys from blacklist by default
 			for key := range blackList {
 				sensitivekeys[key] = struct{}{}
@@ -228,3 +233,106 @@ func TestOptions(t *testing.T) {
 		})
 	}
 }
+
+func TestSensitiveKeysPerLogger(t *testing.T) {
+	cardBuf, tokenBuf := &bytes.Buffer{}, &bytes.Buffer{}
+	cardLogger, err := New(Config{}, WithWriter(cardBuf), WithSensitiveKeys([]string{"card_ref"}))
+	require.NoError(t, err)
+	tokenLogger, err := New(Config{}, WithWriter(tokenBuf), WithSensitiveKeys([]string{"device_id"}))
+	require.NoError(t, err)
+
+	cardLogger.Info("msg", slog.String("card_ref", "c-1"), slog.String("device_id", "d-1"))
+	tokenLogger.Info("msg", slog.String("card_ref", "c-1"), slog.String("device_id", "d-1"))
+
+	var card, token map[string]any
+	require.NoError(t, json.Unmarshal(cardBuf.Bytes(), &card))
+	require.NoError(t, json.Unmarshal(tokenBuf.Bytes(), &token))
+	assert.Equal(t, DefaultRedactMessage, card["card_ref"])
+	assert.Equal(t, "d-1", card["device_id"])
+	assert.Equal(t, "c-1", token["card_ref"])
+	assert.Equal(t, DefaultRedactMessage, token["device_id"])
+}
+
+func TestAllowedKeys(t *testing.T) {
+	buf := &bytes.Buffer{}
+	logger, err := New(Config{}, WithWriter(buf),
+		WithAllowedKeys([]string{"state", "Country", "password"}),
+		WithSensitiveKeys([]string{"password"}),
+	)
+	require.NoError(t, err)
+
+	logger.Info("msg",
+		slog.String("state", "Bangkok"),
+		slog.String("country", "TH"),
+		slog.String("city", "Bangkok"),
+		slog.String("password", "secret"),
+	)
+
+	var output map[string]any
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &output))
+	assert.Equal(t, "Bangkok", output["state"])
+	assert.Equal(t, "TH", output["country"])
+	assert.Equal(t, DefaultRedactMessage, output["city"])
+	assert.Equal(t, DefaultRedactMessage, output["password"], "configured keys win over the allowlist")
+}
+
+func TestDefaultRedactMessage(t *testing.T) {
+	buf := &bytes.Buffer{}
+	logger, err := New(Config{}, WithWriter(buf),
+		WithDefaultRedactMessage("[REDACTED]"),
+		WithSensitiveKeys([]string{"email:email_domain"}),
+	)
+	require.NoError(t, err)
+
+	logger.Info("card 4111111111111111 declined",
+		slog.String("password", "secret"),
+		slog.String("email", "john@example.com"),
+		slog.String("body", `{"token":"t-1"}`),
+	)
+
+	var output map[string]any
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &output))
+	assert.Equal(t, "card [REDACTED] declined", output["msg"])
+	assert.Equal(t, "[REDACTED]", output["password"])
+	assert.Equal(t, "[REDACTED]@example.com", output["email"])
+	assert.Equal(t, `{"token":"[REDACTED]"}`, output["body"])
+
+	_, err = New(Config{}, WithDefaultRedactMessage(""))
+	assert.Error(t, err)
+}
+
+func TestRuntimeInfo(t *testing.T) {
+	buf := &bytes.Buffer{}
+	logger, err := New(Config{Source: "test-app"}, WithWriter(buf), WithRuntimeInfo(true))
+	require.NoError(t, err)
+	logger.Info("started")
+	logger.Info("again")
+
+	lines := bytes.Split(bytes.TrimSpace(buf.Bytes()), []byte("\n"))
+	require.Len(t, lines, 2)
+	info := Runtime()
+	for _, line := range lines {
+		var output struct {
+			ProgramInfo RuntimeInfo `json:"program_info"`
+		}
+		require.NoError(t, json.Unmarshal(line, &output))
+		assert.Equal(t, info, output.ProgramInfo, "runtime info must not be censored")
+	}
+	assert.Equal(t, os.Getpid(), info.PID)
+	assert.NotEmpty(t, info.InstanceID)
+	assert.NotEmpty(t, info.GoVersion)
+	assert.Equal(t, info.InstanceID, Runtime().InstanceID, "the instance ID is stable within a process")
+
+	// the build information is served publicly, it must not identify the host
+	build, err := json.Marshal(info.BuildInfo)
+	require.NoError(t, err)
+	var buildFields map[string]any
+	require.NoError(t, json.Unmarshal(build, &buildFields))
+	assert.ElementsMatch(t, []string{"go_version", "git_commit", "build_date"}, slices.Collect(maps.Keys(buildFields)))
+
+	buf.Reset()
+	logger, err = New(Config{}, WithWriter(buf))
+	require.NoError(t, err)
+	logger.Info("without runtime info")
+	assert.NotContains(t, buf.String(), "program_info")
+}
@@ -6,9 +6,7 @@ import (
 	"io"
 	"log/slog"
 	"os"
-	"path/filepath"
-
-	"github.com/mdobak/go-xerrors"
+	"strings"
 )
 
 // Config contains essential logger configuration like environment and application source.
@@ -22,15 +20,18 @@ type optionsConfig struct {
 	Level                slog.Level
 	AddSource            bool     `json:"add_source"`
 	SensitiveKeys        []string `json:"sensitive_keys"`
+	AllowedKeys          []string `json:"allowed_keys"`
 	WithDebug            bool     `json:"with_debug"`
 	DefaultRedactMessage string   `json:"default_redact_message"`
 	Writer               io.Writer
-}
-
-type stackFrame struct {
-	Func   string `json:"func"`
-	Source string `json:"source"`
-	Line   int    `json:"line"`
+	Sinks                []Sink
+	Levels               *Levels
+	Async                *Async
+	Sampler              *Sampler
+	OTLP                 *OTLPExporter
+	RuntimeInfo          bool
+	Detectors            map[Detector]bool
+	HashSalt             string
 }
 
 const (
@@ -40,14 +41,13 @@ const (
 	DefaultRedactMessage = "*"
 )
 
-var sensitivekeys = map[string]struct{}{}
-
 // New creates a slog.Logger with redaction and structured logging capabilities.
 //
 // The logger outputs JSON-formatted logs with:
 //   - Automatic redaction of sensitive fields
+//   - Redaction of PII found inside values, such as card numbers in a message
 //   - Standard fields for environment and application name
-//   - Runtime information including PID and Go version
+//   - Optionally, build and process information (see WithRuntimeInfo)
 //
 // Example:
 //
@@ -56,8 +56,6 @@ var sensitivekeys = map[string]struct{}{}
 //	    Source:     "myapp",
 //	})
 func New(cfg Config, options ...Option) (*slog.Logger, error) {
-
-	// buildInfo := newBuildInfo()
 	logOpts := optionsConfig{
 		Level:                slog.LevelDebug,
 		AddSource:            false,
@@ -65,6 +63,7 @@ func New(cfg Config, options ...Option) (*slog.Logger, error) {
 		WithDebug:            false,
 		DefaultRedactMessage: DefaultRedactMessage,
 		Writer:               nil,
+		Detectors:            defaultDetectors(),
 	}
 
 	// Validate blacklist during package initialization
@@ -78,96 +77,115 @@ func New(cfg Config, options ...Option) (*slog.Logger, error) {
 		}
 	}
 
-	// Set sensitive keys
-	sensitivekeys = make(map[string]struct{})
-	for _, key := range logOpts.SensitiveKeys {
-		sensitivekeys[snake.SnakeCase(key)] = struct{}{}
+	sensitiveKeys, keyMasks, err := newKeySet(logOpts.SensitiveKeys, logOpts.AllowedKeys)
+	if err != nil {
+		return nil, err
 	}
-	// Set sensitive keys from blacklist by default
-	for key := range blackList {
-		sensitivekeys[snake.SnakeCase(key)] = struct{}{}
+	// An unsalted hash of a low-entropy value, such as an ID, is reversed by
+	// hashing every candidate
+	if logOpts.HashSalt == "" {
+		for key, m := range keyMasks {
+			if m.kind == maskKindHash {
+				return nil, fmt.Errorf("sensitive key %q uses the %q masking strategy without a salt, see WithHashSalt", key, MaskHash)
+			}
+		}
 	}
 
-	opts := &slog.HandlerOptions{
-		Level:       logOpts.Level,
-		AddSource:   logOpts.AddSource,
-		ReplaceAttr: replaceErrorAttribute,
+	levels := logOpts.Levels
+	if levels == nil {
+		levels = NewLevels(logOpts.Level)
 	}
-	out := logOpts.Writer
-	if out == nil {
-		out = os.Stdout
+
+	// Without sinks, the logger writes JSON to the configured writer
+	sinks := logOpts.Sinks
+	if len(sinks) == 0 {
+		out := logOpts.Writer
+		if out == nil {
+			out = os.Stdout
+		}
+		sinks = []Sink{{Writer: out, Format: FormatJSON}}
+	}
+	sinkHandlers := make([]slog.Handler, len(sinks))
+	for i, sink := range sinks {
+		sinkHandlers[i] = newSinkHandler(sink, levels.floor, logOpts.AddSource)
+	}
+	if logOpts.OTLP != nil {
+		logOpts.OTLP.setResource(cfg)
+		otlpLevel := logOpts.OTLP.cfg.Level
+		if otlpLevel == nil {
+			otlpLevel = levels.floor
+		}
+		sinkHandlers = append(sinkHandlers, &otlpHandler{exporter: logOpts.OTLP, level: otlpLevel})
+	}
+
+	// Censoring runs once, before records fan out to the sinks
+	handler := newMultiHandler(sinkHandlers)
+	if logOpts.Async != nil {
+		handler = &asyncHandler{handler: handler, async: logOpts.Async}
 	}
-	handler := slog.NewJSONHandler(out, opts)
 
 	// Wrap with censoring handler
 	censoringHandler := &censoringHandler{
 		handler:       handler,
 		withDebug:     logOpts.WithDebug,
-		sensitiveKeys: sensitivekeys,
+		sensitiveKeys: sensitiveKeys,
+		detectors:     newDetectors(logOpts.Detectors),
+		keyMasks:      keyMasks,
+		redaction:     redaction{message: logOpts.DefaultRedactMessage, salt: logOpts.HashSalt},
+		keys:          &keyCache{},
+		levels:        levels,
+	}
+
+	// Sample before censoring so dropped records cost as little as possible
+	var root slog.Handler = censoringHandler
+	if logOpts.Sampler != nil {
+		root = &samplingHandler{handler: root, sampler: logOpts.Sampler}
 	}
 
 	// Create the logger with default fields
-	logger := slog.New(censoringHandler).With(
+	defaultAttrs := []any{
 		slog.String("source", cfg.Source),
 		slog.String("env", cfg.Environment),
-		// slog.Group("program_info",
-		// 	slog.Int("pid", os.Getpid()),
-		// 	slog.String("go_version", buildInfo.GoVersion),
-		// ),
-	)
-
-	return logger, nil
-}
-func replaceErrorAttribute(groups []string, attr slog.Attr) slog.Attr {
-	switch attr.Value.Kind() {
-	case slog.KindAny:
-		switch v := attr.Value.Any().(type) {
-		case error:
-			attr.Value = formatErrorWithTrace(v)
-		}
 	}
+	if logOpts.RuntimeInfo {
+		defaultAttrs = append(defaultAttrs, Runtime().attr())
+	}
+	logger := slog.New(root).With(defaultAttrs...)
 
-	return attr
+	return logger, nil
 }
 
-// extractStackFrames extracts and formats stack frames from an error
-func extractStackFrames(err error) []stackFrame {
-	trace := xerrors.StackTrace(err)
-
-	if len(trace) == 0 {
-		return nil
+// newKeySet builds the sensitive keys of one logger from the blacklist and the
+// configured keys. A key may carry a ":strategy" suffix selecting a partial
+// mask. Allowed keys are removed from the blacklist but never from the
+// configured keys, which always win.
+func newKeySet(keys, allowed []string) (map[string]struct{}, map[string]mask, error) {
+	allowedKeys := make(map[string]struct{}, len(allowed))
+	for _, key := range allowed {
+		allowedKeys[snake.SnakeCase(key)] = struct{}{}
 	}
 
-	frames := trace.Frames()
-	formattedFrames := make([]stackFrame, len(frames))
-
-	for i, frame := range frames {
-		formattedFrames[i] = stackFrame{
-			Source: filepath.Join(
-				filepath.Base(filepath.Dir(frame.File)),
-				filepath.Base(frame.File),
-			),
-			Func: filepath.Base(frame.Function),
-			Line: frame.Line,
+	sensitiveKeys := make(map[string]struct{}, len(blackList)+len(keys))
+	// Set sensitive keys from blacklist by default
+	for key := range blackList {
+		key = snake.SnakeCase(key)
+		if _, ok := allowedKeys[key]; !ok {
+			sensitiveKeys[key] = struct{}{}
 		}
 	}
 
-	return formattedFrames
-}
-
-// formatErrorWithTrace returns a slog.Value containing the error message and stack trace.
-// If the error doesn't implement the StackTrace interface, only the message is included.
-func formatErrorWithTrace(err error) slog.Value {
-	var attributes []slog.Attr
-
-	attributes = append(attributes, slog.String("msg", err.Error()))
-
-	frames := extractStackFrames(err)
-	if frames != nil {
-		attributes = append(attributes,
-			slog.Any("trace", frames),
-		)
+	keyMasks := make(map[string]mask)
+	for _, key := range keys {
+		name, strategy, hasStrategy := strings.Cut(key, ":")
+		name = snake.SnakeCase(name)
+		sensitiveKeys[name] = struct{}{}
+		if hasStrategy {
+			m, err := parseMask(strategy)
+			if err != nil {
+				return nil, nil, fmt.Errorf("invalid sensitive key %q: %w", key, err)
+			}
+			keyMasks[name] = m
+		}
 	}
-
-	return slog.GroupValue(attributes...)
+	return sensitiveKeys, keyMasks, nil
 }
@@ -0,0 +1,145 @@
+package logx
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// Masking strategies accepted in `sensitive:"..."` tags and as the ":strategy"
+// suffix of keys passed to WithSensitiveKeys.
+//
+//	full          replace the whole value (default)
+//	lastN         keep the last N characters, e.g. last4
+//	firstN        keep the first N characters, e.g. first6
+//	email_domain  keep only the domain of an email address
+//	hash          replace with a salted HMAC-SHA256 digest, see WithHashSalt;
+//	              without a salt, values are fully redacted instead
+const (
+	MaskFull        = "full"
+	MaskEmailDomain = "email_domain"
+	MaskHash        = "hash"
+
+	maskKeepLastPrefix  = "last"
+	maskKeepFirstPrefix = "first"
+
+	// maskRune replaces hidden characters of partially masked values
+	maskRune = '*'
+	// hashPrefix marks hashed values and hashLength is the number of hex digits kept
+	hashPrefix = "sha256:"
+	hashLength = 16
+)
+
+type maskKind int
+
+const (
+	maskKindFull maskKind = iota
+	maskKindKeepLast
+	maskKindKeepFirst
+	maskKindEmailDomain
+	maskKindHash
+)
+
+// mask is a parsed masking strategy. The zero value is full redaction.
+type mask struct {
+	kind maskKind
+	n    int
+}
+
+// parseMask parses a strategy name. "true" is accepted as full redaction for
+// compatibility with `sensitive:"true"` tags.
+func parseMask(strategy string) (mask, error) {
+	switch strategy {
+	case "", "true", MaskFull:
+		return mask{kind: maskKindFull}, nil
+	case MaskEmailDomain:
+		return mask{kind: maskKindEmailDomain}, nil
+	case MaskHash:
+		return mask{kind: maskKindHash}, nil
+	}
+
+	kind, rest := maskKindFull, ""
+	if after, ok := strings.CutPrefix(strategy, maskKeepLastPrefix); ok {
+		kind, rest = maskKindKeepLast, after
+	} else if after, ok := strings.CutPrefix(strategy, maskKeepFirstPrefix); ok {
+		kind, rest = maskKindKeepFirst, after
+	} else {
+		return mask{}, fmt.Errorf("unknown masking strategy %q", strategy)
+	}
+
+	n, err := strconv.Atoi(strings.TrimPrefix(rest, "_"))
+	if err != nil || n <= 0 {
+		return mask{}, fmt.Errorf("invalid masking strategy %q", strategy)
+	}
+	return mask{kind: kind, n: n}, nil
+}
+
+// tagMask returns the mask selected by a sensitive struct tag. Unknown
+// strategies fall back to full redaction so a typo never leaks a value.
+func tagMask(tag string) (mask, bool) {
+	if tag == "" || tag == "false" {
+		return mask{}, false
+	}
+	m, err := parseMask(tag)
+	if err != nil {
+		return mask{kind: maskKindFull}, true
+	}
+	return m, true
+}
+
+// redaction holds the per-logger settings used when a value is replaced.
+type redaction struct {
+	// message replaces fully redacted values; empty means DefaultRedactMessage
+	message string
+	// salt keys the HMAC used by the hash masking strategy
+	salt string
+}
+
+// redactMessage returns the replacement for fully redacted values.
+func (r redaction) redactMessage() string {
+	if r.message == "" {
+		return DefaultRedactMessage
+	}
+	return r.message
+}
+
+// apply masks value. Values too short to be partially shown are fully redacted.
+func (m mask) apply(value string, r redaction) string {
+	switch m.kind {
+	case maskKindKeepLast, maskKindKeepFirst:
+		count := utf8.RuneCountInString(value)
+		if count <= m.n {
+			return r.redactMessage()
+		}
+		runes := []rune(value)
+		hidden := strings.Repeat(string(maskRune), count-m.n)
+		if m.kind == maskKindKeepLast {
+			return hidden + string(runes[count-m.n:])
+		}
+		return string(runes[:m.n]) + hidden
+
+	case maskKindEmailDomain:
+		at := strings.LastIndexByte(value, '@')
+		if at < 0 || at == len(value)-1 {
+			return r.redactMessage()
+		}
+		return r.redactMessage() + value[at:]
+
+	case maskKindHash:
+		// Struct tags are only seen when a value is logged, so New cannot
+		// reject them like keys: without a salt, redact the whole value
+		if r.salt == "" {
+			return r.redactMessage()
+		}
+		mac := hmac.New(sha256.New, []byte(r.salt))
+		mac.Write([]byte(value))
+		return hashPrefix + hex.EncodeToString(mac.Sum(nil))[:hashLength]
+
+	default:
+		return r.redactMessage()
+	}
+}
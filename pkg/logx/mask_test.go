@@ -0,0 +1,120 @@
+package logx
+
+import (
+	"bytes"
+	"encoding/json"
+	"log/slog"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMaskApply(t *testing.T) {
+	tests := []struct {
+		strategy string
+		input    string
+		want     string
+	}{
+		{strategy: "full", input: "4111111111111111", want: "*"},
+		{strategy: "true", input: "secret", want: "*"},
+		{strategy: "last4", input: "4111111111111111", want: "************1111"},
+		{strategy: "last_4", input: "4111111111111111", want: "************1111"},
+		{strategy: "last4", input: "1234", want: "*"},
+		{strategy: "first6", input: "4111111111111111", want: "411111**********"},
+		{strategy: "first2", input: "สมชาย", want: "สม***"},
+		{strategy: "email_domain", input: "john.doe@example.com", want: "*@example.com"},
+		{strategy: "email_domain", input: "not-an-email", want: "*"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.strategy+"/"+tt.input, func(t *testing.T) {
+			m, err := parseMask(tt.strategy)
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, m.apply(tt.input, redaction{}))
+		})
+	}
+}
+
+func TestMaskHash(t *testing.T) {
+	m, err := parseMask(MaskHash)
+	require.NoError(t, err)
+
+	first := m.apply("john@example.com", redaction{salt: "salt"})
+	assert.True(t, strings.HasPrefix(first, hashPrefix))
+	assert.Len(t, first, len(hashPrefix)+hashLength)
+	assert.Equal(t, first, m.apply("john@example.com", redaction{salt: "salt"}), "hash must be deterministic")
+	assert.NotEqual(t, first, m.apply("john@example.com", redaction{salt: "other"}), "hash must depend on the salt")
+	assert.NotContains(t, first, "john")
+	assert.Equal(t, "*", m.apply("john@example.com", redaction{}), "values are not hashed without a salt")
+}
+
+type hashedCustomer struct {
+	ID string `json:"id" sensitive:"hash"`
+}
+
+func TestMaskHashWithoutSalt(t *testing.T) {
+	_, err := New(Config{}, WithSensitiveKeys([]string{"customer_ref:hash"}))
+	assert.ErrorContains(t, err, "without a salt")
+
+	_, err = New(Config{}, WithSensitiveKeys([]string{"customer_ref:hash"}), WithHashSalt("salt"))
+	assert.NoError(t, err)
+
+	buf := &bytes.Buffer{}
+	logger, err := New(Config{}, WithWriter(buf))
+	require.NoError(t, err)
+	logger.Info("customer", slog.Any("customer", hashedCustomer{ID: "C-001"}))
+
+	var output map[string]any
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &output))
+	assert.Equal(t, map[string]any{"id": "*"}, output["customer"])
+}
+
+func TestParseMaskInvalid(t *testing.T) {
+	for _, strategy := range []string{"last", "last0", "firstx", "middle4"} {
+		_, err := parseMask(strategy)
+		assert.Error(t, err, strategy)
+	}
+}
+
+type maskedCard struct {
+	Holder string `json:"holder"`
+	Number string `json:"number" sensitive:"last4"`
+	Email  string `json:"contact" sensitive:"email_domain"`
+	Ref    string `json:"ref" sensitive:"typo"`
+}
+
+func TestMaskingInLogger(t *testing.T) {
+	buf := &bytes.Buffer{}
+	logger, err := New(Config{},
+		WithWriter(buf),
+		WithSensitiveKeys([]string{"card_number:last4", "customer_ref:hash"}),
+		WithHashSalt("salt"),
+	)
+	require.NoError(t, err)
+
+	logger.Info("payment",
+		slog.String("card_number", "4111111111111111"),
+		slog.String("customer_ref", "C-001"),
+		slog.Any("card", maskedCard{Holder: "John", Number: "5555555555554444", Email: "john@example.com", Ref: "R-1"}),
+		slog.String("body", `{"card_number":"4111111111111111"}`),
+	)
+
+	var output map[string]any
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &output))
+	assert.Equal(t, "************1111", output["card_number"])
+	assert.True(t, strings.HasPrefix(output["customer_ref"].(string), hashPrefix))
+	assert.JSONEq(t, `{"card_number":"************1111"}`, output["body"].(string))
+
+	card := output["card"].(map[string]any)
+	assert.Equal(t, "John", card["holder"])
+	assert.Equal(t, "************4444", card["number"])
+	assert.Equal(t, "*@example.com", card["contact"])
+	assert.Equal(t, "*", card["ref"], "unknown strategies fall back to full redaction")
+}
+
+func TestWithSensitiveKeysInvalidStrategy(t *testing.T) {
+	_, err := New(Config{}, WithSensitiveKeys([]string{"card_number:middle4"}))
+	assert.Error(t, err)
+}
@@ -1,6 +1,7 @@
 package logx
 
 import (
+	"fmt"
 	"io"
 	"log/slog"
 )
@@ -17,6 +18,59 @@ func WithLevel(level slog.Level) Option {
 	}
 }
 
+// WithLevels sets runtime adjustable levels for the logger, taking precedence
+// over WithLevel. Keep a reference to levels to change them while the
+// application runs, e.g. from an admin endpoint.
+func WithLevels(levels *Levels) Option {
+	return func(cfg *optionsConfig) error {
+		cfg.Levels = levels
+		return nil
+	}
+}
+
+// WithAsync writes records through async instead of on the calling goroutine.
+// Records are censored before they are queued. Call async.Close on shutdown to
+// flush the queue.
+func WithAsync(async *Async) Option {
+	return func(cfg *optionsConfig) error {
+		cfg.Async = async
+		return nil
+	}
+}
+
+// WithSampler drops repeated records with the same level and message
+// according to the rules of sampler. Call sampler.Close on shutdown to log the
+// pending summaries.
+func WithSampler(sampler *Sampler) Option {
+	return func(cfg *optionsConfig) error {
+		cfg.Sampler = sampler
+		return nil
+	}
+}
+
+// WithOTLP also exports records to an OpenTelemetry collector through
+// exporter, after censoring. The source and environment of the logger and the
+// build information become resource attributes. Call exporter.Shutdown on
+// shutdown to export the remaining records.
+func WithOTLP(exporter *OTLPExporter) Option {
+	return func(cfg *optionsConfig) error {
+		if exporter == nil {
+			return fmt.Errorf("OTLP exporter must not be nil")
+		}
+		cfg.OTLP = exporter
+		return nil
+	}
+}
+
+// WithRuntimeInfo attaches the build and process information returned by
+// Runtime to every record, in the program_info group.
+func WithRuntimeInfo(enabled bool) Option {
+	return func(cfg *optionsConfig) error {
+		cfg.RuntimeInfo = enabled
+		return nil
+	}
+}
+
 // WithAddSource enables or disables the inclusion of source information in logs.
 // When enabled, log entries may include details such as filename and line number.
 func WithAddSource(addSource bool) Option {
@@ -28,6 +82,8 @@ func WithAddSource(addSource bool) Option {
 
 // WithSensitiveKeys specifies keys that should be treated as sensitive information.
 // These keys may be redacted or masked in logs to prevent leakage of sensitive data.
+// A key may select a masking strategy with a ":strategy" suffix, e.g. "card_number:last4"
+// or "email:email_domain". Keys without a suffix are fully redacted.
 func WithSensitiveKeys(keys []string) Option {
 	return func(cfg *optionsConfig) error {
 		cfg.SensitiveKeys = keys
@@ -35,6 +91,16 @@ func WithSensitiveKeys(keys []string) Option {
 	}
 }
 
+// WithAllowedKeys specifies keys that are logged as they are even though the
+// built-in blacklist would redact them, e.g. "state" or "country".
+// Keys passed to WithSensitiveKeys are always redacted.
+func WithAllowedKeys(keys []string) Option {
+	return func(cfg *optionsConfig) error {
+		cfg.AllowedKeys = keys
+		return nil
+	}
+}
+
 // WithDebug enables or disables debug mode for the logger.
 // When enabled, additional debugging information may be included in log output.
 func WithDebugMode(withDebug bool) Option {
@@ -45,13 +111,66 @@ func WithDebugMode(withDebug bool) Option {
 }
 
 // WithDefaultRedactMessage sets the default message to use when redacting sensitive data.
+// It replaces fully redacted values and values found by detectors; partial masks
+// keep hiding characters with '*'.
 func WithDefaultRedactMessage(defaultRedactMessage string) Option {
 	return func(cfg *optionsConfig) error {
+		if defaultRedactMessage == "" {
+			return fmt.Errorf("default redact message must not be empty")
+		}
 		cfg.DefaultRedactMessage = defaultRedactMessage
 		return nil
 	}
 }
 
+// WithDetector enables or disables a value-level PII detector.
+// All built-in detectors are enabled by default; detectors do not run in debug mode.
+//
+// Example:
+//
+//	logx.WithDetector(logx.DetectorPhone, false)
+func WithDetector(detector Detector, enabled bool) Option {
+	return func(cfg *optionsConfig) error {
+		if err := validateDetector(detector); err != nil {
+			return err
+		}
+		if cfg.Detectors == nil {
+			cfg.Detectors = defaultDetectors()
+		}
+		cfg.Detectors[detector] = enabled
+		return nil
+	}
+}
+
+// WithHashSalt sets the secret used by the "hash" masking strategy.
+// Hashed values can be correlated across log lines but not reversed without the salt.
+// New fails when a key passed to WithSensitiveKeys uses the strategy without a
+// salt, and `sensitive:"hash"` fields are fully redacted instead.
+func WithHashSalt(salt string) Option {
+	return func(cfg *optionsConfig) error {
+		cfg.HashSalt = salt
+		return nil
+	}
+}
+
+// WithSink adds a destination with its own level and format. Every sink
+// receives the same censored records. When sinks are added, the writer set
+// with WithWriter is not used.
+//
+// Example:
+//
+//	logx.WithSink(logx.Sink{Writer: os.Stdout, Format: logx.FormatConsole, Level: slog.LevelDebug})
+//	logx.WithSink(logx.Sink{Writer: errorFile, Level: slog.LevelError})
+func WithSink(sink Sink) Option {
+	return func(cfg *optionsConfig) error {
+		if err := sink.validate(); err != nil {
+			return err
+		}
+		cfg.Sinks = append(cfg.Sinks, sink)
+		return nil
+	}
+}
+
 // WithWriter sets a custom io.Writer for logger output (e.g., file or MultiWriter).
 func WithWriter(w io.Writer) Option {
 	return func(cfg *optionsConfig) error {
@@ -0,0 +1,472 @@
+package logx
+
+import (
+	"bytes"
+	"context"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"go.opentelemetry.io/otel/trace"
+)
+
+const (
+	otlpLogsPath     = "/v1/logs"
+	otlpScopeName    = "deeplink-bff/pkg/logx"
+	defaultBatchSize = 512
+	defaultOTLPQueue = 2048
+)
+
+// OTLPConfig configures an OTLPExporter.
+type OTLPConfig struct {
+	// Endpoint is the OTLP/HTTP collector base URL, e.g. http://localhost:4318.
+	// /v1/logs is appended unless the endpoint already ends with it.
+	Endpoint string
+	// Headers are sent with every export request, e.g. an API key.
+	Headers map[string]string
+	// Level is the minimum level exported. Nil exports every record the
+	// logger level lets through.
+	Level slog.Leveler
+	// BatchSize is the maximum number of records per export request. Defaults to 512.
+	BatchSize int
+	// FlushInterval is how long records wait for a batch to fill. Defaults to 1s.
+	FlushInterval time.Duration
+	// QueueSize bounds the records waiting to be exported; records beyond it
+	// are dropped and counted. Defaults to 2048.
+	QueueSize int
+	// Client sends the export requests. Defaults to a client with a 10s timeout.
+	Client *http.Client
+	// Resource holds extra resource attributes, e.g. "k8s.pod.name".
+	Resource map[string]string
+}
+
+// OTLPExporter exports censored log records to an OpenTelemetry collector
+// using OTLP/HTTP with JSON encoding. Records carry the trace and span ID of
+// the span in their context. Pass it with WithOTLP and call Shutdown on exit
+// to export the remaining records.
+type OTLPExporter struct {
+	cfg      OTLPConfig
+	endpoint string
+
+	mu       sync.RWMutex
+	resource []otlpKeyValue
+
+	queue    chan otlpLogRecord
+	flush    chan chan struct{}
+	stop     chan struct{}
+	done     chan struct{}
+	stopOnce sync.Once
+
+	dropped atomic.Uint64
+	failed  atomic.Uint64
+}
+
+// NewOTLPExporter creates an OTLPExporter and starts its export goroutine.
+func NewOTLPExporter(cfg OTLPConfig) (*OTLPExporter, error) {
+	endpoint := strings.TrimRight(cfg.Endpoint, "/")
+	if !strings.HasPrefix(endpoint, "http://") && !strings.HasPrefix(endpoint, "https://") {
+		return nil, fmt.Errorf("invalid OTLP endpoint %q", cfg.Endpoint)
+	}
+	if !strings.HasSuffix(endpoint, otlpLogsPath) {
+		endpoint += otlpLogsPath
+	}
+	if cfg.BatchSize < 0 || cfg.QueueSize < 0 || cfg.FlushInterval < 0 {
+		return nil, fmt.Errorf("OTLP exporter limits must not be negative")
+	}
+	if cfg.BatchSize == 0 {
+		cfg.BatchSize = defaultBatchSize
+	}
+	if cfg.QueueSize == 0 {
+		cfg.QueueSize = defaultOTLPQueue
+	}
+	if cfg.FlushInterval == 0 {
+		cfg.FlushInterval = time.Second
+	}
+	if cfg.Client == nil {
+		cfg.Client = &http.Client{Timeout: 10 * time.Second}
+	}
+
+	e := &OTLPExporter{
+		cfg:      cfg,
+		endpoint: endpoint,
+		queue:    make(chan otlpLogRecord, cfg.QueueSize),
+		flush:    make(chan chan struct{}),
+		stop:     make(chan struct{}),
+		done:     make(chan struct{}),
+	}
+	e.setResource(Config{})
+	go e.run()
+	return e, nil
+}
+
+// setResource sets the resource attributes from the logger configuration and
+// the runtime information of the process.
+func (e *OTLPExporter) setResource(cfg Config) {
+	info := Runtime()
+	attrs := map[string]string{
+		"service.name":            cfg.Source,
+		"deployment.environment":  cfg.Environment,
+		"service.version":         info.GitCommit,
+		"service.instance.id":     info.InstanceID,
+		"process.pid":             strconv.Itoa(info.PID),
+		"process.runtime.name":    "go",
+		"process.runtime.version": info.GoVersion,
+		"build.date":              info.BuildDate,
+		"host.name":               info.Hostname,
+	}
+	for key, value := range e.cfg.Resource {
+		attrs[key] = value
+	}
+
+	resource := make([]otlpKeyValue, 0, len(attrs))
+	for key, value := range attrs {
+		if value == "" {
+			continue
+		}
+		resource = append(resource, otlpKeyValue{Key: key, Value: otlpString(value)})
+	}
+
+	e.mu.Lock()
+	e.resource = resource
+	e.mu.Unlock()
+}
+
+// Flush exports the queued records and waits until they are sent or ctx is done.
+func (e *OTLPExporter) Flush(ctx context.Context) error {
+	flushed := make(chan struct{})
+	select {
+	case e.flush <- flushed:
+	case <-e.done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+	select {
+	case <-flushed:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// Shutdown exports the remaining records and stops the exporter. Records
+// logged afterwards are dropped.
+func (e *OTLPExporter) Shutdown(ctx context.Context) error {
+	e.stopOnce.Do(func() { close(e.stop) })
+	select {
+	case <-e.done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// Dropped returns the number of records dropped because the queue was full or
+// the exporter was shut down.
+func (e *OTLPExporter) Dropped() uint64 {
+	return e.dropped.Load()
+}
+
+// Failed returns the number of records in export requests that failed.
+func (e *OTLPExporter) Failed() uint64 {
+	return e.failed.Load()
+}
+
+func (e *OTLPExporter) enqueue(record otlpLogRecord) {
+	select {
+	case <-e.stop:
+		e.dropped.Add(1)
+		return
+	default:
+	}
+
+	select {
+	case e.queue <- record:
+	default:
+		e.dropped.Add(1)
+	}
+}
+
+func (e *OTLPExporter) run() {
+	defer close(e.done)
+	ticker := time.NewTicker(e.cfg.FlushInterval)
+	defer ticker.Stop()
+
+	batch := make([]otlpLogRecord, 0, e.cfg.BatchSize)
+	export := func() {
+		if len(batch) > 0 {
+			e.export(batch)
+			batch = batch[:0]
+		}
+	}
+	drain := func() {
+		for {
+			select {
+			case record := <-e.queue:
+				batch = append(batch, record)
+				if len(batch) >= e.cfg.BatchSize {
+					export()
+				}
+			default:
+				export()
+				return
+			}
+		}
+	}
+
+	for {
+		select {
+		case record := <-e.queue:
+			batch = append(batch, record)
+			if len(batch) >= e.cfg.BatchSize {
+				export()
+			}
+		case <-ticker.C:
+			export()
+		case flushed := <-e.flush:
+			drain()
+			close(flushed)
+		case <-e.stop:
+			drain()
+			return
+		}
+	}
+}
+
+// export sends one batch. Errors are reported on stderr, since the logger
+// cannot log its own export failures.
+func (e *OTLPExporter) export(batch []otlpLogRecord) {
+	e.mu.RLock()
+	resource := e.resource
+	e.mu.RUnlock()
+
+	body, err := json.Marshal(otlpRequest{ResourceLogs: []otlpResourceLogs{{
+		Resource: otlpResource{Attributes: resource},
+		ScopeLogs: []otlpScopeLogs{{
+			Scope:      otlpScope{Name: otlpScopeName},
+			LogRecords: batch,
+		}},
+	}}})
+	if err != nil {
+		e.fail(len(batch), err)
+		return
+	}
+
+	req, err := http.NewRequest(http.MethodPost, e.endpoint, bytes.NewReader(body))
+	if err != nil {
+		e.fail(len(batch), err)
+		return
+	}
+	req.Header.Set("Content-Type", "application/json")
+	for key, value := range e.cfg.Headers {
+		req.Header.Set(key, value)
+	}
+
+	resp, err := e.cfg.Client.Do(req)
+	if err != nil {
+		e.fail(len(batch), err)
+		return
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, resp.Body)
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		e.fail(len(batch), fmt.Errorf("collector responded %s", resp.Status))
+	}
+}
+
+func (e *OTLPExporter) fail(n int, err error) {
+	e.failed.Add(uint64(n))
+	fmt.Fprintf(os.Stderr, "logx: failed to export %d log records: %v\n", n, err)
+}
+
+// otlpHandler converts records to OTLP log records and queues them on the exporter.
+type otlpHandler struct {
+	exporter *OTLPExporter
+	level    slog.Leveler
+	// goas are the groups and attributes added with WithGroup and WithAttrs, in order
+	goas []groupOrAttrs
+}
+
+func (h *otlpHandler) Enabled(_ context.Context, level slog.Level) bool {
+	return level >= h.level.Level()
+}
+
+func (h *otlpHandler) Handle(ctx context.Context, r slog.Record) error {
+	attrs := nestAttrs(h.goas, r)
+
+	record := otlpLogRecord{
+		TimeUnixNano:         strconv.FormatInt(r.Time.UnixNano(), 10),
+		ObservedTimeUnixNano: strconv.FormatInt(time.Now().UnixNano(), 10),
+		SeverityNumber:       otlpSeverity(r.Level),
+		SeverityText:         r.Level.String(),
+		Body:                 otlpString(r.Message),
+		Attributes:           otlpAttributes(attrs),
+	}
+	if spanCtx := trace.SpanContextFromContext(ctx); spanCtx.IsValid() {
+		traceID, spanID := spanCtx.TraceID(), spanCtx.SpanID()
+		record.TraceID = hex.EncodeToString(traceID[:])
+		record.SpanID = hex.EncodeToString(spanID[:])
+		record.Flags = uint32(spanCtx.TraceFlags())
+	}
+
+	h.exporter.enqueue(record)
+	return nil
+}
+
+func (h *otlpHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	if len(attrs) == 0 {
+		return h
+	}
+	h2 := *h
+	h2.goas = withGroupOrAttrs(h.goas, groupOrAttrs{attrs: attrs})
+	return &h2
+}
+
+func (h *otlpHandler) WithGroup(name string) slog.Handler {
+	if name == "" {
+		return h
+	}
+	h2 := *h
+	h2.goas = withGroupOrAttrs(h.goas, groupOrAttrs{group: name})
+	return &h2
+}
+
+// otlpSeverity maps a slog level to an OTLP severity number, as the
+// OpenTelemetry slog bridge does: DEBUG is 5, INFO 9, WARN 13 and ERROR 17.
+func otlpSeverity(level slog.Level) int {
+	severity := int(level) + 9
+	if severity < 1 {
+		return 1
+	}
+	if severity > 24 {
+		return 24
+	}
+	return severity
+}
+
+func otlpAttributes(attrs []slog.Attr) []otlpKeyValue {
+	if len(attrs) == 0 {
+		return nil
+	}
+	kvs := make([]otlpKeyValue, 0, len(attrs))
+	for _, attr := range attrs {
+		attr = replaceErrorAttribute(nil, attr)
+		attr.Value = attr.Value.Resolve()
+		if attr.Key == "" && attr.Value.Kind() == slog.KindGroup {
+			// an inline group adds its attributes to the parent
+			kvs = append(kvs, otlpAttributes(attr.Value.Group())...)
+			continue
+		}
+		if attr.Equal(slog.Attr{}) {
+			continue
+		}
+		kvs = append(kvs, otlpKeyValue{Key: attr.Key, Value: otlpValue(attr.Value)})
+	}
+	return kvs
+}
+
+func otlpValue(v slog.Value) otlpAnyValue {
+	switch v.Kind() {
+	case slog.KindString:
+		return otlpString(v.String())
+	case slog.KindInt64:
+		s := strconv.FormatInt(v.Int64(), 10)
+		return otlpAnyValue{IntValue: &s}
+	case slog.KindUint64:
+		s := strconv.FormatUint(v.Uint64(), 10)
+		return otlpAnyValue{IntValue: &s}
+	case slog.KindFloat64:
+		f := v.Float64()
+		return otlpAnyValue{DoubleValue: &f}
+	case slog.KindBool:
+		b := v.Bool()
+		return otlpAnyValue{BoolValue: &b}
+	case slog.KindDuration:
+		s := strconv.FormatInt(int64(v.Duration()), 10)
+		return otlpAnyValue{IntValue: &s}
+	case slog.KindTime:
+		return otlpString(v.Time().Format(time.RFC3339Nano))
+	case slog.KindGroup:
+		return otlpAnyValue{KvlistValue: &otlpKvlist{Values: otlpAttributes(v.Group())}}
+	default:
+		switch a := v.Any().(type) {
+		case []byte:
+			return otlpAnyValue{BytesValue: a}
+		case fmt.Stringer:
+			return otlpString(a.String())
+		}
+		// structs, maps and slices are exported as their JSON encoding
+		if data, err := json.Marshal(v.Any()); err == nil {
+			return otlpString(string(data))
+		}
+		return otlpString(fmt.Sprintf("%+v", v.Any()))
+	}
+}
+
+func otlpString(s string) otlpAnyValue {
+	return otlpAnyValue{StringValue: &s}
+}
+
+// The types below follow the JSON encoding of the OTLP ExportLogsServiceRequest.
+// 64 bit integers are encoded as strings and trace and span IDs as hex.
+
+type otlpRequest struct {
+	ResourceLogs []otlpResourceLogs `json:"resourceLogs"`
+}
+
+type otlpResourceLogs struct {
+	Resource  otlpResource    `json:"resource"`
+	ScopeLogs []otlpScopeLogs `json:"scopeLogs"`
+}
+
+type otlpResource struct {
+	Attributes []otlpKeyValue `json:"attributes"`
+}
+
+type otlpScopeLogs struct {
+	Scope      otlpScope       `json:"scope"`
+	LogRecords []otlpLogRecord `json:"logRecords"`
+}
+
+type otlpScope struct {
+	Name string `json:"name"`
+}
+
+type otlpLogRecord struct {
+	TimeUnixNano         string         `json:"timeUnixNano"`
+	ObservedTimeUnixNano string         `json:"observedTimeUnixNano"`
+	SeverityNumber       int            `json:"severityNumber"`
+	SeverityText         string         `json:"severityText"`
+	Body                 otlpAnyValue   `json:"body"`
+	Attributes           []otlpKeyValue `json:"attributes,omitempty"`
+	TraceID              string         `json:"traceId,omitempty"`
+	SpanID               string         `json:"spanId,omitempty"`
+	Flags                uint32         `json:"flags,omitempty"`
+}
+
+type otlpKeyValue struct {
+	Key   string       `json:"key"`
+	Value otlpAnyValue `json:"value"`
+}
+
+type otlpAnyValue struct {
+	StringValue *string     `json:"stringValue,omitempty"`
+	BoolValue   *bool       `json:"boolValue,omitempty"`
+	IntValue    *string     `json:"intValue,omitempty"`
+	DoubleValue *float64    `json:"doubleValue,omitempty"`
+	KvlistValue *otlpKvlist `json:"kvlistValue,omitempty"`
+	BytesValue  []byte      `json:"bytesValue,omitempty"`
+}
+
+type otlpKvlist struct {
+	Values []otlpKeyValue `json:"values"`
+}
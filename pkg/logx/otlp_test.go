@@ -0,0 +1,196 @@
+package logx
+
+import (
+	"encoding/json"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// collector is a stand-in for an OTLP/HTTP collector keeping the requests it receives.
+type collector struct {
+	mu       sync.Mutex
+	requests []otlpRequest
+	headers  []http.Header
+	status   int
+}
+
+func newCollector(t *testing.T) (*collector, *httptest.Server) {
+	t.Helper()
+	c := &collector{status: http.StatusOK}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, otlpLogsPath, r.URL.Path)
+		assert.Equal(t, "application/json", r.Header.Get("Content-Type"))
+		body, err := io.ReadAll(r.Body)
+		require.NoError(t, err)
+
+		var req otlpRequest
+		require.NoError(t, json.Unmarshal(body, &req))
+		c.mu.Lock()
+		defer c.mu.Unlock()
+		c.requests = append(c.requests, req)
+		c.headers = append(c.headers, r.Header.Clone())
+		w.WriteHeader(c.status)
+	}))
+	t.Cleanup(server.Close)
+	return c, server
+}
+
+func (c *collector) logRecords() []otlpLogRecord {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	var out []otlpLogRecord
+	for _, req := range c.requests {
+		for _, rl := range req.ResourceLogs {
+			for _, sl := range rl.ScopeLogs {
+				out = append(out, sl.LogRecords...)
+			}
+		}
+	}
+	return out
+}
+
+func attributeMap(kvs []otlpKeyValue) map[string]otlpAnyValue {
+	out := make(map[string]otlpAnyValue, len(kvs))
+	for _, kv := range kvs {
+		out[kv.Key] = kv.Value
+	}
+	return out
+}
+
+func TestOTLPExporter(t *testing.T) {
+	c, server := newCollector(t)
+	exporter, err := NewOTLPExporter(OTLPConfig{
+		Endpoint:      server.URL,
+		Headers:       map[string]string{"Authorization": "Bearer t-1"},
+		FlushInterval: time.Hour,
+		Resource:      map[string]string{"k8s.pod.name": "bff-0"},
+	})
+	require.NoError(t, err)
+
+	logger, err := New(Config{Environment: "test", Source: "test-app"},
+		WithWriter(io.Discard), WithOTLP(exporter))
+	require.NoError(t, err)
+
+	traceID := trace.TraceID{0x4b, 0xf9, 0x2f, 0x35, 0x77, 0xb3, 0x4d, 0xa6, 0xa3, 0xce, 0x92, 0x9d, 0x0e, 0x0e, 0x47, 0x36}
+	spanID := trace.SpanID{0x00, 0xf0, 0x67, 0xaa, 0x0b, 0xa9, 0x02, 0xb7}
+	ctx := trace.ContextWithSpanContext(t.Context(), trace.NewSpanContext(trace.SpanContextConfig{
+		TraceID:    traceID,
+		SpanID:     spanID,
+		TraceFlags: trace.FlagsSampled,
+	}))
+
+	logger.WithGroup("request").InfoContext(ctx, "Incoming request",
+		slog.String("method", "GET"),
+		slog.Int("status", 200),
+		slog.Duration("latency", time.Millisecond),
+		slog.Bool("cached", true),
+		slog.String("password", "secret"),
+	)
+	logger.Error("failed", slog.String("email", "john@example.com"))
+	require.NoError(t, exporter.Flush(t.Context()))
+
+	records := c.logRecords()
+	require.Len(t, records, 2)
+
+	info := records[0]
+	assert.Equal(t, 9, info.SeverityNumber)
+	assert.Equal(t, "INFO", info.SeverityText)
+	assert.Equal(t, "Incoming request", *info.Body.StringValue)
+	assert.Equal(t, "4bf92f3577b34da6a3ce929d0e0e4736", info.TraceID)
+	assert.Equal(t, "00f067aa0ba902b7", info.SpanID)
+	assert.Equal(t, uint32(1), info.Flags)
+	assert.NotEmpty(t, info.TimeUnixNano)
+
+	attrs := attributeMap(info.Attributes)
+	assert.Equal(t, "test-app", *attrs["source"].StringValue)
+	require.NotNil(t, attrs["request"].KvlistValue)
+	request := attributeMap(attrs["request"].KvlistValue.Values)
+	assert.Equal(t, "GET", *request["method"].StringValue)
+	assert.Equal(t, "200", *request["status"].IntValue)
+	assert.Equal(t, "1000000", *request["latency"].IntValue)
+	assert.True(t, *request["cached"].BoolValue)
+	assert.Equal(t, DefaultRedactMessage, *request["password"].StringValue, "records are exported after censoring")
+
+	failed := records[1]
+	assert.Equal(t, 17, failed.SeverityNumber)
+	assert.Empty(t, failed.TraceID)
+	assert.NotEqual(t, "john@example.com", *attributeMap(failed.Attributes)["email"].StringValue)
+
+	c.mu.Lock()
+	resource := attributeMap(c.requests[0].ResourceLogs[0].Resource.Attributes)
+	assert.Equal(t, "Bearer t-1", c.headers[0].Get("Authorization"))
+	c.mu.Unlock()
+	assert.Equal(t, "test-app", *resource["service.name"].StringValue)
+	assert.Equal(t, "test", *resource["deployment.environment"].StringValue)
+	assert.Equal(t, "bff-0", *resource["k8s.pod.name"].StringValue)
+	assert.Contains(t, resource, "process.runtime.version")
+
+	require.NoError(t, exporter.Shutdown(t.Context()))
+	logger.Info("after shutdown")
+	assert.Equal(t, uint64(1), exporter.Dropped())
+}
+
+func TestOTLPExporterBatchesAndFailures(t *testing.T) {
+	c, server := newCollector(t)
+	c.status = http.StatusServiceUnavailable
+	exporter, err := NewOTLPExporter(OTLPConfig{
+		Endpoint:      server.URL + otlpLogsPath,
+		Level:         slog.LevelWarn,
+		BatchSize:     2,
+		FlushInterval: time.Hour,
+	})
+	require.NoError(t, err)
+
+	logger, err := New(Config{}, WithWriter(io.Discard), WithOTLP(exporter))
+	require.NoError(t, err)
+	logger.Info("below the exporter level")
+	for i := 0; i < 5; i++ {
+		logger.Warn("warn")
+	}
+	require.NoError(t, exporter.Shutdown(t.Context()))
+
+	c.mu.Lock()
+	assert.Len(t, c.requests, 3, "5 records in batches of 2")
+	c.mu.Unlock()
+	assert.Len(t, c.logRecords(), 5)
+	assert.Equal(t, uint64(5), exporter.Failed())
+}
+
+func TestOTLPSeverity(t *testing.T) {
+	tests := []struct {
+		level slog.Level
+		want  int
+	}{
+		{slog.LevelDebug, 5},
+		{slog.LevelInfo, 9},
+		{slog.LevelWarn, 13},
+		{slog.LevelError, 17},
+		{slog.Level(-20), 1},
+		{slog.Level(20), 24},
+	}
+	for _, tt := range tests {
+		t.Run(tt.level.String(), func(t *testing.T) {
+			assert.Equal(t, tt.want, otlpSeverity(tt.level))
+		})
+	}
+}
+
+func TestNewOTLPExporterInvalid(t *testing.T) {
+	_, err := NewOTLPExporter(OTLPConfig{Endpoint: "localhost:4318"})
+	assert.Error(t, err)
+
+	_, err = NewOTLPExporter(OTLPConfig{Endpoint: "http://localhost:4318", BatchSize: -1})
+	assert.Error(t, err)
+
+	_, err = New(Config{}, WithOTLP(nil))
+	assert.Error(t, err)
+}
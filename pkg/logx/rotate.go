@@ -0,0 +1,355 @@
+package logx
+
+import (
+	"compress/gzip"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// WriteFailurePolicy selects what a RotatingFile does when a write fails,
+// e.g. because the disk is full.
+type WriteFailurePolicy string
+
+const (
+	// WriteFailureReturn returns the error to the caller.
+	WriteFailureReturn WriteFailurePolicy = "return"
+	// WriteFailureStderr writes the record to stderr instead and reports success.
+	WriteFailureStderr WriteFailurePolicy = "stderr"
+	// WriteFailureDrop discards the record and reports success.
+	WriteFailureDrop WriteFailurePolicy = "drop"
+)
+
+const (
+	// backupTimeFormat is sortable, so backups sort from oldest to newest by name
+	backupTimeFormat = "20060102T150405.000"
+	compressSuffix   = ".gz"
+)
+
+// RotateConfig configures a RotatingFile.
+type RotateConfig struct {
+	// Path is the file written to. Missing directories are created.
+	Path string
+	// MaxSize rotates the file before a write would grow it beyond MaxSize bytes.
+	// Zero disables size based rotation.
+	MaxSize int64
+	// Interval rotates the file when the wall clock crosses a multiple of
+	// Interval, e.g. every day at midnight UTC for 24h. Zero disables time
+	// based rotation.
+	Interval time.Duration
+	// MaxBackups is the number of rotated files kept. Zero keeps every backup.
+	MaxBackups int
+	// Compress gzips rotated files in the background.
+	Compress bool
+	// OnWriteFailure defaults to WriteFailureReturn.
+	OnWriteFailure WriteFailurePolicy
+}
+
+// RotatingFile is an io.WriteCloser that rotates the file it writes to by
+// size and time. Use it with WithWriter. It is safe for concurrent use.
+//
+// Rotated files are renamed to name-<timestamp>.ext next to the file, e.g.
+// logs/app-20250709T161433.025.log, and gzipped when Compress is set.
+type RotatingFile struct {
+	cfg RotateConfig
+	now func() time.Time
+
+	mu       sync.Mutex
+	file     *os.File
+	size     int64
+	rotateAt time.Time
+
+	// mill serializes compression and pruning of backups
+	mill     sync.Mutex
+	millWait sync.WaitGroup
+
+	failures atomic.Uint64
+}
+
+// NewRotatingFile opens cfg.Path for appending, creating it when missing.
+func NewRotatingFile(cfg RotateConfig) (*RotatingFile, error) {
+	if cfg.Path == "" {
+		return nil, fmt.Errorf("rotating file path must not be empty")
+	}
+	if cfg.MaxSize < 0 || cfg.Interval < 0 || cfg.MaxBackups < 0 {
+		return nil, fmt.Errorf("rotating file limits must not be negative")
+	}
+	switch cfg.OnWriteFailure {
+	case "":
+		cfg.OnWriteFailure = WriteFailureReturn
+	case WriteFailureReturn, WriteFailureStderr, WriteFailureDrop:
+	default:
+		return nil, fmt.Errorf("unknown write failure policy %q", cfg.OnWriteFailure)
+	}
+
+	f := &RotatingFile{cfg: cfg, now: time.Now}
+	if err := f.open(); err != nil {
+		return nil, err
+	}
+	return f, nil
+}
+
+// Write writes p to the file, rotating it first when it is due.
+func (f *RotatingFile) Write(p []byte) (int, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	n, err := f.write(p)
+	if err == nil {
+		return n, nil
+	}
+
+	f.failures.Add(1)
+	switch f.cfg.OnWriteFailure {
+	case WriteFailureStderr:
+		if _, stderrErr := os.Stderr.Write(p); stderrErr != nil {
+			return n, err
+		}
+		return len(p), nil
+	case WriteFailureDrop:
+		return len(p), nil
+	default:
+		return n, err
+	}
+}
+
+func (f *RotatingFile) write(p []byte) (int, error) {
+	if f.file == nil {
+		// a previous rotation or reopen failed; try again
+		if err := f.open(); err != nil {
+			return 0, err
+		}
+	}
+	if f.due(int64(len(p))) {
+		if err := f.rotate(); err != nil {
+			return 0, err
+		}
+	}
+
+	n, err := f.file.Write(p)
+	f.size += int64(n)
+	return n, err
+}
+
+// due reports whether the file must be rotated before writing n more bytes.
+// An empty file is never rotated by size, so records larger than MaxSize are still written.
+func (f *RotatingFile) due(n int64) bool {
+	if f.cfg.MaxSize > 0 && f.size > 0 && f.size+n > f.cfg.MaxSize {
+		return true
+	}
+	return f.cfg.Interval > 0 && !f.now().Before(f.rotateAt)
+}
+
+// Rotate rotates the file immediately.
+func (f *RotatingFile) Rotate() error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	return f.rotate()
+}
+
+// Reopen closes and reopens the file at the configured path. Call it, e.g. on
+// SIGUSR1, after an external tool such as logrotate moved the file away.
+func (f *RotatingFile) Reopen() error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if err := f.close(); err != nil {
+		return err
+	}
+	return f.open()
+}
+
+// Close waits for background compression and closes the file.
+func (f *RotatingFile) Close() error {
+	f.mu.Lock()
+	err := f.close()
+	f.mu.Unlock()
+
+	f.millWait.Wait()
+	return err
+}
+
+// Failures returns the number of writes that failed since the file was created.
+func (f *RotatingFile) Failures() uint64 {
+	return f.failures.Load()
+}
+
+// open must be called with mu held.
+func (f *RotatingFile) open() error {
+	if err := os.MkdirAll(filepath.Dir(f.cfg.Path), 0o755); err != nil {
+		return fmt.Errorf("failed to create log directory: %w", err)
+	}
+
+	file, err := os.OpenFile(f.cfg.Path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o644)
+	if err != nil {
+		return fmt.Errorf("failed to open log file: %w", err)
+	}
+	info, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return fmt.Errorf("failed to stat log file: %w", err)
+	}
+
+	f.file = file
+	f.size = info.Size()
+	if f.cfg.Interval > 0 {
+		f.rotateAt = f.now().Truncate(f.cfg.Interval).Add(f.cfg.Interval)
+	}
+	return nil
+}
+
+// close must be called with mu held.
+func (f *RotatingFile) close() error {
+	if f.file == nil {
+		return nil
+	}
+	err := f.file.Close()
+	f.file = nil
+	return err
+}
+
+// rotate must be called with mu held.
+func (f *RotatingFile) rotate() error {
+	if err := f.close(); err != nil {
+		return err
+	}
+
+	backup := f.backupName(f.now())
+	if err := os.Rename(f.cfg.Path, backup); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("failed to rotate log file: %w", err)
+	}
+	if err := f.open(); err != nil {
+		return err
+	}
+
+	f.millWait.Add(1)
+	go func() {
+		defer f.millWait.Done()
+		f.millBackups(backup)
+	}()
+	return nil
+}
+
+// backupName returns a free backup name for time t. Rotations within the same
+// millisecond move on to the next millisecond so a backup is never overwritten.
+func (f *RotatingFile) backupName(t time.Time) string {
+	dir, prefix, ext := f.nameParts()
+	for {
+		name := filepath.Join(dir, prefix+t.UTC().Format(backupTimeFormat)+ext)
+		if !exists(name) && !exists(name+compressSuffix) {
+			return name
+		}
+		t = t.Add(time.Millisecond)
+	}
+}
+
+func exists(path string) bool {
+	_, err := os.Lstat(path)
+	return err == nil
+}
+
+// nameParts splits the path into its directory, the backup name prefix and the extension.
+func (f *RotatingFile) nameParts() (dir, prefix, ext string) {
+	dir = filepath.Dir(f.cfg.Path)
+	base := filepath.Base(f.cfg.Path)
+	ext = filepath.Ext(base)
+	return dir, strings.TrimSuffix(base, ext) + "-", ext
+}
+
+// millBackups compresses a new backup and removes backups beyond MaxBackups.
+// Errors are reported on stderr, since the logger itself may be failing.
+func (f *RotatingFile) millBackups(backup string) {
+	f.mill.Lock()
+	defer f.mill.Unlock()
+
+	if f.cfg.Compress {
+		if err := compressFile(backup); err != nil {
+			fmt.Fprintf(os.Stderr, "logx: failed to compress %s: %v\n", backup, err)
+		}
+	}
+	if f.cfg.MaxBackups > 0 {
+		if err := f.pruneBackups(); err != nil {
+			fmt.Fprintf(os.Stderr, "logx: failed to remove old log files: %v\n", err)
+		}
+	}
+}
+
+// backups returns the rotated files from oldest to newest.
+func (f *RotatingFile) backups() ([]string, error) {
+	dir, prefix, ext := f.nameParts()
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	var backups []string
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() || !strings.HasPrefix(name, prefix) {
+			continue
+		}
+		stamp := strings.TrimSuffix(strings.TrimSuffix(name[len(prefix):], compressSuffix), ext)
+		if _, err := time.Parse(backupTimeFormat, stamp); err != nil {
+			continue
+		}
+		backups = append(backups, filepath.Join(dir, name))
+	}
+	sort.Strings(backups)
+	return backups, nil
+}
+
+func (f *RotatingFile) pruneBackups() error {
+	backups, err := f.backups()
+	if err != nil {
+		return err
+	}
+	for len(backups) > f.cfg.MaxBackups {
+		if err := os.Remove(backups[0]); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+		backups = backups[1:]
+	}
+	return nil
+}
+
+// compressFile gzips path into path.gz and removes path.
+func compressFile(path string) (err error) {
+	src, err := os.Open(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return err
+	}
+	defer src.Close()
+
+	dst, err := os.OpenFile(path+compressSuffix, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0o644)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err != nil {
+			dst.Close()
+			os.Remove(path + compressSuffix)
+		}
+	}()
+
+	gz := gzip.NewWriter(dst)
+	if _, err = io.Copy(gz, src); err != nil {
+		return err
+	}
+	if err = gz.Close(); err != nil {
+		return err
+	}
+	if err = dst.Close(); err != nil {
+		return err
+	}
+	return os.Remove(path)
+}
@@ -0,0 +1,165 @@
+package logx
+
+import (
+	"compress/gzip"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeClock is a settable clock for time based rotation.
+type fakeClock struct {
+	t time.Time
+}
+
+func (c *fakeClock) now() time.Time { return c.t }
+
+func newTestRotatingFile(t *testing.T, cfg RotateConfig, clock *fakeClock) *RotatingFile {
+	t.Helper()
+	f, err := NewRotatingFile(cfg)
+	require.NoError(t, err)
+	f.now = clock.now
+	// the first open ran with the real clock
+	if cfg.Interval > 0 {
+		f.rotateAt = clock.t.Truncate(cfg.Interval).Add(cfg.Interval)
+	}
+	t.Cleanup(func() { f.Close() })
+	return f
+}
+
+func readFile(t *testing.T, path string) string {
+	t.Helper()
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	return string(data)
+}
+
+func TestRotatingFileBySize(t *testing.T) {
+	dir := t.TempDir()
+	clock := &fakeClock{t: time.Date(2025, 7, 9, 16, 14, 33, 0, time.UTC)}
+	f := newTestRotatingFile(t, RotateConfig{Path: filepath.Join(dir, "logs", "app.log"), MaxSize: 10, MaxBackups: 2}, clock)
+
+	for _, line := range []string{"first\n", "second\n", "third\n", "fourth\n"} {
+		_, err := f.Write([]byte(line))
+		require.NoError(t, err)
+		clock.t = clock.t.Add(time.Second)
+	}
+	require.NoError(t, f.Close())
+
+	assert.Equal(t, "fourth\n", readFile(t, filepath.Join(dir, "logs", "app.log")))
+	backups, err := f.backups()
+	require.NoError(t, err)
+	require.Len(t, backups, 2, "oldest backup must be pruned")
+	assert.Equal(t, "second\n", readFile(t, backups[0]))
+	assert.Equal(t, "third\n", readFile(t, backups[1]))
+	assert.Equal(t, filepath.Join(dir, "logs", "app-20250709T161435.000.log"), backups[0])
+}
+
+func TestRotatingFileByTime(t *testing.T) {
+	dir := t.TempDir()
+	clock := &fakeClock{t: time.Date(2025, 7, 9, 23, 59, 0, 0, time.UTC)}
+	f := newTestRotatingFile(t, RotateConfig{Path: filepath.Join(dir, "app.log"), Interval: 24 * time.Hour}, clock)
+
+	_, err := f.Write([]byte("before midnight\n"))
+	require.NoError(t, err)
+	clock.t = clock.t.Add(2 * time.Minute)
+	_, err = f.Write([]byte("after midnight\n"))
+	require.NoError(t, err)
+	require.NoError(t, f.Close())
+
+	assert.Equal(t, "after midnight\n", readFile(t, filepath.Join(dir, "app.log")))
+	backups, err := f.backups()
+	require.NoError(t, err)
+	require.Len(t, backups, 1)
+	assert.Equal(t, "before midnight\n", readFile(t, backups[0]))
+}
+
+func TestRotatingFileCompress(t *testing.T) {
+	dir := t.TempDir()
+	clock := &fakeClock{t: time.Date(2025, 7, 9, 16, 14, 33, 0, time.UTC)}
+	f := newTestRotatingFile(t, RotateConfig{Path: filepath.Join(dir, "app.log"), Compress: true}, clock)
+
+	_, err := f.Write([]byte("compressed\n"))
+	require.NoError(t, err)
+	require.NoError(t, f.Rotate())
+	require.NoError(t, f.Rotate(), "rotating within the same millisecond must not overwrite a backup")
+	require.NoError(t, f.Close())
+
+	backups, err := f.backups()
+	require.NoError(t, err)
+	require.Len(t, backups, 2)
+	require.True(t, strings.HasSuffix(backups[0], ".log.gz"))
+
+	file, err := os.Open(backups[0])
+	require.NoError(t, err)
+	defer file.Close()
+	gz, err := gzip.NewReader(file)
+	require.NoError(t, err)
+	data, err := io.ReadAll(gz)
+	require.NoError(t, err)
+	assert.Equal(t, "compressed\n", string(data))
+}
+
+func TestRotatingFileReopen(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "app.log")
+	f := newTestRotatingFile(t, RotateConfig{Path: path}, &fakeClock{t: time.Now()})
+
+	_, err := f.Write([]byte("old\n"))
+	require.NoError(t, err)
+	// an external tool moves the file away
+	require.NoError(t, os.Rename(path, path+".1"))
+	require.NoError(t, f.Reopen())
+	_, err = f.Write([]byte("new\n"))
+	require.NoError(t, err)
+
+	assert.Equal(t, "old\n", readFile(t, path+".1"))
+	assert.Equal(t, "new\n", readFile(t, path))
+}
+
+func TestRotatingFileWriteFailure(t *testing.T) {
+	tests := []struct {
+		policy  WriteFailurePolicy
+		wantErr bool
+	}{
+		{policy: WriteFailureReturn, wantErr: true},
+		{policy: WriteFailureDrop, wantErr: false},
+		{policy: WriteFailureStderr, wantErr: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(string(tt.policy), func(t *testing.T) {
+			dir := t.TempDir()
+			f := newTestRotatingFile(t, RotateConfig{Path: filepath.Join(dir, "app.log"), OnWriteFailure: tt.policy}, &fakeClock{t: time.Now()})
+			// make the next write fail and every reopen fail
+			require.NoError(t, f.file.Close())
+			require.NoError(t, os.RemoveAll(dir))
+			require.NoError(t, os.WriteFile(dir, nil, 0o644))
+			t.Cleanup(func() { os.Remove(dir) })
+
+			n, err := f.Write([]byte("lost\n"))
+			if tt.wantErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, len("lost\n"), n)
+			}
+			assert.Equal(t, uint64(1), f.Failures())
+		})
+	}
+}
+
+func TestNewRotatingFileInvalid(t *testing.T) {
+	_, err := NewRotatingFile(RotateConfig{})
+	assert.Error(t, err)
+	_, err = NewRotatingFile(RotateConfig{Path: filepath.Join(t.TempDir(), "app.log"), OnWriteFailure: "retry"})
+	assert.Error(t, err)
+	_, err = NewRotatingFile(RotateConfig{Path: filepath.Join(t.TempDir(), "app.log"), MaxSize: -1})
+	assert.Error(t, err)
+}
@@ -0,0 +1,294 @@
+package logx
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+const (
+	// DefaultSampleInterval is the interval used when SamplingConfig.Interval is zero.
+	DefaultSampleInterval = time.Second
+	// maxSampledKeys bounds the sampler state; records with new keys beyond it are not sampled
+	maxSampledKeys = 10000
+
+	// SuppressedKey is the attribute key of the number of records a summary stands for.
+	SuppressedKey = "suppressed"
+	// SuppressedSinceKey is the attribute key of the time the first suppressed record was seen.
+	SuppressedSinceKey = "suppressed_since"
+)
+
+// SampleRule limits records with the same level and message per interval.
+type SampleRule struct {
+	// First records per interval are always logged.
+	First int
+	// Thereafter logs every Thereafter-th record after the first ones and
+	// drops the rest. Zero drops every record after the first ones.
+	Thereafter int
+	// Dedup suppresses every record after the first ones instead and logs one
+	// summary record per interval with the number suppressed. Thereafter is
+	// ignored. No record is lost without a trace.
+	Dedup bool
+}
+
+// SamplingConfig configures a Sampler.
+type SamplingConfig struct {
+	// Interval is the window records are counted in. Defaults to DefaultSampleInterval.
+	Interval time.Duration
+	// Rules maps a level to its rule. A rule applies to its level and to higher
+	// levels up to the next rule. Records below the lowest rule are not sampled.
+	// Rules for slog.LevelError and above must not fully drop records: they
+	// need Dedup or a positive Thereafter.
+	Rules map[slog.Level]SampleRule
+	// Exempt lists messages that are never sampled, such as access logs that
+	// share one message across every route and would share one budget.
+	Exempt []string
+}
+
+// Sampler drops repeated records under load. Records are keyed by level and
+// message. Pass it with WithSampler and call Close on shutdown to log the
+// pending summaries.
+type Sampler struct {
+	interval time.Duration
+	levels   []slog.Level
+	rules    map[slog.Level]SampleRule
+	exempt   map[string]struct{}
+	now      func() time.Time
+
+	mu      sync.Mutex
+	entries map[sampleKey]*sampleEntry
+
+	dropped atomic.Uint64
+	stop    chan struct{}
+	done    chan struct{}
+	once    sync.Once
+}
+
+type sampleKey struct {
+	level slog.Level
+	msg   string
+}
+
+type sampleEntry struct {
+	start time.Time
+	count int
+	// suppressed counts records held back by a dedup rule since firstSuppressed
+	suppressed      int
+	firstSuppressed time.Time
+	// handler is the handler the last suppressed record was sent to; the
+	// summary goes to it so it carries the same logger attributes
+	handler slog.Handler
+	pc      uintptr
+}
+
+// summary is a record standing for suppressed records.
+type summary struct {
+	handler slog.Handler
+	record  slog.Record
+}
+
+// NewSampler creates a Sampler and starts the goroutine logging summaries of
+// deduplicated records at the end of each interval.
+func NewSampler(cfg SamplingConfig) (*Sampler, error) {
+	if cfg.Interval < 0 {
+		return nil, fmt.Errorf("sample interval must not be negative")
+	}
+	if cfg.Interval == 0 {
+		cfg.Interval = DefaultSampleInterval
+	}
+
+	s := &Sampler{
+		interval: cfg.Interval,
+		rules:    make(map[slog.Level]SampleRule, len(cfg.Rules)),
+		exempt:   make(map[string]struct{}, len(cfg.Exempt)),
+		now:      time.Now,
+		entries:  make(map[sampleKey]*sampleEntry),
+		stop:     make(chan struct{}),
+		done:     make(chan struct{}),
+	}
+	for level, rule := range cfg.Rules {
+		if rule.First < 0 || rule.Thereafter < 0 {
+			return nil, fmt.Errorf("sample rule for %s must not be negative", level)
+		}
+		if level >= slog.LevelError && !rule.Dedup && rule.Thereafter == 0 {
+			return nil, fmt.Errorf("sample rule for %s must not drop every record; set Dedup or Thereafter", level)
+		}
+		s.rules[level] = rule
+		s.levels = append(s.levels, level)
+	}
+	sort.Slice(s.levels, func(i, j int) bool { return s.levels[i] < s.levels[j] })
+	for _, msg := range cfg.Exempt {
+		s.exempt[msg] = struct{}{}
+	}
+
+	go s.run()
+	return s, nil
+}
+
+func (s *Sampler) run() {
+	defer close(s.done)
+	ticker := time.NewTicker(s.interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			s.emit(s.expire(false))
+		case <-s.stop:
+			return
+		}
+	}
+}
+
+// Close stops the sampler and logs the summaries of records suppressed so far.
+func (s *Sampler) Close(ctx context.Context) error {
+	s.once.Do(func() { close(s.stop) })
+	select {
+	case <-s.done:
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+	s.emit(s.expire(true))
+	return nil
+}
+
+// Dropped returns the number of records dropped by sampling. Records
+// suppressed by a dedup rule are not counted, since their summary stands for them.
+func (s *Sampler) Dropped() uint64 {
+	return s.dropped.Load()
+}
+
+// ruleFor returns the rule of the highest configured level not above level.
+func (s *Sampler) ruleFor(level slog.Level) (SampleRule, bool) {
+	i := sort.Search(len(s.levels), func(i int) bool { return s.levels[i] > level })
+	if i == 0 {
+		return SampleRule{}, false
+	}
+	return s.rules[s.levels[i-1]], true
+}
+
+// allow reports whether r is logged. handler is where a summary of r goes.
+func (s *Sampler) allow(handler slog.Handler, r slog.Record) bool {
+	if _, ok := s.exempt[r.Message]; ok {
+		return true
+	}
+	rule, ok := s.ruleFor(r.Level)
+	if !ok {
+		return true
+	}
+
+	now := s.now()
+	key := sampleKey{level: r.Level, msg: r.Message}
+
+	s.mu.Lock()
+	entry, ok := s.entries[key]
+	var pending *summary
+	switch {
+	case !ok:
+		if len(s.entries) >= maxSampledKeys {
+			s.mu.Unlock()
+			return true
+		}
+		entry = &sampleEntry{start: now}
+		s.entries[key] = entry
+	case now.Sub(entry.start) >= s.interval:
+		// the sweeper has not reset this window yet
+		pending = s.summaryOf(key, entry, now)
+		*entry = sampleEntry{start: now}
+	}
+
+	entry.count++
+	allowed := true
+	if entry.count > rule.First {
+		switch {
+		case rule.Dedup:
+			if entry.suppressed == 0 {
+				entry.firstSuppressed = r.Time
+			}
+			entry.suppressed++
+			entry.handler = handler
+			entry.pc = r.PC
+			allowed = false
+		case rule.Thereafter > 0 && (entry.count-rule.First)%rule.Thereafter == 0:
+		default:
+			s.dropped.Add(1)
+			allowed = false
+		}
+	}
+	s.mu.Unlock()
+
+	if pending != nil {
+		s.emit([]summary{*pending})
+	}
+	return allowed
+}
+
+// expire ends the windows that are over, or every window when all is set,
+// and returns the summaries to log.
+func (s *Sampler) expire(all bool) []summary {
+	now := s.now()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var summaries []summary
+	for key, entry := range s.entries {
+		if !all && now.Sub(entry.start) < s.interval {
+			continue
+		}
+		if pending := s.summaryOf(key, entry, now); pending != nil {
+			summaries = append(summaries, *pending)
+		}
+		delete(s.entries, key)
+	}
+	return summaries
+}
+
+// summaryOf returns the summary of the records suppressed in entry, if any.
+// It must be called with mu held.
+func (s *Sampler) summaryOf(key sampleKey, entry *sampleEntry, now time.Time) *summary {
+	if entry.suppressed == 0 {
+		return nil
+	}
+	r := slog.NewRecord(now, key.level, key.msg, entry.pc)
+	r.AddAttrs(
+		slog.Int(SuppressedKey, entry.suppressed),
+		slog.Time(SuppressedSinceKey, entry.firstSuppressed),
+	)
+	return &summary{handler: entry.handler, record: r}
+}
+
+// emit logs summaries outside the lock, since handlers may block on I/O.
+func (s *Sampler) emit(summaries []summary) {
+	for _, sum := range summaries {
+		_ = sum.handler.Handle(context.Background(), sum.record)
+	}
+}
+
+// samplingHandler drops records the sampler does not allow before they are censored.
+type samplingHandler struct {
+	handler slog.Handler
+	sampler *Sampler
+}
+
+func (h *samplingHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	return h.handler.Enabled(ctx, level)
+}
+
+func (h *samplingHandler) Handle(ctx context.Context, r slog.Record) error {
+	if !h.sampler.allow(h.handler, r) {
+		return nil
+	}
+	return h.handler.Handle(ctx, r)
+}
+
+func (h *samplingHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return &samplingHandler{handler: h.handler.WithAttrs(attrs), sampler: h.sampler}
+}
+
+func (h *samplingHandler) WithGroup(name string) slog.Handler {
+	return &samplingHandler{handler: h.handler.WithGroup(name), sampler: h.sampler}
+}
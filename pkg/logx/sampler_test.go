@@ -0,0 +1,163 @@
+package logx
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"log/slog"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newSampledLogger(t *testing.T, cfg SamplingConfig) (*slog.Logger, *Sampler, *fakeClock, *bytes.Buffer) {
+	t.Helper()
+	sampler, err := NewSampler(cfg)
+	require.NoError(t, err)
+	clock := &fakeClock{t: time.Date(2025, 7, 9, 16, 14, 33, 0, time.UTC)}
+	sampler.now = clock.now
+
+	buf := &bytes.Buffer{}
+	logger, err := New(Config{Source: "test-app"}, WithWriter(buf), WithSampler(sampler))
+	require.NoError(t, err)
+	return logger, sampler, clock, buf
+}
+
+func records(t *testing.T, buf *bytes.Buffer) []map[string]any {
+	t.Helper()
+	var out []map[string]any
+	for _, line := range strings.Split(strings.TrimSpace(buf.String()), "\n") {
+		if line == "" {
+			continue
+		}
+		var record map[string]any
+		require.NoError(t, json.Unmarshal([]byte(line), &record))
+		out = append(out, record)
+	}
+	return out
+}
+
+func TestSamplerFirstThenEveryMth(t *testing.T) {
+	logger, sampler, clock, buf := newSampledLogger(t, SamplingConfig{
+		Interval: time.Hour,
+		Rules:    map[slog.Level]SampleRule{slog.LevelInfo: {First: 2, Thereafter: 3}},
+	})
+
+	for i := 0; i < 10; i++ {
+		logger.Info("Incoming request")
+	}
+	// records 1, 2, then 5 and 8
+	assert.Len(t, records(t, buf), 4)
+	assert.Equal(t, uint64(6), sampler.Dropped())
+
+	// another message has its own budget
+	logger.Info("Another message")
+	assert.Len(t, records(t, buf), 5)
+
+	// a new interval starts a new budget
+	clock.t = clock.t.Add(time.Hour)
+	logger.Info("Incoming request")
+	logger.Info("Incoming request")
+	assert.Len(t, records(t, buf), 7)
+
+	require.NoError(t, sampler.Close(context.Background()))
+}
+
+func TestSamplerExempt(t *testing.T) {
+	logger, sampler, _, buf := newSampledLogger(t, SamplingConfig{
+		Interval: time.Hour,
+		Rules:    map[slog.Level]SampleRule{slog.LevelDebug: {First: 2, Thereafter: 0}},
+		Exempt:   []string{"Incoming request"},
+	})
+
+	for i := 0; i < 10; i++ {
+		logger.Info("Incoming request", slog.String("path", "/r/abc"))
+		logger.Warn("Not Found", slog.String("path", "/r/abc"))
+	}
+
+	// exempt messages are all logged, the others are still sampled
+	counts := map[string]int{}
+	for _, record := range records(t, buf) {
+		counts[record["msg"].(string)]++
+	}
+	assert.Equal(t, map[string]int{"Incoming request": 10, "Not Found": 2}, counts)
+	assert.Equal(t, uint64(8), sampler.Dropped())
+
+	require.NoError(t, sampler.Close(context.Background()))
+}
+
+func TestSamplerRulesByLevel(t *testing.T) {
+	logger, _, _, buf := newSampledLogger(t, SamplingConfig{
+		Interval: time.Hour,
+		Rules: map[slog.Level]SampleRule{
+			slog.LevelInfo:  {First: 1},
+			slog.LevelError: {First: 1, Thereafter: 2},
+		},
+	})
+
+	for i := 0; i < 4; i++ {
+		logger.Debug("below every rule")
+		logger.Info("info")
+		logger.Warn("warn uses the info rule")
+		logger.Error("error")
+	}
+
+	counts := map[string]int{}
+	for _, record := range records(t, buf) {
+		counts[record["msg"].(string)]++
+	}
+	assert.Equal(t, 4, counts["below every rule"])
+	assert.Equal(t, 1, counts["info"])
+	assert.Equal(t, 1, counts["warn uses the info rule"])
+	assert.Equal(t, 2, counts["error"])
+}
+
+func TestSamplerDedup(t *testing.T) {
+	logger, sampler, clock, buf := newSampledLogger(t, SamplingConfig{
+		Interval: time.Hour,
+		Rules:    map[slog.Level]SampleRule{slog.LevelError: {First: 1, Dedup: true}},
+	})
+	service := logger.With(slog.String("logger", "deeplink_service"))
+
+	for i := 0; i < 1000; i++ {
+		service.Error("Calling GetDeeplink in service failed", slog.String("password", "secret"))
+	}
+	require.Len(t, records(t, buf), 1)
+
+	// the next record after the interval first logs the summary
+	clock.t = clock.t.Add(time.Hour)
+	service.Error("Calling GetDeeplink in service failed")
+
+	logged := records(t, buf)
+	require.Len(t, logged, 3)
+	summary := logged[1]
+	assert.Equal(t, "ERROR", summary["level"])
+	assert.Equal(t, "Calling GetDeeplink in service failed", summary["msg"])
+	assert.Equal(t, float64(999), summary[SuppressedKey])
+	assert.Equal(t, "deeplink_service", summary["logger"])
+	assert.Equal(t, "test-app", summary["source"])
+	assert.Contains(t, summary, SuppressedSinceKey)
+	assert.Zero(t, sampler.Dropped(), "deduplicated records are summarized, not dropped")
+
+	// Close logs the summary of the open window
+	service.Error("Calling GetDeeplink in service failed")
+	require.NoError(t, sampler.Close(context.Background()))
+	logged = records(t, buf)
+	require.Len(t, logged, 4)
+	assert.Equal(t, float64(1), logged[3][SuppressedKey])
+	assert.NotContains(t, buf.String(), "secret")
+}
+
+func TestNewSamplerInvalid(t *testing.T) {
+	_, err := NewSampler(SamplingConfig{Rules: map[slog.Level]SampleRule{slog.LevelError: {First: 10}}})
+	assert.Error(t, err, "errors must never be fully dropped")
+
+	_, err = NewSampler(SamplingConfig{Rules: map[slog.Level]SampleRule{slog.LevelInfo: {First: -1}}})
+	assert.Error(t, err)
+
+	_, err = NewSampler(SamplingConfig{Interval: -time.Second})
+	assert.Error(t, err)
+}
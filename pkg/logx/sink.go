@@ -0,0 +1,175 @@
+package logx
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+)
+
+// Format selects how a sink encodes records.
+type Format string
+
+const (
+	// FormatJSON writes one JSON object per record.
+	FormatJSON Format = "json"
+	// FormatText writes logfmt style key=value pairs, see slog.TextHandler.
+	FormatText Format = "text"
+	// FormatConsole writes human readable lines with colorized levels, for terminals.
+	FormatConsole Format = "console"
+	// FormatPretty writes a colorized header line per record followed by its
+	// groups as indented blocks and stack traces frame by frame, for local development.
+	FormatPretty Format = "pretty"
+)
+
+// Sink is one destination of a logger with its own level and format.
+// Every sink receives records that are already censored.
+type Sink struct {
+	Writer io.Writer
+	// Level is the minimum level written to this sink. Nil writes every record
+	// the logger level lets through.
+	Level slog.Leveler
+	// Format defaults to FormatJSON.
+	Format Format
+	// Color adds ANSI colors to FormatConsole and FormatPretty. Set it only
+	// when Writer is a terminal, e.g. with isatty; escape codes garble files
+	// and log collectors.
+	Color bool
+}
+
+func (s Sink) validate() error {
+	if s.Writer == nil {
+		return fmt.Errorf("sink writer must not be nil")
+	}
+	switch s.Format {
+	case "", FormatJSON, FormatText, FormatConsole, FormatPretty:
+		return nil
+	default:
+		return fmt.Errorf("unknown sink format %q", s.Format)
+	}
+}
+
+// ParseFormat parses a sink format name.
+func ParseFormat(s string) (Format, error) {
+	format := Format(s)
+	if err := (Sink{Writer: io.Discard, Format: format}).validate(); err != nil {
+		return "", err
+	}
+	if format == "" {
+		return FormatJSON, nil
+	}
+	return format, nil
+}
+
+// newSinkHandler creates the handler writing to one sink. floor is used when
+// the sink has no level of its own.
+func newSinkHandler(sink Sink, floor slog.Leveler, addSource bool) slog.Handler {
+	level := sink.Level
+	if level == nil {
+		level = floor
+	}
+	opts := &slog.HandlerOptions{
+		Level:       level,
+		AddSource:   addSource,
+		ReplaceAttr: replaceErrorAttribute,
+	}
+
+	switch sink.Format {
+	case FormatText:
+		return slog.NewTextHandler(sink.Writer, opts)
+	case FormatConsole:
+		return newConsoleHandler(sink.Writer, opts, sink.Color)
+	case FormatPretty:
+		return newPrettyHandler(sink.Writer, opts, sink.Color)
+	default:
+		return slog.NewJSONHandler(sink.Writer, opts)
+	}
+}
+
+// multiHandler fans records out to every sink that is enabled for their level.
+type multiHandler struct {
+	handlers []slog.Handler
+}
+
+// newMultiHandler returns the only handler when there is one, avoiding the fan out.
+func newMultiHandler(handlers []slog.Handler) slog.Handler {
+	if len(handlers) == 1 {
+		return handlers[0]
+	}
+	return &multiHandler{handlers: handlers}
+}
+
+func (h *multiHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	for _, handler := range h.handlers {
+		if handler.Enabled(ctx, level) {
+			return true
+		}
+	}
+	return false
+}
+
+// Handle writes the record to every enabled sink. A failing sink does not
+// stop the others; their errors are joined.
+func (h *multiHandler) Handle(ctx context.Context, r slog.Record) error {
+	var errs []error
+	for _, handler := range h.handlers {
+		if !handler.Enabled(ctx, r.Level) {
+			continue
+		}
+		if err := handler.Handle(ctx, r.Clone()); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func (h *multiHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	handlers := make([]slog.Handler, len(h.handlers))
+	for i, handler := range h.handlers {
+		handlers[i] = handler.WithAttrs(attrs)
+	}
+	return &multiHandler{handlers: handlers}
+}
+
+func (h *multiHandler) WithGroup(name string) slog.Handler {
+	handlers := make([]slog.Handler, len(h.handlers))
+	for i, handler := range h.handlers {
+		handlers[i] = handler.WithGroup(name)
+	}
+	return &multiHandler{handlers: handlers}
+}
+
+// groupOrAttrs is a group opened with WithGroup or the attributes added with
+// WithAttrs, for handlers that build the attribute tree of a record when it is
+// handled rather than preformatting it.
+type groupOrAttrs struct {
+	group string
+	attrs []slog.Attr
+}
+
+func withGroupOrAttrs(goas []groupOrAttrs, goa groupOrAttrs) []groupOrAttrs {
+	return append(append([]groupOrAttrs(nil), goas...), goa)
+}
+
+// nestAttrs returns the attributes of r nested in the open groups, preceded by
+// the attributes added before each group was opened.
+func nestAttrs(goas []groupOrAttrs, r slog.Record) []slog.Attr {
+	attrs := make([]slog.Attr, 0, r.NumAttrs())
+	r.Attrs(func(attr slog.Attr) bool {
+		attrs = append(attrs, attr)
+		return true
+	})
+	// nest innermost first
+	for i := len(goas) - 1; i >= 0; i-- {
+		goa := goas[i]
+		if goa.group != "" {
+			if len(attrs) > 0 {
+				attrs = []slog.Attr{{Key: goa.group, Value: slog.GroupValue(attrs...)}}
+			}
+			continue
+		}
+		attrs = append(append([]slog.Attr(nil), goa.attrs...), attrs...)
+	}
+	return attrs
+}
@@ -0,0 +1,173 @@
+package logx
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"log/slog"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var ansiCodes = regexp.MustCompile(`\x1b\[\d+m`)
+
+type failingWriter struct{}
+
+func (failingWriter) Write([]byte) (int, error) { return 0, errors.New("disk full") }
+
+func TestSinks(t *testing.T) {
+	console, file, errorFile := &bytes.Buffer{}, &bytes.Buffer{}, &bytes.Buffer{}
+	logger, err := New(Config{Environment: "test", Source: "test-app"},
+		WithLevel(slog.LevelDebug),
+		WithSink(Sink{Writer: console, Format: FormatConsole, Level: slog.LevelDebug, Color: true}),
+		WithSink(Sink{Writer: file, Level: slog.LevelInfo}),
+		WithSink(Sink{Writer: errorFile, Level: slog.LevelError}),
+	)
+	require.NoError(t, err)
+
+	logger.Debug("debug record", slog.String("password", "secret"))
+	logger.Info("info record", slog.Group("request", slog.String("token", "t-1")))
+	logger.Error("error record", slog.String("email", "john@example.com"))
+
+	assert.Equal(t, 3, strings.Count(console.String(), "\n"))
+	assert.Equal(t, 2, strings.Count(file.String(), "\n"))
+	assert.Equal(t, 1, strings.Count(errorFile.String(), "\n"))
+	assert.NotContains(t, file.String(), "debug record")
+	assert.Contains(t, errorFile.String(), `"msg":"error record"`)
+
+	for name, out := range map[string]string{"console": console.String(), "file": file.String(), "error file": errorFile.String()} {
+		assert.NotContains(t, out, "secret", name)
+		assert.NotContains(t, out, "t-1", name)
+		assert.NotContains(t, out, "john@example.com", name)
+	}
+	assert.Contains(t, console.String(), "request.token="+ansiReset+DefaultRedactMessage)
+}
+
+func TestSinksLevelsStillApply(t *testing.T) {
+	console := &bytes.Buffer{}
+	levels := NewLevels(slog.LevelWarn)
+	logger, err := New(Config{}, WithLevels(levels), WithSink(Sink{Writer: console, Format: FormatText, Level: slog.LevelDebug}))
+	require.NoError(t, err)
+
+	logger.Info("filtered by the logger level")
+	assert.Empty(t, console.String())
+
+	levels.SetLevel(slog.LevelDebug)
+	logger.Debug("written")
+	assert.Contains(t, console.String(), "msg=written")
+}
+
+func TestSinkFailureDoesNotStopOthers(t *testing.T) {
+	file := &bytes.Buffer{}
+	handler := newMultiHandler([]slog.Handler{
+		newSinkHandler(Sink{Writer: failingWriter{}}, slog.LevelInfo, false),
+		newSinkHandler(Sink{Writer: file}, slog.LevelInfo, false),
+	})
+
+	err := slog.New(handler).Handler().Handle(t.Context(), slog.NewRecord(time.Now(), slog.LevelInfo, "msg", 0))
+	assert.ErrorContains(t, err, "disk full")
+	assert.Contains(t, file.String(), `"msg":"msg"`)
+}
+
+func TestConsoleHandler(t *testing.T) {
+	buf := &bytes.Buffer{}
+	logger := slog.New(newConsoleHandler(buf, &slog.HandlerOptions{Level: slog.LevelDebug, ReplaceAttr: replaceErrorAttribute}, false))
+
+	logger.With(slog.String("source", "bff")).WithGroup("request").Info("Incoming request",
+		slog.String("method", "GET"),
+		slog.String("query", "a=b c"),
+		slog.Group("header", slog.String("accept", "*/*")),
+		slog.Any("error", errors.New("boom")),
+	)
+
+	line := buf.String()
+	assert.Regexp(t, `^\d{4}-\d{2}-\d{2} \d{2}:\d{2}:\d{2}\.\d{3} INFO  Incoming request `, line)
+	assert.Contains(t, line, " source=bff")
+	assert.Contains(t, line, " request.method=GET")
+	assert.Contains(t, line, ` request.query="a=b c"`)
+	assert.Contains(t, line, " request.header.accept=*/*")
+	assert.Contains(t, line, " request.error.msg=boom")
+	assert.True(t, strings.HasSuffix(line, "\n"))
+
+	buf.Reset()
+	colored := slog.New(newConsoleHandler(buf, nil, true))
+	colored.Error("failed")
+	assert.Contains(t, buf.String(), ansiRed+"ERROR"+ansiReset)
+}
+
+func TestPrettyHandler(t *testing.T) {
+	buf := &bytes.Buffer{}
+	logger, err := New(Config{Environment: "dev", Source: "bff"}, WithSink(Sink{Writer: buf, Format: FormatPretty, Color: true}))
+	require.NoError(t, err)
+
+	logger.With(slog.String("request_id", "r-1")).Error("Request failed",
+		slog.Group("request",
+			slog.String("method", "GET"),
+			slog.Group("header", slog.String("authorization", "Bearer abc")),
+		),
+		slog.Group("response", slog.Int("status", 500)),
+		slog.Any("error", fmt.Errorf("get deeplink: %w", newInnerError())),
+	)
+
+	out := ansiCodes.ReplaceAllString(buf.String(), "")
+	lines := strings.Split(out, "\n")
+	assert.Regexp(t, `^\d{4}-\d{2}-\d{2} \d{2}:\d{2}:\d{2}\.\d{3} ERROR Request failed source=bff env=dev request_id=r-1$`, lines[0])
+	assert.Equal(t, []string{
+		"  request:",
+		"    method: GET",
+		"    header:",
+		"      authorization: " + DefaultRedactMessage,
+		"  response:",
+		"    status: 500",
+		"  error:",
+		`    msg: "get deeplink: connection refused"`,
+		"    trace:",
+		"      logx.newInnerError",
+	}, lines[1:11])
+	assert.Regexp(t, `^          logx/errors_test\.go:\d+$`, lines[11])
+	assert.Contains(t, out, "    causes:\n      - \"get deeplink: connection refused\" (*fmt.wrapError)\n")
+	assert.NotContains(t, out, "Bearer abc")
+
+	buf.Reset()
+	logger.WithGroup("request").With(slog.String("method", "POST")).Info("Incoming request", slog.String("path", "/s/abc"))
+	assert.Contains(t, ansiCodes.ReplaceAllString(buf.String(), ""), "  request:\n    method: POST\n    path: /s/abc\n")
+}
+
+func TestSinkColor(t *testing.T) {
+	for _, format := range []Format{FormatConsole, FormatPretty} {
+		t.Run(string(format), func(t *testing.T) {
+			plain, colored := &bytes.Buffer{}, &bytes.Buffer{}
+			logger, err := New(Config{},
+				WithSink(Sink{Writer: plain, Format: format}),
+				WithSink(Sink{Writer: colored, Format: format, Color: true}),
+			)
+			require.NoError(t, err)
+			logger.Error("failed", slog.Group("request", slog.String("method", "GET")))
+
+			assert.NotRegexp(t, ansiCodes, plain.String())
+			assert.Regexp(t, ansiCodes, colored.String())
+			assert.Equal(t, ansiCodes.ReplaceAllString(colored.String(), ""), plain.String())
+		})
+	}
+}
+
+func TestParseFormat(t *testing.T) {
+	format, err := ParseFormat("")
+	require.NoError(t, err)
+	assert.Equal(t, FormatJSON, format)
+
+	format, err = ParseFormat("console")
+	require.NoError(t, err)
+	assert.Equal(t, FormatConsole, format)
+
+	_, err = ParseFormat("xml")
+	assert.Error(t, err)
+
+	_, err = New(Config{}, WithSink(Sink{}))
+	assert.Error(t, err)
+}
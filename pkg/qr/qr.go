@@ -0,0 +1,206 @@
+package qr
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"image"
+	"image/color"
+	"image/png"
+	"strings"
+
+	qrcode "github.com/skip2/go-qrcode"
+)
+
+// Format is the image format a QR code is rendered to.
+type Format string
+
+// Level is the QR error correction level.
+// Higher levels survive more damage at the cost of a denser symbol.
+type Level string
+
+const (
+	FormatPNG Format = "png"
+	FormatSVG Format = "svg"
+
+	LevelLow     Level = "L" // ~7% recovery
+	LevelMedium  Level = "M" // ~15% recovery
+	LevelQuarter Level = "Q" // ~25% recovery
+	LevelHigh    Level = "H" // ~30% recovery
+)
+
+const (
+	// DefaultSize is the default edge length of the rendered image in pixels.
+	DefaultSize = 256
+	// MinSize and MaxSize bound the edge length of the rendered image in pixels.
+	MinSize = 64
+	MaxSize = 2048
+	// DefaultQuietZone is the blank border in modules recommended by ISO/IEC 18004.
+	DefaultQuietZone = 4
+	// MaxQuietZone bounds the blank border in modules.
+	MaxQuietZone = 16
+)
+
+var (
+	ErrInvalidFormat    = errors.New("qr: invalid format")
+	ErrInvalidLevel     = errors.New("qr: invalid error correction level")
+	ErrInvalidSize      = fmt.Errorf("qr: size must be between %d and %d", MinSize, MaxSize)
+	ErrInvalidQuietZone = fmt.Errorf("qr: quiet zone must be between 0 and %d", MaxQuietZone)
+)
+
+var recoveryLevels = map[Level]qrcode.RecoveryLevel{
+	LevelLow:     qrcode.Low,
+	LevelMedium:  qrcode.Medium,
+	LevelQuarter: qrcode.High,
+	LevelHigh:    qrcode.Highest,
+}
+
+// Options controls how a QR code is rendered.
+type Options struct {
+	Format    Format
+	Size      int
+	Level     Level
+	QuietZone int
+}
+
+// DefaultOptions returns a 256px PNG with medium error correction and the standard quiet zone.
+func DefaultOptions() Options {
+	return Options{
+		Format:    FormatPNG,
+		Size:      DefaultSize,
+		Level:     LevelMedium,
+		QuietZone: DefaultQuietZone,
+	}
+}
+
+// Validate reports whether the options can be rendered.
+func (o Options) Validate() error {
+	if o.Format != FormatPNG && o.Format != FormatSVG {
+		return ErrInvalidFormat
+	}
+	if _, ok := recoveryLevels[o.Level]; !ok {
+		return ErrInvalidLevel
+	}
+	if o.Size < MinSize || o.Size > MaxSize {
+		return ErrInvalidSize
+	}
+	if o.QuietZone < 0 || o.QuietZone > MaxQuietZone {
+		return ErrInvalidQuietZone
+	}
+	return nil
+}
+
+// ContentType returns the MIME type of the rendered format.
+func (f Format) ContentType() string {
+	if f == FormatSVG {
+		return "image/svg+xml"
+	}
+	return "image/png"
+}
+
+// ParseFormat parses a case-insensitive format name.
+func ParseFormat(s string) (Format, error) {
+	f := Format(strings.ToLower(s))
+	if f != FormatPNG && f != FormatSVG {
+		return "", ErrInvalidFormat
+	}
+	return f, nil
+}
+
+// ParseLevel parses a case-insensitive error correction level (L, M, Q or H).
+func ParseLevel(s string) (Level, error) {
+	l := Level(strings.ToUpper(s))
+	if _, ok := recoveryLevels[l]; !ok {
+		return "", ErrInvalidLevel
+	}
+	return l, nil
+}
+
+// Render encodes content as a QR code and renders it according to opts.
+//
+// Example:
+//
+//	img, err := qr.Render("https://example.com", qr.DefaultOptions())
+func Render(content string, opts Options) ([]byte, error) {
+	if err := opts.Validate(); err != nil {
+		return nil, err
+	}
+
+	code, err := qrcode.New(content, recoveryLevels[opts.Level])
+	if err != nil {
+		return nil, fmt.Errorf("qr: failed to encode content: %w", err)
+	}
+	// the quiet zone is drawn by the renderers so its width can be configured
+	code.DisableBorder = true
+	modules := code.Bitmap()
+
+	if opts.Format == FormatSVG {
+		return renderSVG(modules, opts), nil
+	}
+	return renderPNG(modules, opts)
+}
+
+// scale returns the module size in pixels, the edge length of the image and
+// the offset that centres the symbol and its quiet zone inside it. The image
+// is opts.Size pixels, enlarged when the symbol needs more than one pixel
+// per module.
+func scale(modules [][]bool, opts Options) (px, size, offset int) {
+	total := len(modules) + 2*opts.QuietZone
+	px = max(opts.Size/total, 1)
+	size = max(opts.Size, px*total)
+	offset = (size-px*total)/2 + px*opts.QuietZone
+	return px, size, offset
+}
+
+func renderPNG(modules [][]bool, opts Options) ([]byte, error) {
+	px, size, offset := scale(modules, opts)
+
+	palette := color.Palette{color.White, color.Black}
+	img := image.NewPaletted(image.Rect(0, 0, size, size), palette)
+	for y, row := range modules {
+		for x, dark := range row {
+			if !dark {
+				continue
+			}
+			for dy := 0; dy < px; dy++ {
+				for dx := 0; dx < px; dx++ {
+					img.SetColorIndex(offset+x*px+dx, offset+y*px+dy, 1)
+				}
+			}
+		}
+	}
+
+	var buf bytes.Buffer
+	encoder := png.Encoder{CompressionLevel: png.BestCompression}
+	if err := encoder.Encode(&buf, img); err != nil {
+		return nil, fmt.Errorf("qr: failed to encode png: %w", err)
+	}
+	return buf.Bytes(), nil
+}
+
+// renderSVG draws each horizontal run of dark modules as one path segment.
+// The viewBox is expressed in modules so the image scales without blurring.
+func renderSVG(modules [][]bool, opts Options) []byte {
+	total := len(modules) + 2*opts.QuietZone
+
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, `<svg xmlns="http://www.w3.org/2000/svg" width="%d" height="%d" viewBox="0 0 %d %d" shape-rendering="crispEdges">`,
+		opts.Size, opts.Size, total, total)
+	fmt.Fprintf(&buf, `<rect width="%d" height="%d" fill="#ffffff"/>`, total, total)
+	buf.WriteString(`<path fill="#000000" d="`)
+	for y, row := range modules {
+		for x := 0; x < len(row); {
+			if !row[x] {
+				x++
+				continue
+			}
+			start := x
+			for x < len(row) && row[x] {
+				x++
+			}
+			fmt.Fprintf(&buf, "M%d,%dh%dv1h-%dz", start+opts.QuietZone, y+opts.QuietZone, x-start, x-start)
+		}
+	}
+	buf.WriteString(`"/></svg>`)
+	return buf.Bytes()
+}
@@ -0,0 +1,129 @@
+package qr
+
+import (
+	"bytes"
+	"fmt"
+	"image/png"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testContent = "https://example.com/api/v1/deeplink/4d16c3c4-865a-41e8-ab47-2ea773415277"
+
+func TestRenderPNG(t *testing.T) {
+	opts := DefaultOptions()
+	opts.Size = 300
+
+	out, err := Render(testContent, opts)
+	require.NoError(t, err)
+
+	img, err := png.Decode(bytes.NewReader(out))
+	require.NoError(t, err)
+	assert.Equal(t, 300, img.Bounds().Dx())
+	assert.Equal(t, 300, img.Bounds().Dy())
+
+	// the quiet zone must stay blank
+	r, g, b, _ := img.At(0, 0).RGBA()
+	assert.Equal(t, uint32(0xffff), r&g&b)
+}
+
+func TestRenderPNGSmallerThanModules(t *testing.T) {
+	opts := DefaultOptions()
+	opts.Size = MinSize
+	opts.QuietZone = MaxQuietZone
+	opts.Level = LevelHigh
+
+	out, err := Render(testContent, opts)
+	require.NoError(t, err)
+	img, err := png.Decode(bytes.NewReader(out))
+	require.NoError(t, err)
+
+	// the image is enlarged to one pixel per module, quiet zone included
+	size := img.Bounds().Dx()
+	require.Greater(t, size, MinSize)
+	assert.Equal(t, size, img.Bounds().Dy())
+	modules := size - 2*opts.QuietZone
+
+	dark := func(x, y int) bool {
+		r, _, _, _ := img.At(x, y).RGBA()
+		return r == 0
+	}
+	zone, last := opts.QuietZone, opts.QuietZone+modules-1
+	// the outer corners of the three finder patterns
+	assert.True(t, dark(zone, zone), "top-left finder")
+	assert.True(t, dark(last, zone), "top-right finder")
+	assert.True(t, dark(zone, last), "bottom-left finder")
+	// the quiet zone stays blank on every side
+	for i := 0; i < size; i++ {
+		assert.False(t, dark(i, zone-1) || dark(zone-1, i) || dark(i, last+1) || dark(last+1, i), "quiet zone at %d", i)
+	}
+}
+
+func TestRenderSVG(t *testing.T) {
+	opts := DefaultOptions()
+	opts.Format = FormatSVG
+	opts.QuietZone = 2
+
+	out, err := Render(testContent, opts)
+	require.NoError(t, err)
+
+	svg := string(out)
+	assert.Contains(t, svg, `width="256" height="256"`)
+	assert.Contains(t, svg, `<path fill="#000000" d="M2,2h7v1h-7z`) // top-left finder pattern
+}
+
+func TestRenderQuietZone(t *testing.T) {
+	opts := DefaultOptions()
+	opts.Format = FormatSVG
+
+	withZone, err := Render(testContent, opts)
+	require.NoError(t, err)
+
+	opts.QuietZone = 0
+	withoutZone, err := Render(testContent, opts)
+	require.NoError(t, err)
+
+	var modules int
+	_, err = fmt.Sscanf(string(withoutZone), `<svg xmlns="http://www.w3.org/2000/svg" width="256" height="256" viewBox="0 0 %d`, &modules)
+	require.NoError(t, err)
+	assert.Contains(t, string(withZone), fmt.Sprintf(`viewBox="0 0 %d %d"`, modules+8, modules+8))
+}
+
+func TestOptionsValidate(t *testing.T) {
+	tests := []struct {
+		name    string
+		modify  func(*Options)
+		wantErr error
+	}{
+		{name: "default", modify: func(o *Options) {}},
+		{name: "invalid format", modify: func(o *Options) { o.Format = "gif" }, wantErr: ErrInvalidFormat},
+		{name: "invalid level", modify: func(o *Options) { o.Level = "X" }, wantErr: ErrInvalidLevel},
+		{name: "size too small", modify: func(o *Options) { o.Size = MinSize - 1 }, wantErr: ErrInvalidSize},
+		{name: "size too large", modify: func(o *Options) { o.Size = MaxSize + 1 }, wantErr: ErrInvalidSize},
+		{name: "negative quiet zone", modify: func(o *Options) { o.QuietZone = -1 }, wantErr: ErrInvalidQuietZone},
+		{name: "no quiet zone", modify: func(o *Options) { o.QuietZone = 0 }},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			opts := DefaultOptions()
+			tt.modify(&opts)
+			assert.ErrorIs(t, opts.Validate(), tt.wantErr)
+		})
+	}
+}
+
+func TestParse(t *testing.T) {
+	format, err := ParseFormat("SVG")
+	require.NoError(t, err)
+	assert.Equal(t, FormatSVG, format)
+
+	level, err := ParseLevel("q")
+	require.NoError(t, err)
+	assert.Equal(t, LevelQuarter, level)
+
+	_, err = ParseLevel("Z")
+	assert.ErrorIs(t, err, ErrInvalidLevel)
+}